@@ -0,0 +1,355 @@
+// Package addrbook implements the addrbook-gen command, which builds a
+// CometBFT addrbook.json from a network's peer and seed lists and validates
+// existing address books.
+//
+// CometBFT trusts the bucket indices stored in addrbook.json when loading
+// it, so generated entries are placed into "new" buckets following the same
+// group-based scheme the node uses (source group and /16 address group),
+// keyed by the book's key. Like a node adding a new address, entries get the
+// build time as their last attempt: CometBFT considers addresses whose last
+// attempt is zero or more than a week old bad and evicts them first, so a
+// generated book should be deployed within a few days of building it.
+//
+// Usage:
+//
+//	addrbook-gen -out addrbook.json alfama
+//	addrbook-gen -validate ~/.warden/config/addrbook.json
+package addrbook
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// Bucket layout of the CometBFT address book (p2p/pex/params.go).
+const (
+	bucketTypeNew = 1
+	bucketTypeOld = 2
+
+	newBucketCount          = 256
+	newBucketSize           = 64
+	newBucketsPerGroup      = 32
+	oldBucketCount          = 64
+	oldBucketSize           = 64
+	maxNewBucketsPerAddress = 4
+)
+
+// Book is the on-disk format of addrbook.json.
+type Book struct {
+	Key   string       `json:"key"`
+	Addrs []*KnownAddr `json:"addrs"`
+}
+
+// NetAddress is a peer address as stored in the address book.
+type NetAddress struct {
+	ID   string `json:"id"`
+	IP   string `json:"ip"`
+	Port uint16 `json:"port"`
+}
+
+func (a NetAddress) String() string {
+	return a.ID + "@" + net.JoinHostPort(a.IP, strconv.Itoa(int(a.Port)))
+}
+
+// KnownAddr is an address book entry.
+type KnownAddr struct {
+	Addr        NetAddress `json:"addr"`
+	Src         NetAddress `json:"src"`
+	Buckets     []int      `json:"buckets"`
+	Attempts    int32      `json:"attempts"`
+	BucketType  byte       `json:"bucket_type"`
+	LastAttempt time.Time  `json:"last_attempt"`
+	LastSuccess time.Time  `json:"last_success"`
+	LastBanTime time.Time  `json:"last_ban_time"`
+}
+
+// Command is the addrbook-gen command.
+var Command = &cli.Command{
+	Name:  "addrbook-gen",
+	Args:  "<network> | -validate <addrbook.json>",
+	Short: "Generate a CometBFT addrbook.json from a network's peers and seeds, or validate one.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			out      = fs.String("out", "", "write the address book to this file instead of stdout")
+			key      = fs.String("key", "", "address book key (24 hex characters); random when empty")
+			validate = fs.String("validate", "", "validate this addrbook.json instead of generating one")
+			format   = cli.FormatFlag(fs, "text", "json")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if *validate != "" {
+				if len(args) != 0 {
+					return cli.ErrUsage
+				}
+				return runValidate(env, *validate, format)
+			}
+			if len(args) != 1 {
+				return cli.ErrUsage
+			}
+
+			dir, err := network.Resolve(env.Root, args[0])
+			if err != nil {
+				return err
+			}
+			n, err := network.Load(dir)
+			if err != nil {
+				return err
+			}
+
+			if *key == "" {
+				b := make([]byte, 12)
+				if _, err := rand.Read(b); err != nil {
+					return err
+				}
+				*key = hex.EncodeToString(b)
+			}
+
+			book, skipped := Build(ctx, *key, append(append([]string{}, n.Peers...), n.Seeds...))
+			for _, s := range skipped {
+				fmt.Fprintf(env.Stderr, "skipping %s\n", s)
+			}
+			if len(book.Addrs) == 0 {
+				return errors.New("no usable peer addresses")
+			}
+			if problems := Validate(book); len(problems) > 0 {
+				return fmt.Errorf("generated address book is invalid: %v", problems)
+			}
+
+			w := env.Stdout
+			if *out != "" {
+				f, err := os.Create(*out)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			return cli.WriteJSON(w, book)
+		}
+	},
+}
+
+func runValidate(env *cli.Env, path string, format *cli.Format) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var book Book
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	problems := []string{}
+	if err := dec.Decode(&book); err != nil {
+		problems = append(problems, fmt.Sprintf("decode: %v", err))
+	} else {
+		problems = append(problems, Validate(&book)...)
+	}
+
+	if format.JSON() {
+		if err := cli.WriteJSON(env.Stdout, map[string]any{"file": path, "addresses": len(book.Addrs), "problems": problems}); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			fmt.Fprintln(env.Stdout, p)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s: %d problem(s) found", path, len(problems))
+	}
+	if !format.JSON() {
+		fmt.Fprintf(env.Stdout, "%s: %d addresses, ok\n", path, len(book.Addrs))
+	}
+	return nil
+}
+
+// Build creates an address book holding peers ("nodeID@host:port"), with
+// hostnames resolved to their first IP address. Entries that cannot be
+// parsed or resolved are returned as skipped, with the reason.
+func Build(ctx context.Context, key string, peers []string) (*Book, []string) {
+	book := &Book{Key: key, Addrs: []*KnownAddr{}}
+	now := time.Now().UTC().Truncate(time.Second)
+	var skipped []string
+	seen := map[string]bool{}
+	bucketLen := map[int]int{}
+
+	for _, p := range peers {
+		addr, err := resolve(ctx, p)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", p, err))
+			continue
+		}
+		if seen[addr.ID] {
+			continue
+		}
+		seen[addr.ID] = true
+
+		// Addresses from the repo are self-reported, so they are their own
+		// source, like a node's configured persistent peers.
+		bucket := newBucket(key, addr, addr)
+		if bucketLen[bucket] >= newBucketSize {
+			skipped = append(skipped, fmt.Sprintf("%s: new bucket %d is full", p, bucket))
+			continue
+		}
+		bucketLen[bucket]++
+
+		book.Addrs = append(book.Addrs, &KnownAddr{
+			Addr:        addr,
+			Src:         addr,
+			Buckets:     []int{bucket},
+			BucketType:  bucketTypeNew,
+			LastAttempt: now,
+		})
+	}
+	return book, skipped
+}
+
+func resolve(ctx context.Context, peer string) (NetAddress, error) {
+	id, hostport, err := networks.ParsePeer(peer)
+	if err != nil {
+		return NetAddress{}, err
+	}
+	host, portStr, _ := net.SplitHostPort(hostport)
+	port, _ := strconv.Atoi(portStr)
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+		if err != nil {
+			return NetAddress{}, err
+		}
+		ip = ips[0]
+	}
+	return NetAddress{ID: id, IP: ip.String(), Port: uint16(port)}, nil
+}
+
+// newBucket mirrors CometBFT's calcNewBucket: addresses from the same source
+// group spread over at most newBucketsPerGroup buckets. SHA-256 stands in
+// for the node's process-local hash key, which is not persisted.
+func newBucket(key string, addr, src NetAddress) int {
+	h1 := sha256.Sum256([]byte(key + groupKey(addr.IP) + groupKey(src.IP)))
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], binary.BigEndian.Uint64(h1[:8])%newBucketsPerGroup)
+
+	h2 := sha256.Sum256(append([]byte(key+groupKey(src.IP)), buf[:]...))
+	return int(binary.BigEndian.Uint64(h2[:8]) % newBucketCount)
+}
+
+// groupKey returns the network group of an IP: its /16 for IPv4 and /32
+// for IPv6, or "local"/"unroutable" for addresses that are not public.
+func groupKey(s string) string {
+	ip := net.ParseIP(s)
+	switch {
+	case ip == nil:
+		return "unroutable"
+	case ip.IsLoopback(), ip.IsPrivate(), ip.IsUnspecified():
+		return "local"
+	case ip.IsLinkLocalUnicast(), ip.IsMulticast():
+		return "unroutable"
+	case ip.To4() != nil:
+		return ip.Mask(net.CIDRMask(16, 32)).String()
+	default:
+		return ip.Mask(net.CIDRMask(32, 128)).String()
+	}
+}
+
+// Validate checks an address book against the invariants CometBFT relies on
+// when loading it.
+func Validate(book *Book) []string {
+	var problems []string
+	report := func(format string, args ...any) { problems = append(problems, fmt.Sprintf(format, args...)) }
+
+	if b, err := hex.DecodeString(book.Key); err != nil || len(b) != 12 {
+		report("key %q is not 24 hex characters", book.Key)
+	}
+
+	seen := map[string]int{}
+	newLen := map[int]int{}
+	oldLen := map[int]int{}
+	for i, ka := range book.Addrs {
+		if ka == nil {
+			report("addrs[%d]: null entry", i)
+			continue
+		}
+		where := fmt.Sprintf("addrs[%d] (%s)", i, ka.Addr.ID)
+		if prev, ok := seen[ka.Addr.ID]; ok {
+			report("%s: duplicate node id, also at addrs[%d]", where, prev)
+		}
+		seen[ka.Addr.ID] = i
+
+		for name, a := range map[string]NetAddress{"addr": ka.Addr, "src": ka.Src} {
+			if b, err := hex.DecodeString(a.ID); err != nil || len(b) != 20 {
+				report("%s: %s.id %q is not 40 hex characters", where, name, a.ID)
+			}
+			if net.ParseIP(a.IP) == nil {
+				report("%s: %s.ip %q is not an IP address", where, name, a.IP)
+			}
+			if a.Port == 0 {
+				report("%s: %s.port is 0", where, name)
+			}
+		}
+
+		switch ka.BucketType {
+		case bucketTypeNew:
+			if len(ka.Buckets) == 0 || len(ka.Buckets) > maxNewBucketsPerAddress {
+				report("%s: new address must be in 1 to %d buckets, found %d", where, maxNewBucketsPerAddress, len(ka.Buckets))
+			}
+			for _, b := range ka.Buckets {
+				if b < 0 || b >= newBucketCount {
+					report("%s: new bucket %d out of range [0,%d)", where, b, newBucketCount)
+				}
+				newLen[b]++
+			}
+		case bucketTypeOld:
+			if len(ka.Buckets) != 1 {
+				report("%s: old address must be in exactly 1 bucket, found %d", where, len(ka.Buckets))
+			}
+			for _, b := range ka.Buckets {
+				if b < 0 || b >= oldBucketCount {
+					report("%s: old bucket %d out of range [0,%d)", where, b, oldBucketCount)
+				}
+				oldLen[b]++
+			}
+			if ka.LastSuccess.IsZero() {
+				report("%s: old address has never succeeded", where)
+			}
+		default:
+			report("%s: invalid bucket_type %d", where, ka.BucketType)
+		}
+
+		if ka.Attempts < 0 {
+			report("%s: negative attempts", where)
+		}
+		if ka.LastSuccess.After(time.Now()) || ka.LastAttempt.After(time.Now()) {
+			report("%s: timestamp in the future", where)
+		}
+	}
+
+	for b, n := range newLen {
+		if n > newBucketSize {
+			report("new bucket %d holds %d addresses, more than %d", b, n, newBucketSize)
+		}
+	}
+	for b, n := range oldLen {
+		if n > oldBucketSize {
+			report("old bucket %d holds %d addresses, more than %d", b, n, oldBucketSize)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,139 @@
+package addrbook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKey = "0123456789abcdef01234567"
+
+func TestGroupKey(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"203.0.113.7", "203.0.0.0"},
+		{"203.0.200.1", "203.0.0.0"},
+		{"127.0.0.1", "local"},
+		{"10.1.2.3", "local"},
+		{"0.0.0.0", "local"},
+		{"169.254.1.1", "unroutable"},
+		{"2001:db8:1234::1", "2001:db8::"},
+		{"fe80::1", "unroutable"},
+		{"not an ip", "unroutable"},
+	}
+	for _, tt := range tests {
+		if got := groupKey(tt.ip); got != tt.want {
+			t.Errorf("groupKey(%q) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestNewBucket(t *testing.T) {
+	src := NetAddress{ID: strings.Repeat("aa", 20), IP: "198.51.100.1", Port: 26656}
+	buckets := map[int]bool{}
+	for i := 0; i < 1000; i++ {
+		addr := NetAddress{ID: strings.Repeat("bb", 20), IP: fmt.Sprintf("%d.%d.1.1", 1+i/250, i%250), Port: 26656}
+		b := newBucket(testKey, addr, src)
+		if b < 0 || b >= newBucketCount {
+			t.Fatalf("newBucket(%s) = %d, out of range", addr.IP, b)
+		}
+		if again := newBucket(testKey, addr, src); again != b {
+			t.Fatalf("newBucket(%s) = %d, then %d", addr.IP, b, again)
+		}
+		buckets[b] = true
+	}
+	if len(buckets) > newBucketsPerGroup {
+		t.Errorf("one source group spread over %d buckets, want at most %d", len(buckets), newBucketsPerGroup)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	id := func(c string) string { return strings.Repeat(c, 40) }
+	peers := []string{
+		id("a") + "@203.0.113.1:26656",
+		id("b") + "@[2001:db8::1]:26656",
+		id("a") + "@203.0.113.2:26656", // duplicate node id
+		"not a peer",
+	}
+	book, skipped := Build(context.Background(), testKey, peers)
+	if len(book.Addrs) != 2 {
+		t.Fatalf("Build kept %d addresses, want 2", len(book.Addrs))
+	}
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], "not a peer:") {
+		t.Errorf("Build skipped %q, want only the invalid peer", skipped)
+	}
+	for _, ka := range book.Addrs {
+		if ka.LastAttempt.IsZero() {
+			t.Errorf("%s: zero last attempt", ka.Addr)
+		}
+	}
+	if problems := Validate(book); len(problems) > 0 {
+		t.Errorf("Validate(Build(...)) = %q", problems)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	addr := NetAddress{ID: strings.Repeat("ab", 20), IP: "203.0.113.1", Port: 26656}
+	valid := func() *KnownAddr {
+		return &KnownAddr{Addr: addr, Src: addr, Buckets: []int{3}, BucketType: bucketTypeNew}
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(b *Book)
+		problem string // substring of the first problem; "" when none is expected
+	}{
+		{"valid", func(b *Book) {}, ""},
+		{"old address", func(b *Book) {
+			b.Addrs[0].BucketType, b.Addrs[0].LastSuccess = bucketTypeOld, time.Now().Add(-time.Hour)
+		}, ""},
+		{"bad key", func(b *Book) { b.Key = "xyz" }, "not 24 hex characters"},
+		{"null entry", func(b *Book) { b.Addrs[0] = nil }, "null entry"},
+		{"duplicate", func(b *Book) { b.Addrs = append(b.Addrs, valid()) }, "duplicate node id"},
+		{"bad id", func(b *Book) { b.Addrs[0].Addr.ID = "abc" }, "addr.id"},
+		{"bad ip", func(b *Book) { b.Addrs[0].Src.IP = "host" }, "src.ip"},
+		{"zero port", func(b *Book) { b.Addrs[0].Addr.Port = 0 }, "addr.port is 0"},
+		{"no buckets", func(b *Book) { b.Addrs[0].Buckets = nil }, "1 to 4 buckets"},
+		{"too many buckets", func(b *Book) { b.Addrs[0].Buckets = []int{1, 2, 3, 4, 5} }, "1 to 4 buckets"},
+		{"new bucket out of range", func(b *Book) { b.Addrs[0].Buckets = []int{newBucketCount} }, "out of range"},
+		{"old in two buckets", func(b *Book) {
+			b.Addrs[0].BucketType, b.Addrs[0].LastSuccess = bucketTypeOld, time.Now().Add(-time.Hour)
+			b.Addrs[0].Buckets = []int{1, 2}
+		}, "exactly 1 bucket"},
+		{"old bucket out of range", func(b *Book) {
+			b.Addrs[0].BucketType, b.Addrs[0].LastSuccess = bucketTypeOld, time.Now().Add(-time.Hour)
+			b.Addrs[0].Buckets = []int{oldBucketCount}
+		}, "out of range"},
+		{"old never succeeded", func(b *Book) { b.Addrs[0].BucketType = bucketTypeOld }, "never succeeded"},
+		{"bad bucket type", func(b *Book) { b.Addrs[0].BucketType = 3 }, "invalid bucket_type 3"},
+		{"negative attempts", func(b *Book) { b.Addrs[0].Attempts = -1 }, "negative attempts"},
+		{"future", func(b *Book) { b.Addrs[0].LastAttempt = time.Now().Add(time.Hour) }, "in the future"},
+		{"full bucket", func(b *Book) {
+			for i := 0; i < newBucketSize; i++ {
+				ka := valid()
+				ka.Addr.ID = fmt.Sprintf("%040x", i+1)
+				b.Addrs = append(b.Addrs, ka)
+			}
+		}, "new bucket 3 holds 65 addresses"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Book{Key: testKey, Addrs: []*KnownAddr{valid()}}
+			tt.edit(b)
+			problems := Validate(b)
+			if tt.problem == "" {
+				if len(problems) > 0 {
+					t.Fatalf("Validate problems = %q", problems)
+				}
+				return
+			}
+			if len(problems) == 0 || !strings.Contains(problems[0], tt.problem) {
+				t.Fatalf("Validate problems = %q, want one containing %q", problems, tt.problem)
+			}
+		})
+	}
+}
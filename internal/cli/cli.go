@@ -0,0 +1,175 @@
+// Package cli contains the command plumbing shared by the wardennet CLI and
+// the standalone tools under utils/.
+//
+// Every tool is a Command: it registers its flags on a flag.FlagSet and
+// returns the function that runs it. The same Command can then be mounted as
+// a wardennet subcommand or run on its own with Main.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// RootEnv is the environment variable holding the path of the networks
+// repository checkout that network names are resolved against.
+const RootEnv = "WARDENNET_ROOT"
+
+// Env is passed to every running command.
+type Env struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// Root is the networks repository checkout that network names passed to
+	// -network are resolved against.
+	Root string
+}
+
+// RunFunc runs a command with its remaining positional arguments.
+type RunFunc func(ctx context.Context, env *Env, args []string) error
+
+// Command describes a tool.
+type Command struct {
+	// Name is the wardennet subcommand name.
+	Name string
+	// Args is the synopsis of the positional arguments, e.g. "<genesis.json>".
+	Args string
+	// Short is a one-line description shown in help output.
+	Short string
+	// Setup registers the command's flags on fs and returns the function
+	// running the command once they are parsed.
+	Setup func(fs *flag.FlagSet) RunFunc
+}
+
+// ErrUsage is returned by commands called with invalid arguments; the
+// caller prints the usage and exits with status 2.
+var ErrUsage = errors.New("invalid usage")
+
+// FlagSet returns a flag set for c named prog, and the function to run once
+// it has been parsed.
+func (c *Command) FlagSet(prog string) (*flag.FlagSet, RunFunc) {
+	fs := flag.NewFlagSet(prog, flag.ContinueOnError)
+	run := c.Setup(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s [flags] %s\n\n%s\n\n", prog, c.Args, c.Short)
+		fs.PrintDefaults()
+	}
+	return fs, run
+}
+
+// Exec parses args for c and runs it, returning the process exit status.
+func Exec(ctx context.Context, prog string, c *Command, env *Env, args []string) int {
+	fs, run := c.FlagSet(prog)
+	fs.SetOutput(env.Stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	err := run(ctx, env, fs.Args())
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrUsage):
+		fs.Usage()
+		return 2
+	default:
+		fmt.Fprintf(env.Stderr, "%s: %v\n", prog, err)
+		return 1
+	}
+}
+
+// Main runs c as a standalone program and exits.
+func Main(prog string, c *Command) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := Exec(ctx, prog, c, DefaultEnv(), os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+// DefaultEnv returns an Env writing to the process stdout/stderr and rooted
+// at $WARDENNET_ROOT, or the working directory when it is unset.
+func DefaultEnv() *Env {
+	root := os.Getenv(RootEnv)
+	if root == "" {
+		root = "."
+	}
+	return &Env{Stdout: os.Stdout, Stderr: os.Stderr, Root: root}
+}
+
+// FormatFlag registers the -format flag. The first allowed format is the
+// default.
+func FormatFlag(fs *flag.FlagSet, allowed ...string) *Format {
+	f := &Format{value: allowed[0], allowed: allowed}
+	fs.Var(f, "format", fmt.Sprintf("output format: one of %v", allowed))
+	return f
+}
+
+// Format is the value of a -format flag.
+type Format struct {
+	value   string
+	allowed []string
+}
+
+func (f *Format) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+// Set implements flag.Value.
+func (f *Format) Set(s string) error {
+	for _, a := range f.allowed {
+		if s == a {
+			f.value = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %v", f.allowed)
+}
+
+// JSON reports whether JSON output was requested.
+func (f *Format) JSON() bool { return f.value == "json" }
+
+// WriteJSON writes v as indented JSON, the output format shared by every
+// command.
+func WriteJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// WriteMarkdown writes rows as a Markdown table, for pasting into issues and
+// pull requests. header is the upper-case header of the matching text table;
+// only the first letter of each title is kept upper-case. Pipes in cells are
+// escaped.
+func WriteMarkdown(w io.Writer, header []string, rows [][]string) error {
+	titles := make([]string, len(header))
+	for i, h := range header {
+		titles[i] = h[:1] + strings.ToLower(h[1:])
+	}
+	if _, err := fmt.Fprintf(w, "| %s |\n|%s\n", strings.Join(titles, " | "), strings.Repeat(" --- |", len(titles))); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		cols := make([]string, len(row))
+		for i, c := range row {
+			cols[i] = strings.ReplaceAll(c, "|", `\|`)
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cols, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CompletionCommand returns a command printing a shell completion script
+// for prog and its subcommands.
+func CompletionCommand(prog string, commands func() []*Command) *Command {
+	return &Command{
+		Name:  "completion",
+		Args:  "bash|zsh|fish",
+		Short: "Print a shell completion script, e.g. `source <(" + prog + " completion bash)`.",
+		Setup: func(fs *flag.FlagSet) RunFunc {
+			return func(ctx context.Context, env *Env, args []string) error {
+				if len(args) != 1 {
+					return ErrUsage
+				}
+				cmds := commands()
+				switch args[0] {
+				case "bash":
+					writeBash(env.Stdout, prog, cmds)
+				case "zsh":
+					fmt.Fprintln(env.Stdout, "autoload -U +X bashcompinit && bashcompinit")
+					writeBash(env.Stdout, prog, cmds)
+				case "fish":
+					writeFish(env.Stdout, prog, cmds)
+				default:
+					return fmt.Errorf("unsupported shell %q", args[0])
+				}
+				return nil
+			}
+		},
+	}
+}
+
+// flagNames returns the flags of c, prefixed with a dash.
+func flagNames(c *Command) []string {
+	fs, _ := c.FlagSet(c.Name)
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) { names = append(names, "-"+f.Name) })
+	sort.Strings(names)
+	return names
+}
+
+func writeBash(w io.Writer, prog string, cmds []*Command) {
+	fn := "_" + strings.ReplaceAll(prog, "-", "_")
+	names := make([]string, 0, len(cmds))
+	for _, c := range cmds {
+		names = append(names, c.Name)
+	}
+
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, `	local cur=${COMP_WORDS[COMP_CWORD]}`)
+	fmt.Fprintln(w, `	if [ "$COMP_CWORD" -eq 1 ]; then`)
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, `	case "${COMP_WORDS[1]}" in`)
+	for _, c := range cmds {
+		words := flagNames(c)
+		if c.Name == "completion" {
+			words = []string{"bash", "zsh", "fish"}
+		}
+		fmt.Fprintf(w, "\t%s) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", c.Name, strings.Join(words, " "))
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "complete -o default -F %s %s\n", fn, prog)
+}
+
+func writeFish(w io.Writer, prog string, cmds []*Command) {
+	for _, c := range cmds {
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a %s -d %q\n", prog, c.Name, c.Short)
+		for _, f := range flagNames(c) {
+			fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from %s' -o %s\n", prog, c.Name, strings.TrimPrefix(f, "-"))
+		}
+	}
+}
@@ -0,0 +1,473 @@
+// Package doublesign implements the double-sign-checker command, which lets
+// a validator audit its own record, and maintainers check validators before
+// listing them, for double-sign evidence and tombstoning.
+//
+// The validator is given as its hex consensus address, its bech32
+// consensus address (wardenvalcons1...) or its base64 ed25519 consensus
+// pubkey. The last -blocks blocks (or -from to -to) are fetched from an RPC
+// endpoint, which must be an archive node to look far back, and scanned for
+// duplicate-vote and light client attack evidence naming the validator, and
+// for slashing events against it in the block results. When a REST endpoint
+// is known, the validator's slashing signing info is fetched as well to
+// report whether it is jailed or tombstoned.
+//
+// The command exits non-zero when the validator double-signed in the scanned
+// range or is tombstoned.
+//
+// Usage:
+//
+//	double-sign-checker -network alfama wardenvalcons1...
+//	double-sign-checker -rpc https://archive.example.org -from 1 -to 500000 8A3F...
+package doublesign
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/bech32"
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+)
+
+// Evidence is a piece of misbehaviour evidence naming the validator.
+type Evidence struct {
+	Height     int64  `json:"height"`
+	Type       string `json:"type"`
+	VoteHeight int64  `json:"vote_height,omitempty"`
+	Time       string `json:"time,omitempty"`
+}
+
+// Slash is a slashing event against the validator.
+type Slash struct {
+	Height int64  `json:"height"`
+	Reason string `json:"reason"`
+	Power  string `json:"power,omitempty"`
+	Jailed bool   `json:"jailed"`
+}
+
+// SigningInfo is the validator's slashing signing info.
+type SigningInfo struct {
+	StartHeight         string `json:"start_height"`
+	JailedUntil         string `json:"jailed_until"`
+	Tombstoned          bool   `json:"tombstoned"`
+	MissedBlocksCounter string `json:"missed_blocks_counter"`
+}
+
+// Report is the outcome of a check.
+type Report struct {
+	Address     string       `json:"address"`
+	ConsAddress string       `json:"cons_address"`
+	RPC         string       `json:"rpc"`
+	From        int64        `json:"from"`
+	To          int64        `json:"to"`
+	Evidence    []Evidence   `json:"evidence"`
+	Slashes     []Slash      `json:"slashes"`
+	SigningInfo *SigningInfo `json:"signing_info,omitempty"`
+}
+
+// DoubleSigned reports whether r shows the validator double-signing or
+// being tombstoned.
+func (r *Report) DoubleSigned() bool {
+	if len(r.Evidence) > 0 || r.SigningInfo != nil && r.SigningInfo.Tombstoned {
+		return true
+	}
+	for _, s := range r.Slashes {
+		if s.Reason == "double_sign" {
+			return true
+		}
+	}
+	return false
+}
+
+// Command is the double-sign-checker command.
+var Command = &cli.Command{
+	Name:  "double-sign-checker",
+	Args:  "<consensus address or pubkey>",
+	Short: "Scan recent blocks for double-sign evidence and slashing of a validator, and report its signing info.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format      = cli.FormatFlag(fs, "text", "json")
+			name        = fs.String("network", "", "network name or directory to take the RPC and REST endpoints from")
+			rpc         = fs.String("rpc", "", "RPC endpoint, overrides the network's")
+			api         = fs.String("api", "", "REST endpoint for the signing info, overrides the network's")
+			blocks      = fs.Int64("blocks", 1000, "number of most recent blocks to scan")
+			from        = fs.Int64("from", 0, "first height to scan, instead of -blocks")
+			to          = fs.Int64("to", 0, "last height to scan (default latest)")
+			prefix      = fs.String("prefix", "warden", "bech32 prefix of the chain")
+			concurrency = fs.Int("concurrency", 8, "number of blocks fetched in parallel")
+			timeout     = fs.Duration("timeout", 10*time.Second, "timeout for each request")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if len(args) != 1 || *concurrency < 1 {
+				return cli.ErrUsage
+			}
+			addr, err := ParseValidator(args[0], *prefix)
+			if err != nil {
+				return err
+			}
+
+			c := &Checker{
+				Client:      &http.Client{Timeout: *timeout},
+				RPC:         strings.TrimSuffix(*rpc, "/"),
+				API:         strings.TrimSuffix(*api, "/"),
+				Prefix:      *prefix,
+				Concurrency: *concurrency,
+			}
+			if *name != "" {
+				dir, err := network.Resolve(env.Root, *name)
+				if err != nil {
+					return err
+				}
+				n, err := network.Load(dir)
+				if err != nil {
+					return err
+				}
+				if c.RPC == "" && len(n.RPC) > 0 {
+					c.RPC = strings.TrimSuffix(n.RPC[0], "/")
+				}
+				if c.API == "" && len(n.REST) > 0 {
+					c.API = strings.TrimSuffix(n.REST[0], "/")
+				}
+			}
+			if c.RPC == "" {
+				return errors.New("no RPC endpoint: pass -network or -rpc")
+			}
+
+			r, err := c.Check(ctx, addr, *from, *to, *blocks)
+			if err != nil {
+				return err
+			}
+
+			if format.JSON() {
+				err = cli.WriteJSON(env.Stdout, r)
+			} else {
+				err = writeText(env.Stdout, r)
+			}
+			if err != nil {
+				return err
+			}
+			if r.DoubleSigned() {
+				return fmt.Errorf("validator %s double-signed", r.ConsAddress)
+			}
+			return nil
+		}
+	},
+}
+
+// ParseValidator returns the 20-byte consensus address given as hex, as a
+// bech32 consensus address with the prefix+"valcons" prefix or as a base64
+// ed25519 pubkey.
+func ParseValidator(s, prefix string) ([]byte, error) {
+	if b, err := hex.DecodeString(s); err == nil && len(b) == 20 {
+		return b, nil
+	}
+	if hrp, b, err := bech32.Decode(s); err == nil {
+		if want := prefix + "valcons"; hrp != want {
+			return nil, fmt.Errorf("%s: bech32 prefix %q, expected a %q consensus address", s, hrp, want)
+		}
+		if len(b) != 20 {
+			return nil, fmt.Errorf("%s: %d-byte address, expected 20", s, len(b))
+		}
+		return b, nil
+	}
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil && len(b) == 32 {
+		sum := sha256.Sum256(b)
+		return sum[:20], nil
+	}
+	return nil, fmt.Errorf("%q is neither a hex or bech32 consensus address nor a base64 ed25519 pubkey", s)
+}
+
+// Checker scans a chain for the misbehaviour of a validator.
+type Checker struct {
+	Client *http.Client
+	RPC    string
+	// API is the REST endpoint the signing info is read from; it is skipped
+	// when empty.
+	API         string
+	Prefix      string
+	Concurrency int
+}
+
+// Check scans the blocks from from to to for evidence and slashes of the
+// validator with consensus address addr. When from is 0 the last blocks
+// blocks are scanned, and when to is 0 the scan ends at the latest height.
+// The range is clamped to the blocks the node still has.
+func (c *Checker) Check(ctx context.Context, addr []byte, from, to, blocks int64) (*Report, error) {
+	consAddr, err := bech32.Encode(c.Prefix+"valcons", addr)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{
+		Address:     strings.ToUpper(hex.EncodeToString(addr)),
+		ConsAddress: consAddr,
+		RPC:         c.RPC,
+		Evidence:    []Evidence{},
+		Slashes:     []Slash{},
+	}
+
+	var status struct {
+		Result struct {
+			SyncInfo struct {
+				LatestBlockHeight   string `json:"latest_block_height"`
+				EarliestBlockHeight string `json:"earliest_block_height"`
+			} `json:"sync_info"`
+		} `json:"result"`
+	}
+	if err := c.get(ctx, c.RPC+"/status", &status); err != nil {
+		return nil, err
+	}
+	latest, err := strconv.ParseInt(status.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latest_block_height: %w", err)
+	}
+	earliest, _ := strconv.ParseInt(status.Result.SyncInfo.EarliestBlockHeight, 10, 64)
+
+	if to == 0 || to > latest {
+		to = latest
+	}
+	if from == 0 {
+		from = to - blocks + 1
+	}
+	from = max(from, earliest, 1)
+	if from > to {
+		return nil, fmt.Errorf("nothing to scan: from %d is after to %d", from, to)
+	}
+	r.From, r.To = from, to
+
+	if err := c.scan(ctx, r, addr); err != nil {
+		return nil, err
+	}
+
+	if c.API != "" {
+		info, err := c.signingInfo(ctx, r.ConsAddress)
+		if err != nil {
+			return nil, err
+		}
+		r.SigningInfo = info
+	}
+	return r, nil
+}
+
+// scan fetches the blocks of r's range with Concurrency workers and stops
+// at the first error.
+func (c *Checker) scan(ctx context.Context, r *Report, addr []byte) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	heights := make(chan int64)
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	for i := 0; i < c.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for h := range heights {
+				ev, sl, err := c.block(ctx, h, addr, r.ConsAddress)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("block %d: %w", h, err)
+					cancel()
+				}
+				r.Evidence = append(r.Evidence, ev...)
+				r.Slashes = append(r.Slashes, sl...)
+				mu.Unlock()
+			}
+		}()
+	}
+	for h := r.From; h <= r.To && ctx.Err() == nil; h++ {
+		select {
+		case heights <- h:
+		case <-ctx.Done():
+		}
+	}
+	close(heights)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sort.Slice(r.Evidence, func(i, j int) bool { return r.Evidence[i].Height < r.Evidence[j].Height })
+	sort.Slice(r.Slashes, func(i, j int) bool { return r.Slashes[i].Height < r.Slashes[j].Height })
+	return nil
+}
+
+type vote struct {
+	Height           string `json:"height"`
+	ValidatorAddress string `json:"validator_address"`
+}
+
+type evidence struct {
+	Type  string `json:"type"`
+	Value struct {
+		VoteA               *vote  `json:"vote_a"`
+		Timestamp           string `json:"timestamp"`
+		CommonHeight        string `json:"common_height"`
+		ByzantineValidators []struct {
+			Address string `json:"address"`
+		} `json:"byzantine_validators"`
+	} `json:"value"`
+}
+
+type event struct {
+	Type       string `json:"type"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"attributes"`
+}
+
+// block returns the evidence and slashes of the validator at height h.
+func (c *Checker) block(ctx context.Context, h int64, addr []byte, consAddr string) ([]Evidence, []Slash, error) {
+	var block struct {
+		Result struct {
+			Block struct {
+				Evidence struct {
+					Evidence []evidence `json:"evidence"`
+				} `json:"evidence"`
+			} `json:"block"`
+		} `json:"result"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("%s/block?height=%d", c.RPC, h), &block); err != nil {
+		return nil, nil, err
+	}
+	hexAddr := hex.EncodeToString(addr)
+
+	var found []Evidence
+	for _, e := range block.Result.Block.Evidence.Evidence {
+		ev := Evidence{Height: h, Type: strings.TrimPrefix(e.Type, "tendermint/"), Time: e.Value.Timestamp}
+		match := false
+		if v := e.Value.VoteA; v != nil && strings.EqualFold(v.ValidatorAddress, hexAddr) {
+			match = true
+			ev.VoteHeight, _ = strconv.ParseInt(v.Height, 10, 64)
+		}
+		for _, b := range e.Value.ByzantineValidators {
+			if strings.EqualFold(b.Address, hexAddr) {
+				match = true
+				ev.VoteHeight, _ = strconv.ParseInt(e.Value.CommonHeight, 10, 64)
+			}
+		}
+		if match {
+			found = append(found, ev)
+		}
+	}
+
+	var results struct {
+		Result struct {
+			// CometBFT 0.38 reports all block events as finalize block
+			// events, older versions split them around the txs.
+			FinalizeBlockEvents []event `json:"finalize_block_events"`
+			BeginBlockEvents    []event `json:"begin_block_events"`
+			EndBlockEvents      []event `json:"end_block_events"`
+		} `json:"result"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("%s/block_results?height=%d", c.RPC, h), &results); err != nil {
+		return nil, nil, err
+	}
+	var slashes []Slash
+	for _, events := range [][]event{results.Result.FinalizeBlockEvents, results.Result.BeginBlockEvents, results.Result.EndBlockEvents} {
+		for _, e := range events {
+			if e.Type != "slash" {
+				continue
+			}
+			s := Slash{Height: h}
+			var address string
+			for _, a := range e.Attributes {
+				switch a.Key {
+				case "address":
+					address = a.Value
+				case "reason":
+					s.Reason = a.Value
+				case "power":
+					s.Power = a.Value
+				case "jailed":
+					s.Jailed = true
+				}
+			}
+			if address == consAddr {
+				slashes = append(slashes, s)
+			}
+		}
+	}
+	return found, slashes, nil
+}
+
+// signingInfo returns the signing info of consAddr, or nil when the chain
+// has none for it.
+func (c *Checker) signingInfo(ctx context.Context, consAddr string) (*SigningInfo, error) {
+	var resp struct {
+		ValSigningInfo SigningInfo `json:"val_signing_info"`
+	}
+	err := c.get(ctx, c.API+"/cosmos/slashing/v1beta1/signing_infos/"+consAddr, &resp)
+	var status statusError
+	if errors.As(err, &status) && status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &resp.ValSigningInfo, nil
+}
+
+type statusError int
+
+func (e statusError) Error() string { return http.StatusText(int(e)) }
+
+func (c *Checker) get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %w", url, statusError(resp.StatusCode))
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 32<<20)).Decode(v)
+}
+
+func writeText(w io.Writer, r *Report) error {
+	fmt.Fprintf(w, "Validator: %s (%s)\n", r.ConsAddress, r.Address)
+	fmt.Fprintf(w, "Scanned:   blocks %d to %d on %s\n", r.From, r.To, r.RPC)
+
+	fmt.Fprintf(w, "\nEvidence: %d\n", len(r.Evidence))
+	for _, e := range r.Evidence {
+		fmt.Fprintf(w, "  height %d: %s for height %d %s\n", e.Height, e.Type, e.VoteHeight, e.Time)
+	}
+	fmt.Fprintf(w, "\nSlashes: %d\n", len(r.Slashes))
+	for _, s := range r.Slashes {
+		jailed := ""
+		if s.Jailed {
+			jailed = ", jailed"
+		}
+		fmt.Fprintf(w, "  height %d: %s (power %s%s)\n", s.Height, s.Reason, s.Power, jailed)
+	}
+
+	if i := r.SigningInfo; i != nil {
+		fmt.Fprintf(w, "\nSigning info: start height %s, missed blocks %s, jailed until %s, tombstoned %t\n",
+			i.StartHeight, i.MissedBlocksCounter, i.JailedUntil, i.Tombstoned)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package doublesign
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/bech32"
+	"github.com/warden-protocol/networks/internal/cli"
+)
+
+// encode returns the bech32 encoding of b with hrp.
+func encode(t *testing.T, hrp string, b []byte) string {
+	t.Helper()
+	s, err := bech32.Encode(hrp, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestParseValidator(t *testing.T) {
+	addr := bytes.Repeat([]byte{0xab}, 20)
+	pubkey := bytes.Repeat([]byte{0x01}, 32)
+	sum := sha256.Sum256(pubkey)
+
+	tests := []struct {
+		name string
+		in   string
+		want []byte // nil when an error is expected
+		err  string
+	}{
+		{"hex", hex.EncodeToString(addr), addr, ""},
+		{"upper-case hex", "ABABABABABABABABABABABABABABABABABABABAB", addr, ""},
+		{"valcons", encode(t, "wardenvalcons", addr), addr, ""},
+		{"base64 pubkey", base64.StdEncoding.EncodeToString(pubkey), sum[:20], ""},
+		{"account address", encode(t, "warden", addr), nil, `bech32 prefix "warden", expected a "wardenvalcons" consensus address`},
+		{"operator address", encode(t, "wardenvaloper", addr), nil, `bech32 prefix "wardenvaloper"`},
+		{"other chain", encode(t, "cosmosvalcons", addr), nil, `bech32 prefix "cosmosvalcons"`},
+		{"short valcons", encode(t, "wardenvalcons", addr[:10]), nil, "10-byte address, expected 20"},
+		{"short hex", hex.EncodeToString(addr[:10]), nil, "is neither"},
+		{"garbage", "not an address", nil, "is neither"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseValidator(tt.in, "warden")
+			if tt.want == nil {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseValidator(%q) = %x, %v, want an error containing %q", tt.in, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseValidator(%q): %v", tt.in, err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("ParseValidator(%q) = %x, want %x", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// chain serves the RPC and REST endpoints Checker reads, for a chain at
+// height 100 that pruned the blocks below 50. The validator double-signed
+// at height 60 and was slashed for it at height 61.
+func chain(t *testing.T, addr []byte, consAddr string, tombstoned bool) *httptest.Server {
+	hexAddr := strings.ToUpper(hex.EncodeToString(addr))
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "//") {
+			t.Errorf("request to %s", r.URL.Path)
+		}
+		var height int
+		fmt.Sscan(r.URL.Query().Get("height"), &height)
+		switch {
+		case r.URL.Path == "/status":
+			fmt.Fprint(w, `{"result":{"sync_info":{"latest_block_height":"100","earliest_block_height":"50"}}}`)
+		case r.URL.Path == "/block" && height == 60:
+			fmt.Fprintf(w, `{"result":{"block":{"evidence":{"evidence":[
+				{"type":"tendermint/DuplicateVoteEvidence","value":{"vote_a":{"height":"59","validator_address":"%s"},"timestamp":"2024-06-01T12:00:00Z"}},
+				{"type":"tendermint/DuplicateVoteEvidence","value":{"vote_a":{"height":"59","validator_address":"00"}}}
+			]}}}}`, hexAddr)
+		case r.URL.Path == "/block":
+			fmt.Fprint(w, `{"result":{"block":{"evidence":{"evidence":[]}}}}`)
+		case r.URL.Path == "/block_results" && height == 61:
+			fmt.Fprintf(w, `{"result":{"finalize_block_events":[
+				{"type":"slash","attributes":[{"key":"address","value":"%s"},{"key":"reason","value":"double_sign"},{"key":"power","value":"10"},{"key":"jailed","value":"%s"}]},
+				{"type":"slash","attributes":[{"key":"address","value":"wardenvalcons1other"},{"key":"reason","value":"missing_signature"}]}
+			]}}`, consAddr, consAddr)
+		case r.URL.Path == "/block_results":
+			fmt.Fprint(w, `{"result":{}}`)
+		case r.URL.Path == "/cosmos/slashing/v1beta1/signing_infos/"+consAddr:
+			fmt.Fprintf(w, `{"val_signing_info":{"start_height":"1","jailed_until":"9999-12-31T23:59:59Z","tombstoned":%t,"missed_blocks_counter":"0"}}`, tombstoned)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestCheck(t *testing.T) {
+	addr := bytes.Repeat([]byte{0xab}, 20)
+	consAddr := encode(t, "wardenvalcons", addr)
+	srv := chain(t, addr, consAddr, true)
+	defer srv.Close()
+
+	tests := []struct {
+		name             string
+		from, to, blocks int64
+		wantFrom, wantTo int64
+		evidence, slash  bool
+		err              string
+	}{
+		{name: "recent blocks", blocks: 10, wantFrom: 91, wantTo: 100},
+		{name: "range", from: 55, to: 65, wantFrom: 55, wantTo: 65, evidence: true, slash: true},
+		{name: "clamped to the pruned height", blocks: 1000, wantFrom: 50, wantTo: 100, evidence: true, slash: true},
+		{name: "clamped to the latest height", from: 61, to: 500, wantFrom: 61, wantTo: 100, slash: true},
+		{name: "nothing to scan", from: 80, to: 70, err: "nothing to scan: from 80 is after to 70"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Checker{Client: srv.Client(), RPC: srv.URL, API: srv.URL, Prefix: "warden", Concurrency: 4}
+			r, err := c.Check(context.Background(), addr, tt.from, tt.to, tt.blocks)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Check() error = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.From != tt.wantFrom || r.To != tt.wantTo {
+				t.Errorf("scanned %d to %d, want %d to %d", r.From, r.To, tt.wantFrom, tt.wantTo)
+			}
+			if got := len(r.Evidence) == 1 && r.Evidence[0] == (Evidence{Height: 60, Type: "DuplicateVoteEvidence", VoteHeight: 59, Time: "2024-06-01T12:00:00Z"}); got != tt.evidence {
+				t.Errorf("evidence = %+v, want it found: %t", r.Evidence, tt.evidence)
+			}
+			if got := len(r.Slashes) == 1 && r.Slashes[0] == (Slash{Height: 61, Reason: "double_sign", Power: "10", Jailed: true}); got != tt.slash {
+				t.Errorf("slashes = %+v, want it found: %t", r.Slashes, tt.slash)
+			}
+			if r.SigningInfo == nil || !r.SigningInfo.Tombstoned || !r.DoubleSigned() {
+				t.Errorf("signing info = %+v, want tombstoned", r.SigningInfo)
+			}
+		})
+	}
+}
+
+func TestCommand(t *testing.T) {
+	addr := bytes.Repeat([]byte{0xab}, 20)
+	consAddr := encode(t, "wardenvalcons", addr)
+
+	tests := []struct {
+		name       string
+		tombstoned bool
+		args       func(url string) []string
+		code       int
+		stdout     string
+	}{
+		{
+			name:   "double-signed",
+			args:   func(url string) []string { return []string{"-rpc", url + "/", "-from", "60", "-to", "60", consAddr} },
+			code:   1,
+			stdout: "height 60: DuplicateVoteEvidence for height 59",
+		},
+		{
+			name:   "clean",
+			args:   func(url string) []string { return []string{"-rpc", url + "/", "-from", "90", consAddr} },
+			stdout: "Evidence: 0\n",
+		},
+		{
+			name:       "network endpoints with trailing slashes",
+			tombstoned: true,
+			args:       func(url string) []string { return []string{"-network", "chiado", "-from", "90", consAddr} },
+			code:       1,
+			stdout:     "tombstoned true",
+		},
+		{
+			name: "operator address",
+			args: func(url string) []string { return []string{"-rpc", url, encode(t, "wardenvaloper", addr)} },
+			code: 1,
+		},
+		{
+			name: "no endpoint",
+			args: func(url string) []string { return []string{consAddr} },
+			code: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := chain(t, addr, consAddr, tt.tombstoned)
+			defer srv.Close()
+
+			root := t.TempDir()
+			dir := filepath.Join(root, "testnets", "chiado")
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range map[string]string{
+				"chain-id.txt":  "chiado_10010-1\n",
+				"rpc-nodes.txt": srv.URL + "/\n",
+				"api-nodes.txt": srv.URL + "/\n",
+			} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			var stdout, stderr bytes.Buffer
+			env := &cli.Env{Stdout: &stdout, Stderr: &stderr, Root: root}
+			if code := cli.Exec(ctx, "double-sign-checker", Command, env, tt.args(srv.URL)); code != tt.code {
+				t.Fatalf("exit status %d, want %d: %s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.stdout)
+			}
+		})
+	}
+}
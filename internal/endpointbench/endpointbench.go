@@ -0,0 +1,317 @@
+// Package endpointbench implements the endpoint-bench command, which samples
+// the published endpoints of each network over a time window and reports
+// their latency percentiles and block-height freshness.
+//
+// Every -interval during -window, all selected endpoints are probed with the
+// same checks as rpc-healthcheck. Latency percentiles are computed over the
+// individual requests of the successful samples, so kinds whose check takes
+// several requests compare fairly; freshness is how many blocks an endpoint
+// was behind the highest endpoint of the same network in the same round, and
+// rounds more than -max-lag blocks behind are counted as stale. A round
+// interrupted by cancellation is not recorded. Endpoints are
+// ranked per network by error rate, average lag and p95 latency, so slow or
+// stale endpoints end up at the bottom of the report.
+//
+// Usage:
+//
+//	endpoint-bench -window 5m -interval 10s alfama
+//	endpoint-bench -kinds rpc,evm -format markdown
+//	endpoint-bench -max-lag 10 buenavista
+package endpointbench
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/healthcheck"
+	"github.com/warden-protocol/networks/internal/network"
+)
+
+// Stats is the benchmark result of one endpoint.
+type Stats struct {
+	Network  string `json:"network"`
+	Kind     string `json:"kind"`
+	Endpoint string `json:"endpoint"`
+	Rank     int    `json:"rank"`
+	Samples  int    `json:"samples"`
+	Errors   int    `json:"errors"`
+
+	// P50 and P95 are per-request latencies.
+	P50 time.Duration `json:"latency_p50_ns"`
+	P95 time.Duration `json:"latency_p95_ns"`
+
+	// Height is the last height the endpoint reported. LagAvg, LagMax and
+	// Stale are only meaningful when HeightSamples is non-zero; Stale counts
+	// the samples more than the prober's MaxLag blocks behind.
+	Height        int64   `json:"height,omitempty"`
+	HeightSamples int     `json:"height_samples"`
+	LagAvg        float64 `json:"lag_avg"`
+	LagMax        int64   `json:"lag_max"`
+	Stale         int     `json:"stale"`
+
+	LastError string `json:"last_error,omitempty"`
+
+	latencies []time.Duration
+	lagSum    int64
+}
+
+// ErrorRate returns the fraction of failed samples.
+func (s *Stats) ErrorRate() float64 {
+	if s.Samples == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Samples)
+}
+
+// Command is the endpoint-bench command.
+var Command = &cli.Command{
+	Name:  "endpoint-bench",
+	Args:  "[network...]",
+	Short: "Sample the published endpoints over a window and rank them by latency and freshness.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format   = cli.FormatFlag(fs, "text", "json", "markdown")
+			window   = fs.Duration("window", time.Minute, "sampling window")
+			interval = fs.Duration("interval", 5*time.Second, "time between sampling rounds")
+			timeout  = fs.Duration("timeout", 10*time.Second, "timeout for each probe")
+			kinds    = fs.String("kinds", "rpc,rest,grpc", "comma-separated endpoint kinds to sample (rpc, rest, grpc, evm)")
+			maxLag   = fs.Int64("max-lag", 50, "blocks an endpoint may lag behind the highest one before a sample counts as stale")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if *interval <= 0 || *window < 0 || *maxLag < 0 {
+				return cli.ErrUsage
+			}
+			selected := map[string]bool{}
+			for _, k := range network.SplitList(*kinds) {
+				switch k {
+				case healthcheck.KindRPC, healthcheck.KindREST, healthcheck.KindGRPC, healthcheck.KindEVM:
+					selected[k] = true
+				default:
+					return fmt.Errorf("unknown endpoint kind %q", k)
+				}
+			}
+
+			networks, err := network.LoadAll(env.Root, args)
+			if err != nil {
+				return err
+			}
+			for _, n := range networks {
+				filterKinds(n, selected)
+			}
+
+			p := &healthcheck.Prober{
+				Client:  &http.Client{Timeout: *timeout},
+				Timeout: *timeout,
+				MaxLag:  *maxLag,
+			}
+			b := &Bench{Prober: p, Window: *window, Interval: *interval}
+			stats := b.Run(ctx, networks, func(round int) {
+				fmt.Fprintf(env.Stderr, "round %d done\n", round)
+			})
+
+			switch format.String() {
+			case "json":
+				return cli.WriteJSON(env.Stdout, stats)
+			case "markdown":
+				return WriteMarkdown(env.Stdout, stats)
+			default:
+				return WriteTable(env.Stdout, stats)
+			}
+		}
+	},
+}
+
+// filterKinds drops the endpoints of n whose kind is not selected.
+func filterKinds(n *network.Network, selected map[string]bool) {
+	for kind, list := range map[string]*[]string{
+		healthcheck.KindRPC:  &n.RPC,
+		healthcheck.KindREST: &n.REST,
+		healthcheck.KindGRPC: &n.GRPC,
+		healthcheck.KindEVM:  &n.EVM,
+	} {
+		if !selected[kind] {
+			*list = []string{}
+		}
+	}
+}
+
+// Bench samples endpoints in rounds.
+type Bench struct {
+	Prober   *healthcheck.Prober
+	Window   time.Duration
+	Interval time.Duration
+}
+
+// Run probes every endpoint of networks once per Interval until Window has
+// elapsed or ctx is done, calling progress after each round, and returns
+// the ranked statistics. The first round starts immediately; a round during
+// which ctx is done is discarded, as its failures say nothing about the
+// endpoints.
+func (b *Bench) Run(ctx context.Context, networks []*network.Network, progress func(round int)) []*Stats {
+	stats := map[string]*Stats{}
+	deadline := time.Now().Add(b.Window)
+
+	for round := 1; ; round++ {
+		results := b.Prober.Probe(ctx, networks)
+		if ctx.Err() != nil {
+			break
+		}
+		b.record(stats, results)
+		if progress != nil {
+			progress(round)
+		}
+		if time.Until(deadline) < b.Interval {
+			break
+		}
+		select {
+		case <-time.After(b.Interval):
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return rank(stats)
+}
+
+func (b *Bench) record(stats map[string]*Stats, results []*healthcheck.Result) {
+	highest := map[string]int64{}
+	for _, r := range results {
+		if !r.Failed() && r.Height > highest[r.Network] {
+			highest[r.Network] = r.Height
+		}
+	}
+
+	for _, r := range results {
+		key := r.Network + "\x00" + r.Kind + "\x00" + r.Endpoint
+		s, ok := stats[key]
+		if !ok {
+			s = &Stats{Network: r.Network, Kind: r.Kind, Endpoint: r.Endpoint}
+			stats[key] = s
+		}
+		s.Samples++
+		if r.Failed() {
+			s.Errors++
+			s.LastError = r.Detail
+			continue
+		}
+		s.latencies = append(s.latencies, r.Calls...)
+		if r.Height > 0 {
+			lag := highest[r.Network] - r.Height
+			s.Height = r.Height
+			s.HeightSamples++
+			s.lagSum += lag
+			if lag > s.LagMax {
+				s.LagMax = lag
+			}
+			if lag > b.Prober.MaxLag {
+				s.Stale++
+			}
+		}
+	}
+}
+
+// rank computes the percentiles and orders the endpoints of each network
+// from best to worst.
+func rank(stats map[string]*Stats) []*Stats {
+	list := make([]*Stats, 0, len(stats))
+	for _, s := range stats {
+		sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+		s.P50 = percentile(s.latencies, 50)
+		s.P95 = percentile(s.latencies, 95)
+		if s.HeightSamples > 0 {
+			s.LagAvg = float64(s.lagSum) / float64(s.HeightSamples)
+		}
+		list = append(list, s)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		if a.ErrorRate() != b.ErrorRate() {
+			return a.ErrorRate() < b.ErrorRate()
+		}
+		if a.LagAvg != b.LagAvg {
+			return a.LagAvg < b.LagAvg
+		}
+		if a.P95 != b.P95 {
+			return a.P95 < b.P95
+		}
+		return a.Endpoint < b.Endpoint
+	})
+
+	var prev string
+	rank := 0
+	for _, s := range list {
+		if s.Network != prev {
+			prev, rank = s.Network, 0
+		}
+		rank++
+		s.Rank = rank
+	}
+	return list
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (p*len(sorted)+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+func (s *Stats) columns() []string {
+	p50, p95, lagAvg, lagMax, stale := "-", "-", "-", "-", "-"
+	if len(s.latencies) > 0 {
+		p50 = s.P50.Round(time.Millisecond).String()
+		p95 = s.P95.Round(time.Millisecond).String()
+	}
+	if s.HeightSamples > 0 {
+		lagAvg = strconv.FormatFloat(s.LagAvg, 'f', 1, 64)
+		lagMax = strconv.FormatInt(s.LagMax, 10)
+		stale = strconv.Itoa(s.Stale)
+	}
+	return []string{
+		s.Network, strconv.Itoa(s.Rank), s.Kind, s.Endpoint,
+		fmt.Sprintf("%d/%d", s.Samples-s.Errors, s.Samples),
+		p50, p95, lagAvg, lagMax, stale, s.LastError,
+	}
+}
+
+var header = []string{"NETWORK", "RANK", "KIND", "ENDPOINT", "OK", "P50", "P95", "LAG AVG", "LAG MAX", "STALE", "LAST ERROR"}
+
+// WriteTable writes stats as a text table.
+func WriteTable(w io.Writer, stats []*Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, s := range stats {
+		fmt.Fprintln(tw, strings.Join(s.columns(), "\t"))
+	}
+	return tw.Flush()
+}
+
+// WriteMarkdown writes stats as a Markdown table, for pasting into issues
+// and pull requests that prune endpoints.
+func WriteMarkdown(w io.Writer, stats []*Stats) error {
+	rows := make([][]string, len(stats))
+	for i, s := range stats {
+		rows[i] = s.columns()
+	}
+	return cli.WriteMarkdown(w, header, rows)
+}
@@ -0,0 +1,114 @@
+package endpointbench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/healthcheck"
+	"github.com/warden-protocol/networks/internal/network"
+)
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 20; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	tests := []struct {
+		sorted []time.Duration
+		p      int
+		want   time.Duration
+	}{
+		{nil, 50, 0},
+		{sorted[:1], 95, time.Millisecond},
+		{sorted, 50, 10 * time.Millisecond},
+		{sorted, 95, 19 * time.Millisecond},
+		{sorted, 100, 20 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%d values, %d) = %s, want %s", len(tt.sorted), tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestRecordAndRank(t *testing.T) {
+	b := &Bench{Prober: &healthcheck.Prober{MaxLag: 5}}
+	ms := func(n int) []time.Duration { return []time.Duration{time.Duration(n) * time.Millisecond} }
+	rounds := [][]*healthcheck.Result{
+		{
+			{Network: "chiado", Kind: "rpc", Endpoint: "https://fast", Status: healthcheck.StatusOK, Height: 100, Calls: ms(10)},
+			{Network: "chiado", Kind: "rpc", Endpoint: "https://stale", Status: healthcheck.StatusWarn, Height: 90, Calls: ms(5)},
+			{Network: "chiado", Kind: "rpc", Endpoint: "https://down", Status: healthcheck.StatusDown, Detail: "refused"},
+			{Network: "barra", Kind: "rest", Endpoint: "https://only", Status: healthcheck.StatusOK, Height: 7, Calls: ms(1)},
+		},
+		{
+			{Network: "chiado", Kind: "rpc", Endpoint: "https://fast", Status: healthcheck.StatusOK, Height: 101, Calls: ms(30)},
+			{Network: "chiado", Kind: "rpc", Endpoint: "https://stale", Status: healthcheck.StatusOK, Height: 98, Calls: ms(5)},
+			{Network: "chiado", Kind: "rpc", Endpoint: "https://down", Status: healthcheck.StatusOK, Height: 101, Calls: ms(1)},
+			// A wrong-chain height must not raise the bar for the others.
+			{Network: "chiado", Kind: "rpc", Endpoint: "https://other", Status: healthcheck.StatusWrongChain, Height: 5000},
+		},
+	}
+	stats := map[string]*Stats{}
+	for _, r := range rounds {
+		b.record(stats, r)
+	}
+
+	var got []string
+	for _, s := range rank(stats) {
+		got = append(got, fmt.Sprintf("%s %d %s %d/%d lag=%.1f/%d stale=%d p95=%s",
+			s.Network, s.Rank, s.Endpoint, s.Samples-s.Errors, s.Samples, s.LagAvg, s.LagMax, s.Stale, s.P95))
+	}
+	want := []string{
+		"barra 1 https://only 1/1 lag=0.0/0 stale=0 p95=1ms",
+		"chiado 1 https://fast 2/2 lag=0.0/0 stale=0 p95=30ms",
+		"chiado 2 https://stale 2/2 lag=6.5/10 stale=1 p95=5ms",
+		"chiado 3 https://down 1/2 lag=0.0/0 stale=0 p95=1ms",
+		"chiado 4 https://other 0/1 lag=0.0/0 stale=0 p95=0s",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("rank() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"result":{"node_info":{"network":"chiado_10010-1"},"sync_info":{"latest_block_height":"42","catching_up":false}}}`)
+	}))
+	defer srv.Close()
+	n := &network.Network{Name: "chiado", ChainID: "chiado_10010-1", RPC: []string{srv.URL}}
+	p := &healthcheck.Prober{Client: srv.Client(), Timeout: time.Second}
+
+	rounds := 0
+	b := &Bench{Prober: p, Window: 25 * time.Millisecond, Interval: 10 * time.Millisecond}
+	stats := b.Run(context.Background(), []*network.Network{n}, func(int) { rounds++ })
+	if rounds < 2 || len(stats) != 1 || stats[0].Samples != rounds || stats[0].Errors != 0 || stats[0].Height != 42 {
+		t.Errorf("after %d rounds, Run() = %+v", rounds, stats)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if stats := b.Run(ctx, []*network.Network{n}, nil); len(stats) != 0 {
+		t.Errorf("Run() with a cancelled context recorded %+v, want the round discarded", stats[0])
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	stats := []*Stats{{Network: "chiado", Kind: "rpc", Endpoint: "https://rpc", Rank: 1, Samples: 2, Errors: 1, LastError: "a | b"}}
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, stats); err != nil {
+		t.Fatal(err)
+	}
+	want := "| Network | Rank | Kind | Endpoint | Ok | P50 | P95 | Lag avg | Lag max | Stale | Last error |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- |\n" +
+		"| chiado | 1 | rpc | https://rpc | 1/2 | - | - | - | - | - | a \\| b |\n"
+	if buf.String() != want {
+		t.Errorf("WriteMarkdown() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
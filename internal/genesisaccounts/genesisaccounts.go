@@ -0,0 +1,724 @@
+// Package genesisaccounts implements the genesis-accounts command, which
+// turns an allocation spreadsheet into the auth and bank genesis entries of
+// the accounts it lists.
+//
+// The input is a CSV file with a header row, or a JSON array of objects,
+// with the columns:
+//
+//	address         bech32 account address (required)
+//	amount          coins, e.g. 1000000uward or "5uward,10ufoo" (required)
+//	vesting         none (default), continuous or delayed
+//	vesting_amount  vesting part of amount (default: all of it)
+//	start           vesting start, RFC 3339 or unix seconds (continuous only)
+//	end             vesting end, RFC 3339 or unix seconds
+//
+// Addresses are checked for a valid bech32 checksum and the expected prefix,
+// and must appear only once, in the input and in the genesis it is merged
+// into. Accounts are sorted by address and numbered after the existing
+// ones, so the same input always produces the same genesis. With -genesis
+// the accounts, balances and supply are merged into that file, keeping its
+// key order; otherwise the auth and bank fragments are printed.
+//
+// Usage:
+//
+//	genesis-accounts allocations.csv
+//	genesis-accounts -genesis testnets/alfama/genesis.json -out merged.json allocations.csv
+package genesisaccounts
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/bech32"
+	"github.com/warden-protocol/networks/internal/cli"
+)
+
+// Vesting account types.
+const (
+	VestingNone       = "none"
+	VestingContinuous = "continuous"
+	VestingDelayed    = "delayed"
+)
+
+// Allocation is one row of the input.
+type Allocation struct {
+	Address       string `json:"address"`
+	Amount        string `json:"amount"`
+	Vesting       string `json:"vesting,omitempty"`
+	VestingAmount string `json:"vesting_amount,omitempty"`
+	Start         string `json:"start,omitempty"`
+	End           string `json:"end,omitempty"`
+}
+
+// Coin is a cosmos-sdk coin.
+type Coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+// Balance is a bank genesis balance.
+type Balance struct {
+	Address string `json:"address"`
+	Coins   []Coin `json:"coins"`
+}
+
+type baseAccount struct {
+	Address       string  `json:"address"`
+	PubKey        *string `json:"pub_key"`
+	AccountNumber string  `json:"account_number"`
+	Sequence      string  `json:"sequence"`
+}
+
+type baseVestingAccount struct {
+	BaseAccount      baseAccount `json:"base_account"`
+	OriginalVesting  []Coin      `json:"original_vesting"`
+	DelegatedFree    []Coin      `json:"delegated_free"`
+	DelegatedVesting []Coin      `json:"delegated_vesting"`
+	EndTime          string      `json:"end_time"`
+}
+
+type plainAccount struct {
+	Type string `json:"@type"`
+	baseAccount
+}
+
+type continuousVestingAccount struct {
+	Type               string             `json:"@type"`
+	BaseVestingAccount baseVestingAccount `json:"base_vesting_account"`
+	StartTime          string             `json:"start_time"`
+}
+
+type delayedVestingAccount struct {
+	Type               string             `json:"@type"`
+	BaseVestingAccount baseVestingAccount `json:"base_vesting_account"`
+}
+
+// Command is the genesis-accounts command.
+var Command = &cli.Command{
+	Name:  "genesis-accounts",
+	Args:  "<allocations.csv|allocations.json>",
+	Short: "Generate genesis accounts, balances and vesting from an allocation file, optionally merged into a genesis.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			genesis = fs.String("genesis", "", "merge the accounts into this genesis file")
+			out     = fs.String("out", "", "write the result to this file instead of stdout")
+			prefix  = fs.String("prefix", "warden", "bech32 prefix of account addresses")
+			first   = fs.Uint64("account-number", 0, "first account number; not allowed with -genesis, which continues after the genesis' highest account number")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if len(args) != 1 {
+				return cli.ErrUsage
+			}
+			allocs, err := readAllocations(args[0])
+			if err != nil {
+				return err
+			}
+
+			var g *object
+			existing := map[string]bool{}
+			if *genesis != "" {
+				fs.Visit(func(f *flag.Flag) {
+					if f.Name == "account-number" {
+						err = errors.New("-account-number cannot be used with -genesis")
+					}
+				})
+				if err != nil {
+					return err
+				}
+				data, err := os.ReadFile(*genesis)
+				if err != nil {
+					return err
+				}
+				g = &object{}
+				if err := json.Unmarshal(data, g); err != nil {
+					return fmt.Errorf("decode %s: %w", *genesis, err)
+				}
+				if *first, err = existingAccounts(g, existing); err != nil {
+					return fmt.Errorf("%s: %w", *genesis, err)
+				}
+			}
+
+			res, problems := build(allocs, *prefix, *first, existing)
+			if len(problems) > 0 {
+				for _, p := range problems {
+					fmt.Fprintln(env.Stderr, p)
+				}
+				return fmt.Errorf("%s: %d problem(s) found", args[0], len(problems))
+			}
+
+			var v any = res.fragment()
+			if g != nil {
+				if err := res.merge(g); err != nil {
+					return err
+				}
+				v = g
+			}
+
+			w := env.Stdout
+			if *out != "" {
+				f, err := os.Create(*out)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			fmt.Fprintf(env.Stderr, "%d accounts, %d vesting, total %s\n", len(res.accounts), res.vesting, formatCoins(res.total))
+			return cli.WriteJSON(w, v)
+		}
+	},
+}
+
+func readAllocations(path string) ([]Allocation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var allocs []Allocation
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&allocs); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+		return allocs, nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s: empty file", path)
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"address", "amount"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%s: missing %q column", path, required)
+		}
+	}
+	get := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	allocs := make([]Allocation, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		allocs = append(allocs, Allocation{
+			Address:       get(row, "address"),
+			Amount:        get(row, "amount"),
+			Vesting:       get(row, "vesting"),
+			VestingAmount: get(row, "vesting_amount"),
+			Start:         get(row, "start"),
+			End:           get(row, "end"),
+		})
+	}
+	return allocs, nil
+}
+
+// existingAccounts records the addresses of the accounts and balances in g
+// and returns the next free account number.
+func existingAccounts(g *object, seen map[string]bool) (uint64, error) {
+	appState, err := g.child("app_state")
+	if err != nil {
+		return 0, err
+	}
+	var next uint64
+	if auth, err := appState.child("auth"); err == nil {
+		var accounts []json.RawMessage
+		if raw, ok := auth.get("accounts"); ok {
+			if err := json.Unmarshal(raw, &accounts); err != nil {
+				return 0, fmt.Errorf("decode auth accounts: %w", err)
+			}
+		}
+		for _, raw := range accounts {
+			addr, num, err := accountInfo(raw)
+			if err != nil {
+				return 0, err
+			}
+			seen[addr] = true
+			if num >= next {
+				next = num + 1
+			}
+		}
+	}
+	if bank, err := appState.child("bank"); err == nil {
+		var balances []Balance
+		if raw, ok := bank.get("balances"); ok {
+			if err := json.Unmarshal(raw, &balances); err != nil {
+				return 0, fmt.Errorf("decode bank balances: %w", err)
+			}
+		}
+		for _, b := range balances {
+			seen[b.Address] = true
+		}
+	}
+	return next, nil
+}
+
+// accountInfo returns the address and account number of a genesis account
+// of any of the standard account types.
+func accountInfo(raw json.RawMessage) (string, uint64, error) {
+	var acc struct {
+		baseAccount
+		BaseAccount        *baseAccount `json:"base_account"`
+		BaseVestingAccount *struct {
+			BaseAccount baseAccount `json:"base_account"`
+		} `json:"base_vesting_account"`
+	}
+	if err := json.Unmarshal(raw, &acc); err != nil {
+		return "", 0, fmt.Errorf("decode account: %w", err)
+	}
+	base := acc.baseAccount
+	switch {
+	case acc.BaseVestingAccount != nil:
+		base = acc.BaseVestingAccount.BaseAccount
+	case acc.BaseAccount != nil:
+		base = *acc.BaseAccount
+	}
+	num, err := strconv.ParseUint(base.AccountNumber, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("account %s: invalid account_number %q", base.Address, base.AccountNumber)
+	}
+	return base.Address, num, nil
+}
+
+type result struct {
+	accounts []any
+	balances []Balance
+	total    map[string]*big.Int
+	vesting  int
+}
+
+// build validates allocs and creates their accounts and balances, sorted
+// by address and numbered from first.
+func build(allocs []Allocation, prefix string, first uint64, existing map[string]bool) (*result, []string) {
+	var problems []string
+	report := func(i int, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("row %d: %s", i+1, fmt.Sprintf(format, args...)))
+	}
+
+	type row struct {
+		alloc   Allocation
+		amount  map[string]*big.Int
+		vesting map[string]*big.Int
+		start   int64
+		end     int64
+	}
+	var rows []row
+	seen := map[string]int{}
+
+	for i, a := range allocs {
+		if err := checkAddress(a.Address, prefix); err != nil {
+			report(i, "address %q: %v", a.Address, err)
+			continue
+		}
+		if prev, ok := seen[a.Address]; ok {
+			report(i, "duplicate address %s, also at row %d", a.Address, prev+1)
+			continue
+		}
+		seen[a.Address] = i
+		if existing[a.Address] {
+			report(i, "address %s already exists in genesis", a.Address)
+			continue
+		}
+
+		amount, err := parseCoins(a.Amount)
+		if err != nil || len(amount) == 0 {
+			report(i, "amount %q: %v", a.Amount, orEmpty(err))
+			continue
+		}
+		r := row{alloc: a, amount: amount}
+
+		if a.Vesting == "" {
+			r.alloc.Vesting = VestingNone
+		}
+		switch r.alloc.Vesting {
+		case VestingNone:
+			if a.VestingAmount != "" || a.Start != "" || a.End != "" {
+				report(i, "vesting fields set without a vesting type")
+				continue
+			}
+		case VestingContinuous, VestingDelayed:
+			r.vesting = amount
+			if a.VestingAmount != "" {
+				if r.vesting, err = parseCoins(a.VestingAmount); err != nil || len(r.vesting) == 0 {
+					report(i, "vesting_amount %q: %v", a.VestingAmount, orEmpty(err))
+					continue
+				}
+				if d := exceeds(r.vesting, amount); d != "" {
+					report(i, "vesting_amount exceeds amount in %s", d)
+					continue
+				}
+			}
+			if r.end, err = parseTime(a.End); err != nil {
+				report(i, "end %q: %v", a.End, err)
+				continue
+			}
+			if r.alloc.Vesting == VestingContinuous {
+				if r.start, err = parseTime(a.Start); err != nil {
+					report(i, "start %q: %v", a.Start, err)
+					continue
+				}
+				if r.start >= r.end {
+					report(i, "vesting start %s is not before end %s", a.Start, a.End)
+					continue
+				}
+			} else if a.Start != "" {
+				report(i, "delayed vesting has no start time")
+				continue
+			}
+		default:
+			report(i, "unknown vesting type %q", a.Vesting)
+			continue
+		}
+		rows = append(rows, r)
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].alloc.Address < rows[j].alloc.Address })
+
+	res := &result{accounts: []any{}, balances: []Balance{}, total: map[string]*big.Int{}}
+	for i, r := range rows {
+		base := baseAccount{
+			Address:       r.alloc.Address,
+			AccountNumber: strconv.FormatUint(first+uint64(i), 10),
+			Sequence:      "0",
+		}
+		switch r.alloc.Vesting {
+		case VestingNone:
+			res.accounts = append(res.accounts, plainAccount{Type: "/cosmos.auth.v1beta1.BaseAccount", baseAccount: base})
+		case VestingContinuous:
+			res.vesting++
+			res.accounts = append(res.accounts, continuousVestingAccount{
+				Type:               "/cosmos.vesting.v1beta1.ContinuousVestingAccount",
+				BaseVestingAccount: vestingBase(base, r.vesting, r.end),
+				StartTime:          strconv.FormatInt(r.start, 10),
+			})
+		case VestingDelayed:
+			res.vesting++
+			res.accounts = append(res.accounts, delayedVestingAccount{
+				Type:               "/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				BaseVestingAccount: vestingBase(base, r.vesting, r.end),
+			})
+		}
+		res.balances = append(res.balances, Balance{Address: r.alloc.Address, Coins: sortedCoins(r.amount)})
+		for d, a := range r.amount {
+			if res.total[d] == nil {
+				res.total[d] = new(big.Int)
+			}
+			res.total[d].Add(res.total[d], a)
+		}
+	}
+	return res, nil
+}
+
+func vestingBase(base baseAccount, vesting map[string]*big.Int, end int64) baseVestingAccount {
+	return baseVestingAccount{
+		BaseAccount:      base,
+		OriginalVesting:  sortedCoins(vesting),
+		DelegatedFree:    []Coin{},
+		DelegatedVesting: []Coin{},
+		EndTime:          strconv.FormatInt(end, 10),
+	}
+}
+
+func orEmpty(err error) error {
+	if err == nil {
+		return errors.New("no coins")
+	}
+	return err
+}
+
+// fragment returns the generated entries as auth and bank genesis
+// fragments.
+func (r *result) fragment() any {
+	return map[string]any{
+		"auth": map[string]any{"accounts": r.accounts},
+		"bank": map[string]any{"balances": r.balances, "supply": sortedCoins(r.total)},
+	}
+}
+
+// merge appends the accounts and balances to genesis g and adds their total
+// to the bank supply, unless the supply is left empty for the bank module
+// to compute.
+func (r *result) merge(g *object) error {
+	appState, err := g.child("app_state")
+	if err != nil {
+		return err
+	}
+	auth, err := appState.child("auth")
+	if err != nil {
+		return err
+	}
+	bank, err := appState.child("bank")
+	if err != nil {
+		return err
+	}
+
+	var accounts []json.RawMessage
+	if raw, ok := auth.get("accounts"); ok {
+		if err := json.Unmarshal(raw, &accounts); err != nil {
+			return fmt.Errorf("decode auth accounts: %w", err)
+		}
+	}
+	for _, a := range r.accounts {
+		raw, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		accounts = append(accounts, raw)
+	}
+	if err := auth.set("accounts", accounts); err != nil {
+		return err
+	}
+
+	var balances []json.RawMessage
+	if raw, ok := bank.get("balances"); ok {
+		if err := json.Unmarshal(raw, &balances); err != nil {
+			return fmt.Errorf("decode bank balances: %w", err)
+		}
+	}
+	for _, b := range r.balances {
+		raw, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		balances = append(balances, raw)
+	}
+	if err := bank.set("balances", balances); err != nil {
+		return err
+	}
+
+	var supply []Coin
+	if raw, ok := bank.get("supply"); ok {
+		if err := json.Unmarshal(raw, &supply); err != nil {
+			return fmt.Errorf("decode bank supply: %w", err)
+		}
+	}
+	if len(supply) > 0 {
+		total := map[string]*big.Int{}
+		for _, c := range supply {
+			a, ok := new(big.Int).SetString(c.Amount, 10)
+			if !ok {
+				return fmt.Errorf("invalid supply amount %q for %s", c.Amount, c.Denom)
+			}
+			total[c.Denom] = a
+		}
+		for d, a := range r.total {
+			if total[d] == nil {
+				total[d] = new(big.Int)
+			}
+			total[d].Add(total[d], a)
+		}
+		if err := bank.set("supply", sortedCoins(total)); err != nil {
+			return err
+		}
+	}
+
+	if err := appState.set("auth", auth); err != nil {
+		return err
+	}
+	if err := appState.set("bank", bank); err != nil {
+		return err
+	}
+	return g.set("app_state", appState)
+}
+
+// checkAddress checks that addr is a bech32 address with the given prefix
+// and a 20- or 32-byte payload.
+func checkAddress(addr, prefix string) error {
+	hrp, data, err := bech32.Decode(addr)
+	if err != nil {
+		return err
+	}
+	if hrp != prefix {
+		return fmt.Errorf("prefix %q, expected %q", hrp, prefix)
+	}
+	if n := len(data); n != 20 && n != 32 {
+		return fmt.Errorf("%d-byte payload, expected 20 or 32", n)
+	}
+	return nil
+}
+
+var denomRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9/:._-]{2,127}$`)
+
+// parseCoins parses a comma-separated list of coins such as "5uward,10ufoo".
+func parseCoins(s string) (map[string]*big.Int, error) {
+	coins := map[string]*big.Int{}
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		i := strings.IndexFunc(c, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid coin %q", c)
+		}
+		amount, ok := new(big.Int).SetString(c[:i], 10)
+		denom := c[i:]
+		if !ok || amount.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid amount in %q", c)
+		}
+		if !denomRe.MatchString(denom) {
+			return nil, fmt.Errorf("invalid denom %q", denom)
+		}
+		if coins[denom] != nil {
+			return nil, fmt.Errorf("denom %s listed twice", denom)
+		}
+		coins[denom] = amount
+	}
+	return coins, nil
+}
+
+// exceeds returns the first denom in which a is larger than b, or "".
+func exceeds(a, b map[string]*big.Int) string {
+	for _, c := range sortedCoins(a) {
+		if b[c.Denom] == nil || a[c.Denom].Cmp(b[c.Denom]) > 0 {
+			return c.Denom
+		}
+	}
+	return ""
+}
+
+func sortedCoins(m map[string]*big.Int) []Coin {
+	coins := make([]Coin, 0, len(m))
+	for d, a := range m {
+		coins = append(coins, Coin{Denom: d, Amount: a.String()})
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i].Denom < coins[j].Denom })
+	return coins
+}
+
+func formatCoins(m map[string]*big.Int) string {
+	var parts []string
+	for _, c := range sortedCoins(m) {
+		parts = append(parts, c.Amount+c.Denom)
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseTime parses an RFC 3339 time or unix seconds.
+func parseTime(s string) (int64, error) {
+	if s == "" {
+		return 0, errors.New("missing")
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, errors.New("not RFC 3339 or unix seconds")
+	}
+	return t.Unix(), nil
+}
+
+// object is a JSON object that keeps the order of its keys, so a genesis
+// file round-trips with a minimal diff.
+type object struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+func (o *object) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil {
+		return err
+	} else if t != json.Delim('{') {
+		return errors.New("not a JSON object")
+	}
+	o.keys, o.values = nil, map[string]json.RawMessage{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := t.(string)
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		if _, dup := o.values[key]; !dup {
+			o.keys = append(o.keys, key)
+		}
+		o.values[key] = v
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(o.values[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (o *object) get(key string) (json.RawMessage, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// child decodes the object stored under key.
+func (o *object) child(key string) (*object, error) {
+	raw, ok := o.values[key]
+	if !ok {
+		return nil, fmt.Errorf("missing %q", key)
+	}
+	c := &object{}
+	if err := json.Unmarshal(raw, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	return c, nil
+}
+
+// set stores v under key, appending the key when it is new.
+func (o *object) set(key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = raw
+	return nil
+}
@@ -0,0 +1,232 @@
+package genesisaccounts
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/warden-protocol/networks/internal/bech32"
+)
+
+func TestParseCoins(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]string // nil when an error is expected
+	}{
+		{"single", "5uward", map[string]string{"uward": "5"}},
+		{"several", "5uward, 7ufoo", map[string]string{"uward": "5", "ufoo": "7"}},
+		{"big amount", "100000000000000000000000000uward", map[string]string{"uward": "100000000000000000000000000"}},
+		{"ibc denom", "1ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", map[string]string{"ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2": "1"}},
+		{"empty", "", map[string]string{}},
+		{"trailing comma", "5uward,", map[string]string{"uward": "5"}},
+		{"no amount", "uward", nil},
+		{"no denom", "5", nil},
+		{"zero", "0uward", nil},
+		{"short denom", "5uw", nil},
+		{"bad denom", "5u ward", nil},
+		{"negative", "-5uward", nil},
+		{"twice", "5uward,6uward", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCoins(tt.in)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("parseCoins(%q) = %v, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCoins(%q): %v", tt.in, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseCoins(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			for d, a := range tt.want {
+				if got[d] == nil || got[d].String() != a {
+					t.Errorf("parseCoins(%q)[%s] = %v, want %s", tt.in, d, got[d], a)
+				}
+			}
+		})
+	}
+}
+
+// testAddress returns the bech32 address with prefix hrp of a payload of n
+// bytes b.
+func testAddress(t *testing.T, hrp string, b byte, n int) string {
+	t.Helper()
+	addr, err := bech32.Encode(hrp, bytes.Repeat([]byte{b}, n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func TestCheckAddress(t *testing.T) {
+	valid := testAddress(t, "warden", 1, 20)
+	tests := []struct {
+		name, addr, err string
+	}{
+		{"account", valid, ""},
+		{"module or contract", testAddress(t, "warden", 1, 32), ""},
+		{"upper case", strings.ToUpper(valid), ""},
+		{"wrong prefix", testAddress(t, "cosmos", 1, 20), `prefix "cosmos", expected "warden"`},
+		{"short payload", testAddress(t, "warden", 1, 19), "19-byte payload"},
+		{"bad checksum", valid[:len(valid)-1] + "q", "invalid checksum"},
+		{"empty", "", "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAddress(tt.addr, "warden")
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("checkAddress(%q) = %v, want nil", tt.addr, err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("checkAddress(%q) = %v, want an error containing %q", tt.addr, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	a, b := testAddress(t, "warden", 0x01, 20), testAddress(t, "warden", 0x02, 20)
+
+	tests := []struct {
+		name    string
+		allocs  []Allocation
+		problem string // substring of the first problem; "" when none is expected
+		vesting int
+	}{
+		{
+			name:   "plain",
+			allocs: []Allocation{{Address: a, Amount: "5uward"}},
+		},
+		{
+			name: "vesting",
+			allocs: []Allocation{
+				{Address: a, Amount: "10uward", Vesting: VestingContinuous, VestingAmount: "5uward", Start: "2024-03-01T00:00:00Z", End: "2025-03-01T00:00:00Z"},
+				{Address: b, Amount: "10uward", Vesting: VestingDelayed, End: "1740000000"},
+			},
+			vesting: 2,
+		},
+		{
+			name:    "bad address",
+			allocs:  []Allocation{{Address: "warden1invalid", Amount: "5uward"}},
+			problem: "address",
+		},
+		{
+			name:    "wrong prefix",
+			allocs:  []Allocation{{Address: testAddress(t, "cosmos", 1, 20), Amount: "5uward"}},
+			problem: "prefix",
+		},
+		{
+			name:    "duplicate",
+			allocs:  []Allocation{{Address: a, Amount: "5uward"}, {Address: a, Amount: "6uward"}},
+			problem: "duplicate address",
+		},
+		{
+			name:    "existing",
+			allocs:  []Allocation{{Address: b, Amount: "5uward"}},
+			problem: "already exists",
+		},
+		{
+			name:    "no amount",
+			allocs:  []Allocation{{Address: a, Amount: ""}},
+			problem: "no coins",
+		},
+		{
+			name:    "vesting fields without type",
+			allocs:  []Allocation{{Address: a, Amount: "5uward", End: "1740000000"}},
+			problem: "without a vesting type",
+		},
+		{
+			name:    "vesting exceeds amount",
+			allocs:  []Allocation{{Address: a, Amount: "5uward", Vesting: VestingDelayed, VestingAmount: "6uward", End: "1740000000"}},
+			problem: "exceeds amount",
+		},
+		{
+			name:    "vesting in other denom",
+			allocs:  []Allocation{{Address: a, Amount: "5uward", Vesting: VestingDelayed, VestingAmount: "5ufoo", End: "1740000000"}},
+			problem: "exceeds amount in ufoo",
+		},
+		{
+			name:    "start after end",
+			allocs:  []Allocation{{Address: a, Amount: "5uward", Vesting: VestingContinuous, Start: "1740000000", End: "1730000000"}},
+			problem: "not before end",
+		},
+		{
+			name:    "delayed with start",
+			allocs:  []Allocation{{Address: a, Amount: "5uward", Vesting: VestingDelayed, Start: "1730000000", End: "1740000000"}},
+			problem: "no start time",
+		},
+		{
+			name:    "missing end",
+			allocs:  []Allocation{{Address: a, Amount: "5uward", Vesting: VestingDelayed}},
+			problem: "missing",
+		},
+		{
+			name:    "unknown type",
+			allocs:  []Allocation{{Address: a, Amount: "5uward", Vesting: "periodic"}},
+			problem: "unknown vesting type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := map[string]bool{}
+			if tt.problem != "" {
+				existing[b] = true
+			}
+			res, problems := build(tt.allocs, "warden", 7, existing)
+			if tt.problem != "" {
+				if len(problems) == 0 || !strings.Contains(problems[0], tt.problem) {
+					t.Fatalf("build problems = %q, want one containing %q", problems, tt.problem)
+				}
+				return
+			}
+			if len(problems) > 0 {
+				t.Fatalf("build problems = %q", problems)
+			}
+			if len(res.accounts) != len(tt.allocs) || len(res.balances) != len(tt.allocs) {
+				t.Fatalf("build returned %d accounts and %d balances, want %d", len(res.accounts), len(res.balances), len(tt.allocs))
+			}
+			if res.vesting != tt.vesting {
+				t.Errorf("build vesting = %d, want %d", res.vesting, tt.vesting)
+			}
+		})
+	}
+}
+
+func TestBuildNumbersSortedAccounts(t *testing.T) {
+	// Accounts are numbered in address order, not input order.
+	lo, hi := testAddress(t, "warden", 0x01, 20), testAddress(t, "warden", 0x02, 20)
+	if hi < lo {
+		lo, hi = hi, lo
+	}
+	allocs := []Allocation{
+		{Address: hi, Amount: "5uward,1ufoo"},
+		{Address: lo, Amount: "7uward"},
+	}
+	res, problems := build(allocs, "warden", 7, nil)
+	if len(problems) > 0 {
+		t.Fatalf("build problems = %q", problems)
+	}
+
+	for i, want := range []struct {
+		addr, number string
+	}{{lo, "7"}, {hi, "8"}} {
+		acc := res.accounts[i].(plainAccount)
+		if acc.Address != want.addr || acc.AccountNumber != want.number {
+			t.Errorf("accounts[%d] = %s #%s, want %s #%s", i, acc.Address, acc.AccountNumber, want.addr, want.number)
+		}
+		if res.balances[i].Address != want.addr {
+			t.Errorf("balances[%d] = %s, want %s", i, res.balances[i].Address, want.addr)
+		}
+	}
+	if got := res.total["uward"].String(); got != "12" {
+		t.Errorf("total uward = %s, want 12", got)
+	}
+	if got := res.total["ufoo"].String(); got != "1" {
+		t.Errorf("total ufoo = %s, want 1", got)
+	}
+}
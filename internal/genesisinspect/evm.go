@@ -0,0 +1,145 @@
+package genesisinspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// evmReport summarizes the evm and feemarket module state of a genesis.
+type evmReport struct {
+	ChainID  uint64   `json:"chain_id,omitempty"`
+	Denom    string   `json:"denom"`
+	BaseFee  string   `json:"base_fee,omitempty"`
+	Problems []string `json:"problems"`
+}
+
+type evmState struct {
+	Params *struct {
+		EVMDenom    string `json:"evm_denom"`
+		ChainConfig *struct {
+			ChainID json.RawMessage `json:"chain_id"`
+		} `json:"chain_config"`
+	} `json:"params"`
+}
+
+type feemarketState struct {
+	Params *struct {
+		NoBaseFee                bool            `json:"no_base_fee"`
+		BaseFee                  string          `json:"base_fee"`
+		MinGasPrice              string          `json:"min_gas_price"`
+		BaseFeeChangeDenominator json.RawMessage `json:"base_fee_change_denominator"`
+		ElasticityMultiplier     json.RawMessage `json:"elasticity_multiplier"`
+	} `json:"params"`
+}
+
+// checkEVM validates the evm and feemarket params against the EVM ID of an
+// evmos-style chain-id ("name_EVMID-version"): both modules present, the
+// chain config (when it carries a chain ID) agreeing with the chain-id, an
+// EVM denom held by some account, and a usable base fee. It returns nil for
+// genesis files with neither an EVM chain-id nor an evm module.
+func checkEVM(chainID string, appState map[string]json.RawMessage) (*evmReport, error) {
+	evmID, evmChain := networks.EVMChainID(chainID)
+	rawEVM, hasEVM := appState["evm"]
+	if !evmChain && !hasEVM {
+		return nil, nil
+	}
+
+	r := &evmReport{ChainID: evmID, Problems: []string{}}
+	report := func(format string, args ...any) {
+		r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+	}
+
+	var evm evmState
+	if hasEVM {
+		if err := json.Unmarshal(rawEVM, &evm); err != nil {
+			return nil, fmt.Errorf("decode evm state: %w", err)
+		}
+	}
+	switch {
+	case !hasEVM:
+		report("chain-id %s has EVM ID %d but app_state has no evm module", chainID, evmID)
+	case evm.Params == nil:
+		report("evm params are missing")
+	default:
+		r.Denom = evm.Params.EVMDenom
+		if r.Denom == "" {
+			report("evm_denom is empty")
+		}
+
+		var configID string
+		if cc := evm.Params.ChainConfig; cc != nil && len(cc.ChainID) > 0 && string(cc.ChainID) != "null" {
+			configID = strings.Trim(string(cc.ChainID), `"`)
+		}
+		switch {
+		case configID != "" && evmChain && configID != fmt.Sprint(evmID):
+			report("chain_config.chain_id is %s, chain-id %s has EVM ID %d", configID, chainID, evmID)
+		case configID == "" && !evmChain:
+			report("chain-id %s has no EVM ID (expected name_EVMID-version) and chain_config sets no chain_id", chainID)
+		}
+	}
+
+	if r.Denom != "" {
+		held, err := denomHeld(appState, r.Denom)
+		if err != nil {
+			return nil, err
+		}
+		if !held {
+			report("no account holds the EVM denom %s", r.Denom)
+		}
+	}
+
+	var feemarket feemarketState
+	if raw, ok := appState["feemarket"]; ok {
+		if err := json.Unmarshal(raw, &feemarket); err != nil {
+			return nil, fmt.Errorf("decode feemarket state: %w", err)
+		}
+	}
+	if p := feemarket.Params; p == nil {
+		report("feemarket params are missing")
+	} else {
+		if p.MinGasPrice != "" {
+			if _, ok := new(big.Rat).SetString(p.MinGasPrice); !ok {
+				report("feemarket min_gas_price %q is not a decimal", p.MinGasPrice)
+			}
+		}
+		if !p.NoBaseFee {
+			r.BaseFee = p.BaseFee
+			if fee, ok := new(big.Rat).SetString(p.BaseFee); !ok || fee.Sign() <= 0 {
+				report("feemarket base_fee %q is not a positive amount", p.BaseFee)
+			}
+			// A zero denominator makes EndBlock divide by zero once blocks
+			// are produced.
+			if d := strings.Trim(string(p.BaseFeeChangeDenominator), `"`); d == "" || d == "0" || d == "null" {
+				report("feemarket base_fee_change_denominator must be positive")
+			}
+			if m := strings.Trim(string(p.ElasticityMultiplier), `"`); m == "" || m == "0" || m == "null" {
+				report("feemarket elasticity_multiplier must be positive")
+			}
+		}
+	}
+	return r, nil
+}
+
+// denomHeld reports whether some bank balance includes denom.
+func denomHeld(appState map[string]json.RawMessage, denom string) (bool, error) {
+	raw, ok := appState["bank"]
+	if !ok {
+		return false, nil
+	}
+	var bank bankState
+	if err := json.Unmarshal(raw, &bank); err != nil {
+		return false, fmt.Errorf("decode bank state: %w", err)
+	}
+	for _, b := range bank.Balances {
+		for _, c := range b.Coins {
+			if c.Denom == denom {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,116 @@
+package genesisinspect
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckEVM(t *testing.T) {
+	const (
+		bank      = `{"balances":[{"address":"warden1x","coins":[{"denom":"award","amount":"1"}]}]}`
+		evm       = `{"params":{"evm_denom":"award","chain_config":{"chain_id":"10010"}}}`
+		feemarket = `{"params":{"no_base_fee":false,"base_fee":"1000000000","min_gas_price":"0.000000000000000000","base_fee_change_denominator":8,"elasticity_multiplier":2}}`
+	)
+
+	tests := []struct {
+		name     string
+		chainID  string
+		modules  map[string]string // app_state modules; the valid ones above unless replaced
+		nilEVM   bool              // no report expected
+		problems []string          // substrings of the expected problems, in order
+	}{
+		{name: "valid", chainID: "chiado_10010-1"},
+		{name: "no EVM", chainID: "buenavista-1", modules: map[string]string{"evm": "", "feemarket": ""}, nilEVM: true},
+		{name: "no chain config chain ID", chainID: "chiado_10010-1", modules: map[string]string{"evm": `{"params":{"evm_denom":"award"}}`}},
+		{name: "chain config without EVM chain-id", chainID: "buenavista-1"},
+		{name: "no base fee", chainID: "chiado_10010-1", modules: map[string]string{"feemarket": `{"params":{"no_base_fee":true}}`}},
+		{
+			name:     "evm module missing",
+			chainID:  "chiado_10010-1",
+			modules:  map[string]string{"evm": ""},
+			problems: []string{"chain-id chiado_10010-1 has EVM ID 10010 but app_state has no evm module"},
+		},
+		{
+			name:     "evm params missing",
+			chainID:  "chiado_10010-1",
+			modules:  map[string]string{"evm": `{}`},
+			problems: []string{"evm params are missing"},
+		},
+		{
+			name:     "chain config mismatch",
+			chainID:  "chiado_10001-1",
+			problems: []string{"chain_config.chain_id is 10010, chain-id chiado_10001-1 has EVM ID 10001"},
+		},
+		{
+			name:     "no EVM ID anywhere",
+			chainID:  "buenavista-1",
+			modules:  map[string]string{"evm": `{"params":{"evm_denom":"award","chain_config":{"chain_id":null}}}`},
+			problems: []string{"chain-id buenavista-1 has no EVM ID"},
+		},
+		{
+			name:     "empty denom",
+			chainID:  "chiado_10010-1",
+			modules:  map[string]string{"evm": `{"params":{"evm_denom":""}}`},
+			problems: []string{"evm_denom is empty"},
+		},
+		{
+			name:     "denom not held",
+			chainID:  "chiado_10010-1",
+			modules:  map[string]string{"bank": `{"balances":[{"address":"warden1x","coins":[{"denom":"uward","amount":"1"}]}]}`},
+			problems: []string{"no account holds the EVM denom award"},
+		},
+		{
+			name:     "feemarket missing",
+			chainID:  "chiado_10010-1",
+			modules:  map[string]string{"feemarket": ""},
+			problems: []string{"feemarket params are missing"},
+		},
+		{
+			name:    "bad base fee params",
+			chainID: "chiado_10010-1",
+			modules: map[string]string{"feemarket": `{"params":{"base_fee":"0","min_gas_price":"x","base_fee_change_denominator":0,"elasticity_multiplier":"0"}}`},
+			problems: []string{
+				`feemarket min_gas_price "x" is not a decimal`,
+				`feemarket base_fee "0" is not a positive amount`,
+				"base_fee_change_denominator must be positive",
+				"elasticity_multiplier must be positive",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appState := map[string]json.RawMessage{
+				"bank":      json.RawMessage(bank),
+				"evm":       json.RawMessage(evm),
+				"feemarket": json.RawMessage(feemarket),
+			}
+			for m, raw := range tt.modules {
+				if raw == "" {
+					delete(appState, m)
+				} else {
+					appState[m] = json.RawMessage(raw)
+				}
+			}
+
+			r, err := checkEVM(tt.chainID, appState)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.nilEVM {
+				if r != nil {
+					t.Errorf("checkEVM() = %+v, want nil", r)
+				}
+				return
+			}
+			if len(r.Problems) != len(tt.problems) {
+				t.Fatalf("problems = %q, want %d containing %q", r.Problems, len(tt.problems), tt.problems)
+			}
+			for i, p := range tt.problems {
+				if !strings.Contains(r.Problems[i], p) {
+					t.Errorf("problems[%d] = %q, want it to contain %q", i, r.Problems[i], p)
+				}
+			}
+		})
+	}
+}
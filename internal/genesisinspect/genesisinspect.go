@@ -0,0 +1,555 @@
+// Package genesisinspect implements the genesis-inspect command, which prints
+// a structured summary of a genesis file.
+//
+// It accepts both init genesis files (validators only present as gentxs in
+// app_state.genutil) and final/exported genesis files (validators present in
+// app_state.staking), and reports the validator set with voting power and
+// commission, total supply per denom, account counts, module params and
+// consensus params. It also checks the x/warden module state, the EVM params,
+// the supply invariants and the vesting schedules (see checkWarden, checkEVM,
+// checkInvariants and checkVesting); with -check, a problem makes it exit
+// non-zero.
+//
+// Usage:
+//
+//	genesis-inspect [-format text|json] testnets/buenavista/genesis.json
+//	genesis-inspect -network alfama
+//	genesis-inspect -check -network alfama
+package genesisinspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+)
+
+// powerReduction is the default cosmos-sdk conversion factor between bonded
+// tokens and consensus voting power.
+var powerReduction = big.NewInt(1_000_000)
+
+type coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+type commissionRates struct {
+	Rate          string `json:"rate"`
+	MaxRate       string `json:"max_rate"`
+	MaxChangeRate string `json:"max_change_rate"`
+}
+
+type description struct {
+	Moniker string `json:"moniker"`
+}
+
+type genesisDoc struct {
+	ChainID         string                     `json:"chain_id"`
+	GenesisTime     string                     `json:"genesis_time"`
+	InitialHeight   json.RawMessage            `json:"initial_height"`
+	ConsensusParams json.RawMessage            `json:"consensus_params"`
+	Consensus       *consensusSection          `json:"consensus"`
+	AppState        map[string]json.RawMessage `json:"app_state"`
+}
+
+type consensusSection struct {
+	Params json.RawMessage `json:"params"`
+}
+
+type stakingState struct {
+	Params struct {
+		BondDenom string `json:"bond_denom"`
+	} `json:"params"`
+	Validators []struct {
+		OperatorAddress string      `json:"operator_address"`
+		Jailed          bool        `json:"jailed"`
+		Status          string      `json:"status"`
+		Tokens          string      `json:"tokens"`
+		Description     description `json:"description"`
+		Commission      struct {
+			CommissionRates commissionRates `json:"commission_rates"`
+		} `json:"commission"`
+	} `json:"validators"`
+}
+
+type genutilState struct {
+	GenTxs []struct {
+		Body struct {
+			Messages []json.RawMessage `json:"messages"`
+		} `json:"body"`
+	} `json:"gen_txs"`
+}
+
+type msgCreateValidator struct {
+	Type             string          `json:"@type"`
+	Description      description     `json:"description"`
+	Commission       commissionRates `json:"commission"`
+	ValidatorAddress string          `json:"validator_address"`
+	Value            coin            `json:"value"`
+}
+
+type bankState struct {
+	Balances []struct {
+		Address string `json:"address"`
+		Coins   []coin `json:"coins"`
+	} `json:"balances"`
+	Supply []coin `json:"supply"`
+}
+
+type authState struct {
+	Accounts []struct {
+		Type string `json:"@type"`
+	} `json:"accounts"`
+}
+
+type validator struct {
+	Moniker         string          `json:"moniker"`
+	OperatorAddress string          `json:"operator_address"`
+	Status          string          `json:"status"`
+	Tokens          string          `json:"tokens"`
+	Power           string          `json:"power"`
+	Commission      commissionRates `json:"commission"`
+	Source          string          `json:"source"`
+}
+
+type accountStats struct {
+	Total    int            `json:"total"`
+	ByType   map[string]int `json:"by_type"`
+	Balances int            `json:"balances"`
+}
+
+type summary struct {
+	ChainID         string                     `json:"chain_id"`
+	GenesisTime     string                     `json:"genesis_time"`
+	InitialHeight   string                     `json:"initial_height"`
+	Validators      []validator                `json:"validators"`
+	TotalPower      string                     `json:"total_power"`
+	Supply          []coin                     `json:"supply"`
+	BalancesSum     []coin                     `json:"balances_sum"`
+	Accounts        accountStats               `json:"accounts"`
+	ModuleParams    map[string]json.RawMessage `json:"module_params"`
+	ConsensusParams json.RawMessage            `json:"consensus_params"`
+	Invariants      []invariant                `json:"invariants"`
+	Vesting         *vestingReport             `json:"vesting"`
+	Warden          *wardenReport              `json:"warden,omitempty"`
+	EVM             *evmReport                 `json:"evm,omitempty"`
+}
+
+// Command is the genesis-inspect command.
+var Command = &cli.Command{
+	Name:  "genesis-inspect",
+	Args:  "[<genesis.json>]",
+	Short: "Print a summary of a genesis file: validators, supply, accounts and params.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		format := cli.FormatFlag(fs, "text", "json")
+		name := fs.String("network", "", "inspect the genesis of this network instead of a file")
+		check := fs.Bool("check", false, "exit non-zero when the warden state, an EVM param, a supply invariant or a vesting schedule is broken")
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			var path string
+			switch {
+			case len(args) == 1 && *name == "":
+				path = args[0]
+			case len(args) == 0 && *name != "":
+				dir, err := network.Resolve(env.Root, *name)
+				if err != nil {
+					return err
+				}
+				n, err := network.Load(dir)
+				if err != nil {
+					return err
+				}
+				if n.Genesis == "" {
+					return fmt.Errorf("network %s has no genesis file", n.Name)
+				}
+				path = n.Genesis
+			default:
+				return cli.ErrUsage
+			}
+			return run(path, format, *check, env.Stdout)
+		}
+	},
+}
+
+func run(path string, format *cli.Format, check bool, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var doc genesisDoc
+	if err := json.NewDecoder(f).Decode(&doc); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	s, err := summarize(&doc)
+	if err != nil {
+		return err
+	}
+
+	if format.JSON() {
+		err = cli.WriteJSON(w, s)
+	} else {
+		err = printText(w, s)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !check {
+		return nil
+	}
+	for _, inv := range s.Invariants {
+		if !inv.OK {
+			return fmt.Errorf("%s: invariant broken: %s", path, inv.Name)
+		}
+	}
+	if n := len(s.Vesting.Problems); n > 0 {
+		return fmt.Errorf("%s: %d invalid vesting schedule(s)", path, n)
+	}
+	if s.Warden != nil && len(s.Warden.Problems) > 0 {
+		return fmt.Errorf("%s: %d warden state problem(s)", path, len(s.Warden.Problems))
+	}
+	if s.EVM != nil && len(s.EVM.Problems) > 0 {
+		return fmt.Errorf("%s: %d EVM param problem(s)", path, len(s.EVM.Problems))
+	}
+	return nil
+}
+
+func summarize(doc *genesisDoc) (*summary, error) {
+	s := &summary{
+		ChainID:       doc.ChainID,
+		GenesisTime:   doc.GenesisTime,
+		InitialHeight: strings.Trim(string(doc.InitialHeight), `"`),
+		ModuleParams:  map[string]json.RawMessage{},
+	}
+
+	// SDK >= 0.50 nests consensus params under "consensus", older versions
+	// use the CometBFT "consensus_params" key.
+	s.ConsensusParams = doc.ConsensusParams
+	if doc.Consensus != nil && len(doc.Consensus.Params) > 0 {
+		s.ConsensusParams = doc.Consensus.Params
+	}
+
+	validators, err := collectValidators(doc.AppState)
+	if err != nil {
+		return nil, err
+	}
+	s.Validators = validators
+
+	totalPower := new(big.Int)
+	for _, v := range validators {
+		p, _ := new(big.Int).SetString(v.Power, 10)
+		if p != nil {
+			totalPower.Add(totalPower, p)
+		}
+	}
+	s.TotalPower = totalPower.String()
+
+	if raw, ok := doc.AppState["bank"]; ok {
+		var bank bankState
+		if err := json.Unmarshal(raw, &bank); err != nil {
+			return nil, fmt.Errorf("decode bank state: %w", err)
+		}
+		s.Supply = bank.Supply
+		s.Accounts.Balances = len(bank.Balances)
+
+		sums := map[string]*big.Int{}
+		for _, b := range bank.Balances {
+			for _, c := range b.Coins {
+				if err := addCoin(sums, c); err != nil {
+					return nil, fmt.Errorf("balance of %s: %w", b.Address, err)
+				}
+			}
+		}
+		s.BalancesSum = sortedCoins(sums)
+	}
+
+	if raw, ok := doc.AppState["auth"]; ok {
+		var auth authState
+		if err := json.Unmarshal(raw, &auth); err != nil {
+			return nil, fmt.Errorf("decode auth state: %w", err)
+		}
+		s.Accounts.Total = len(auth.Accounts)
+		s.Accounts.ByType = map[string]int{}
+		for _, a := range auth.Accounts {
+			s.Accounts.ByType[a.Type]++
+		}
+	}
+
+	for module, raw := range doc.AppState {
+		var m struct {
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(raw, &m); err != nil {
+			// Some modules (e.g. runtime) store non-object state.
+			continue
+		}
+		if len(m.Params) > 0 && string(m.Params) != "null" {
+			s.ModuleParams[module] = m.Params
+		}
+	}
+
+	if s.Invariants, err = checkInvariants(doc.AppState); err != nil {
+		return nil, err
+	}
+	if s.Vesting, err = checkVesting(doc.AppState, doc.GenesisTime); err != nil {
+		return nil, err
+	}
+
+	if s.Warden, err = checkWarden(doc.AppState); err != nil {
+		return nil, err
+	}
+	if s.EVM, err = checkEVM(doc.ChainID, doc.AppState); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// collectValidators returns the validators from the staking state, falling
+// back to the MsgCreateValidator messages in genutil for init genesis files.
+func collectValidators(appState map[string]json.RawMessage) ([]validator, error) {
+	var validators []validator
+
+	if raw, ok := appState["staking"]; ok {
+		var staking stakingState
+		if err := json.Unmarshal(raw, &staking); err != nil {
+			return nil, fmt.Errorf("decode staking state: %w", err)
+		}
+		for _, v := range staking.Validators {
+			status := v.Status
+			if v.Jailed {
+				status += " (jailed)"
+			}
+			validators = append(validators, validator{
+				Moniker:         v.Description.Moniker,
+				OperatorAddress: v.OperatorAddress,
+				Status:          status,
+				Tokens:          v.Tokens,
+				Power:           power(v.Tokens),
+				Commission:      v.Commission.CommissionRates,
+				Source:          "staking",
+			})
+		}
+	}
+
+	if raw, ok := appState["genutil"]; ok {
+		var genutil genutilState
+		if err := json.Unmarshal(raw, &genutil); err != nil {
+			return nil, fmt.Errorf("decode genutil state: %w", err)
+		}
+		for i, tx := range genutil.GenTxs {
+			for _, rawMsg := range tx.Body.Messages {
+				var msg msgCreateValidator
+				if err := json.Unmarshal(rawMsg, &msg); err != nil {
+					return nil, fmt.Errorf("decode gentx %d: %w", i, err)
+				}
+				if msg.Type != "/cosmos.staking.v1beta1.MsgCreateValidator" {
+					continue
+				}
+				validators = append(validators, validator{
+					Moniker:         msg.Description.Moniker,
+					OperatorAddress: msg.ValidatorAddress,
+					Status:          "gentx",
+					Tokens:          msg.Value.Amount + msg.Value.Denom,
+					Power:           power(msg.Value.Amount),
+					Commission:      msg.Commission,
+					Source:          "gentx",
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(validators, func(i, j int) bool {
+		pi, _ := new(big.Int).SetString(validators[i].Power, 10)
+		pj, _ := new(big.Int).SetString(validators[j].Power, 10)
+		if pi == nil || pj == nil {
+			return pi != nil
+		}
+		return pi.Cmp(pj) > 0
+	})
+
+	return validators, nil
+}
+
+func power(tokens string) string {
+	t, ok := new(big.Int).SetString(tokens, 10)
+	if !ok {
+		return "?"
+	}
+	return t.Quo(t, powerReduction).String()
+}
+
+func addCoin(sums map[string]*big.Int, c coin) error {
+	amt, ok := new(big.Int).SetString(c.Amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %q for denom %s", c.Amount, c.Denom)
+	}
+	if sums[c.Denom] == nil {
+		sums[c.Denom] = new(big.Int)
+	}
+	sums[c.Denom].Add(sums[c.Denom], amt)
+	return nil
+}
+
+func sortedCoins(sums map[string]*big.Int) []coin {
+	coins := make([]coin, 0, len(sums))
+	for denom, amt := range sums {
+		coins = append(coins, coin{Denom: denom, Amount: amt.String()})
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i].Denom < coins[j].Denom })
+	return coins
+}
+
+func printText(w io.Writer, s *summary) error {
+	fmt.Fprintf(w, "Chain ID:       %s\n", s.ChainID)
+	fmt.Fprintf(w, "Genesis time:   %s\n", s.GenesisTime)
+	fmt.Fprintf(w, "Initial height: %s\n", s.InitialHeight)
+
+	fmt.Fprintf(w, "\nValidators (%d, total power %s)\n", len(s.Validators), s.TotalPower)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MONIKER\tOPERATOR\tSTATUS\tTOKENS\tPOWER\tRATE\tMAX RATE\tMAX CHANGE")
+	for _, v := range s.Validators {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			v.Moniker, v.OperatorAddress, v.Status, v.Tokens, v.Power,
+			v.Commission.Rate, v.Commission.MaxRate, v.Commission.MaxChangeRate)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nSupply")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DENOM\tSUPPLY\tSUM OF BALANCES")
+	for _, denom := range denoms(s.Supply, s.BalancesSum) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", denom, amountOf(s.Supply, denom), amountOf(s.BalancesSum, denom))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nAccounts: %d (%d with balances)\n", s.Accounts.Total, s.Accounts.Balances)
+	types := make([]string, 0, len(s.Accounts.ByType))
+	for t := range s.Accounts.ByType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		fmt.Fprintf(w, "  %-50s %d\n", t, s.Accounts.ByType[t])
+	}
+
+	fmt.Fprintln(w, "\nModule params")
+	modules := make([]string, 0, len(s.ModuleParams))
+	for m := range s.ModuleParams {
+		modules = append(modules, m)
+	}
+	sort.Strings(modules)
+	for _, m := range modules {
+		fmt.Fprintf(w, "  %s: %s\n", m, compact(s.ModuleParams[m]))
+	}
+
+	fmt.Fprintln(w, "\nInvariants")
+	for _, inv := range s.Invariants {
+		status := "ok"
+		if !inv.OK {
+			status = "BROKEN"
+		}
+		fmt.Fprintf(w, "  %-45s %s", inv.Name, status)
+		if inv.Note != "" {
+			fmt.Fprintf(w, " (%s)", inv.Note)
+		}
+		fmt.Fprintln(w)
+		if inv.OK {
+			continue
+		}
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "    DENOM\tEXPECTED\tACTUAL\tDIFF")
+		for _, b := range inv.Breakdown {
+			fmt.Fprintf(tw, "    %s\t%s\t%s\t%s\n", b.Denom, b.Expected, b.Actual, b.Diff)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	var vesting int
+	for _, n := range s.Vesting.Accounts {
+		vesting += n
+	}
+	fmt.Fprintf(w, "\nVesting accounts: %d\n", vesting)
+	for _, p := range s.Vesting.Problems {
+		fmt.Fprintf(w, "  %s\n", p)
+	}
+
+	if s.Warden != nil {
+		fmt.Fprintf(w, "\nWarden: %d keychains, %d spaces, %d keys, %d templates\n", s.Warden.Keychains, s.Warden.Spaces, s.Warden.Keys, s.Warden.Templates)
+		for _, p := range s.Warden.Problems {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+
+	if s.EVM != nil {
+		fmt.Fprintf(w, "\nEVM: chain ID %d, denom %s", s.EVM.ChainID, s.EVM.Denom)
+		if s.EVM.BaseFee != "" {
+			fmt.Fprintf(w, ", base fee %s", s.EVM.BaseFee)
+		}
+		fmt.Fprintln(w)
+		for _, p := range s.EVM.Problems {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+
+	fmt.Fprintln(w, "\nConsensus params")
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, s.ConsensusParams, "  ", "  "); err != nil {
+		fmt.Fprintf(w, "  %s\n", s.ConsensusParams)
+	} else {
+		fmt.Fprintf(w, "  %s\n", buf.String())
+	}
+
+	return nil
+}
+
+func denoms(lists ...[]coin) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, l := range lists {
+		for _, c := range l {
+			if !seen[c.Denom] {
+				seen[c.Denom] = true
+				out = append(out, c.Denom)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func amountOf(coins []coin, denom string) string {
+	for _, c := range coins {
+		if c.Denom == denom {
+			return c.Amount
+		}
+	}
+	return "-"
+}
+
+func compact(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
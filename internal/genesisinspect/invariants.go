@@ -0,0 +1,171 @@
+package genesisinspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// bondedPoolName is the name of the staking module account holding bonded
+// tokens.
+const bondedPoolName = "bonded_tokens_pool"
+
+// invariant is the outcome of one supply check, with a per-denom breakdown.
+type invariant struct {
+	Name      string         `json:"name"`
+	OK        bool           `json:"ok"`
+	Note      string         `json:"note,omitempty"`
+	Breakdown []denomBalance `json:"breakdown"`
+}
+
+type denomBalance struct {
+	Denom    string `json:"denom"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Diff     string `json:"diff"`
+}
+
+// compare fills the breakdown of inv from the expected and actual sums per
+// denom, marking inv as broken when any denom differs.
+func (inv *invariant) compare(expected, actual map[string]*big.Int) {
+	inv.OK = true
+	inv.Breakdown = []denomBalance{}
+
+	seen := map[string]bool{}
+	var denoms []string
+	for _, m := range []map[string]*big.Int{expected, actual} {
+		for d := range m {
+			if !seen[d] {
+				seen[d] = true
+				denoms = append(denoms, d)
+			}
+		}
+	}
+	sort.Strings(denoms)
+
+	for _, d := range denoms {
+		e, a := orZero(expected[d]), orZero(actual[d])
+		diff := new(big.Int).Sub(a, e)
+		if diff.Sign() != 0 {
+			inv.OK = false
+		}
+		inv.Breakdown = append(inv.Breakdown, denomBalance{Denom: d, Expected: e.String(), Actual: a.String(), Diff: diff.String()})
+	}
+}
+
+func orZero(i *big.Int) *big.Int {
+	if i == nil {
+		return new(big.Int)
+	}
+	return i
+}
+
+// checkInvariants checks that the bank supply equals the sum of all
+// balances, module accounts included. For an exported genesis, which has
+// validators in the staking state, it also checks that the balance of the
+// bonded pool equals the tokens of the bonded validators. An init genesis
+// has nothing bonded yet: gentxs are delegated at InitChain.
+func checkInvariants(appState map[string]json.RawMessage) ([]invariant, error) {
+	var invariants []invariant
+
+	var bank bankState
+	if raw, ok := appState["bank"]; ok {
+		if err := json.Unmarshal(raw, &bank); err != nil {
+			return nil, fmt.Errorf("decode bank state: %w", err)
+		}
+	}
+
+	balances := map[string]*big.Int{}
+	byAddress := map[string][]coin{}
+	for _, b := range bank.Balances {
+		byAddress[b.Address] = b.Coins
+		for _, c := range b.Coins {
+			if err := addCoin(balances, c); err != nil {
+				return nil, fmt.Errorf("balance of %s: %w", b.Address, err)
+			}
+		}
+	}
+
+	supply := invariant{Name: "supply equals sum of balances"}
+	if len(bank.Supply) == 0 {
+		// The bank module computes the supply from the balances when it is
+		// left empty.
+		supply.OK = true
+		supply.Note = "supply is empty and will be computed from the balances"
+		supply.Breakdown = []denomBalance{}
+	} else {
+		expected := map[string]*big.Int{}
+		for _, c := range bank.Supply {
+			if err := addCoin(expected, c); err != nil {
+				return nil, fmt.Errorf("supply: %w", err)
+			}
+		}
+		supply.compare(expected, balances)
+	}
+	invariants = append(invariants, supply)
+
+	var staking stakingState
+	if raw, ok := appState["staking"]; ok {
+		if err := json.Unmarshal(raw, &staking); err != nil {
+			return nil, fmt.Errorf("decode staking state: %w", err)
+		}
+	}
+	if len(staking.Validators) == 0 {
+		return invariants, nil
+	}
+
+	bonded := invariant{Name: "bonded pool equals bonded validator tokens"}
+	expected := map[string]*big.Int{}
+	for _, v := range staking.Validators {
+		if v.Status != "BOND_STATUS_BONDED" {
+			continue
+		}
+		if err := addCoin(expected, coin{Denom: staking.Params.BondDenom, Amount: v.Tokens}); err != nil {
+			return nil, fmt.Errorf("tokens of %s: %w", v.OperatorAddress, err)
+		}
+	}
+	addr, err := moduleAddress(appState, bondedPoolName)
+	if err != nil {
+		return nil, err
+	}
+	actual := map[string]*big.Int{}
+	for _, c := range byAddress[addr] {
+		if err := addCoin(actual, c); err != nil {
+			return nil, fmt.Errorf("balance of %s: %w", addr, err)
+		}
+	}
+	bonded.compare(expected, actual)
+	if addr == "" {
+		bonded.Note = "no " + bondedPoolName + " module account in auth state"
+	}
+	invariants = append(invariants, bonded)
+
+	return invariants, nil
+}
+
+// moduleAddress returns the address of the module account called name, or
+// "" when there is none.
+func moduleAddress(appState map[string]json.RawMessage, name string) (string, error) {
+	raw, ok := appState["auth"]
+	if !ok {
+		return "", nil
+	}
+	var auth struct {
+		Accounts []struct {
+			Name        string `json:"name"`
+			BaseAccount struct {
+				Address string `json:"address"`
+			} `json:"base_account"`
+		} `json:"accounts"`
+	}
+	if err := json.Unmarshal(raw, &auth); err != nil {
+		return "", fmt.Errorf("decode auth state: %w", err)
+	}
+	for _, a := range auth.Accounts {
+		if a.Name == name {
+			return a.BaseAccount.Address, nil
+		}
+	}
+	return "", nil
+}
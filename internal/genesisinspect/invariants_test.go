@@ -0,0 +1,113 @@
+package genesisinspect
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	const auth = `{"accounts": [{"@type": "/cosmos.auth.v1beta1.ModuleAccount", "name": "bonded_tokens_pool", "base_account": {"address": "pool"}}]}`
+
+	tests := []struct {
+		name     string
+		appState map[string]string
+		ok       []bool
+	}{
+		{
+			name: "supply matches balances",
+			appState: map[string]string{
+				"bank": `{"balances": [{"address": "a", "coins": [{"denom": "uward", "amount": "60"}]}, {"address": "b", "coins": [{"denom": "uward", "amount": "40"}]}],
+					"supply": [{"denom": "uward", "amount": "100"}]}`,
+			},
+			ok: []bool{true},
+		},
+		{
+			name: "supply differs from balances",
+			appState: map[string]string{
+				"bank": `{"balances": [{"address": "a", "coins": [{"denom": "uward", "amount": "60"}]}], "supply": [{"denom": "uward", "amount": "100"}]}`,
+			},
+			ok: []bool{false},
+		},
+		{
+			name: "empty supply is computed",
+			appState: map[string]string{
+				"bank": `{"balances": [{"address": "a", "coins": [{"denom": "uward", "amount": "60"}]}]}`,
+			},
+			ok: []bool{true},
+		},
+		{
+			name: "init genesis has nothing bonded",
+			appState: map[string]string{
+				"bank":    `{"balances": [{"address": "a", "coins": [{"denom": "uward", "amount": "60"}]}]}`,
+				"staking": `{"params": {"bond_denom": "uward"}, "validators": []}`,
+				"genutil": `{"gen_txs": [{"body": {"messages": [{"@type": "/cosmos.staking.v1beta1.MsgCreateValidator", "value": {"denom": "uward", "amount": "50"}}]}}]}`,
+			},
+			ok: []bool{true},
+		},
+		{
+			name: "bonded pool matches bonded validators",
+			appState: map[string]string{
+				"auth": auth,
+				"bank": `{"balances": [{"address": "pool", "coins": [{"denom": "uward", "amount": "100"}]}]}`,
+				"staking": `{"params": {"bond_denom": "uward"}, "validators": [
+					{"operator_address": "v1", "status": "BOND_STATUS_BONDED", "tokens": "100"},
+					{"operator_address": "v2", "status": "BOND_STATUS_UNBONDED", "tokens": "50"}]}`,
+			},
+			ok: []bool{true, true},
+		},
+		{
+			name: "bonded pool short",
+			appState: map[string]string{
+				"auth":    auth,
+				"bank":    `{"balances": [{"address": "pool", "coins": [{"denom": "uward", "amount": "90"}]}]}`,
+				"staking": `{"params": {"bond_denom": "uward"}, "validators": [{"operator_address": "v1", "status": "BOND_STATUS_BONDED", "tokens": "100"}]}`,
+			},
+			ok: []bool{true, false},
+		},
+		{
+			name: "bonded pool in another denom",
+			appState: map[string]string{
+				"auth":    auth,
+				"bank":    `{"balances": [{"address": "pool", "coins": [{"denom": "award", "amount": "100"}]}]}`,
+				"staking": `{"params": {"bond_denom": "uward"}, "validators": [{"operator_address": "v1", "status": "BOND_STATUS_BONDED", "tokens": "100"}]}`,
+			},
+			ok: []bool{true, false},
+		},
+		{
+			name: "no bonded pool account",
+			appState: map[string]string{
+				"staking": `{"params": {"bond_denom": "uward"}, "validators": [{"operator_address": "v1", "status": "BOND_STATUS_BONDED", "tokens": "100"}]}`,
+			},
+			ok: []bool{true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appState := map[string]json.RawMessage{}
+			for module, state := range tt.appState {
+				appState[module] = json.RawMessage(state)
+			}
+			invariants, err := checkInvariants(appState)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(invariants) != len(tt.ok) {
+				t.Fatalf("got %d invariants, want %d", len(invariants), len(tt.ok))
+			}
+			for i, inv := range invariants {
+				if inv.OK != tt.ok[i] {
+					t.Errorf("%s: ok = %t, want %t (breakdown %+v)", inv.Name, inv.OK, tt.ok[i], inv.Breakdown)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckInvariantsInvalidAmount(t *testing.T) {
+	appState := map[string]json.RawMessage{
+		"bank": json.RawMessage(`{"balances": [{"address": "a", "coins": [{"denom": "uward", "amount": "1.5"}]}]}`),
+	}
+	if _, err := checkInvariants(appState); err == nil {
+		t.Error("checkInvariants accepted a non-integer amount")
+	}
+}
@@ -0,0 +1,182 @@
+package genesisinspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vestingReport summarizes the vesting accounts of a genesis.
+type vestingReport struct {
+	Accounts map[string]int `json:"accounts"`
+	Problems []string       `json:"problems"`
+}
+
+type vestingAccount struct {
+	Type               string `json:"@type"`
+	BaseVestingAccount struct {
+		BaseAccount struct {
+			Address string `json:"address"`
+		} `json:"base_account"`
+		OriginalVesting  []coin `json:"original_vesting"`
+		DelegatedVesting []coin `json:"delegated_vesting"`
+		EndTime          string `json:"end_time"`
+	} `json:"base_vesting_account"`
+	StartTime      string `json:"start_time"`
+	VestingPeriods []struct {
+		Length string `json:"length"`
+		Amount []coin `json:"amount"`
+	} `json:"vesting_periods"`
+}
+
+// checkVesting validates the schedules of the continuous, delayed and
+// periodic vesting accounts: end after start, no schedule starting before
+// genesisTime, periods adding up to the original vesting amount and end
+// time, and the original vesting covered by the account's balance plus its
+// delegated vesting, which left the balance when it was delegated.
+func checkVesting(appState map[string]json.RawMessage, genesisTime string) (*vestingReport, error) {
+	r := &vestingReport{Accounts: map[string]int{}, Problems: []string{}}
+
+	var auth struct {
+		Accounts []json.RawMessage `json:"accounts"`
+	}
+	if raw, ok := appState["auth"]; ok {
+		if err := json.Unmarshal(raw, &auth); err != nil {
+			return nil, fmt.Errorf("decode auth state: %w", err)
+		}
+	}
+
+	var bank bankState
+	if raw, ok := appState["bank"]; ok {
+		if err := json.Unmarshal(raw, &bank); err != nil {
+			return nil, fmt.Errorf("decode bank state: %w", err)
+		}
+	}
+	balances := map[string][]coin{}
+	for _, b := range bank.Balances {
+		balances[b.Address] = b.Coins
+	}
+
+	var genesisUnix int64
+	if t, err := time.Parse(time.RFC3339Nano, genesisTime); err == nil {
+		genesisUnix = t.Unix()
+	}
+
+	for _, raw := range auth.Accounts {
+		var acc vestingAccount
+		if err := json.Unmarshal(raw, &acc); err != nil {
+			return nil, fmt.Errorf("decode account: %w", err)
+		}
+		kind := strings.TrimSuffix(acc.Type[strings.LastIndex(acc.Type, ".")+1:], "VestingAccount")
+		switch kind {
+		case "Continuous", "Delayed", "Periodic":
+		default:
+			continue
+		}
+		r.Accounts[acc.Type]++
+
+		addr := acc.BaseVestingAccount.BaseAccount.Address
+		report := func(format string, args ...any) {
+			r.Problems = append(r.Problems, fmt.Sprintf("%s (%s): %s", addr, kind, fmt.Sprintf(format, args...)))
+		}
+
+		original := map[string]*big.Int{}
+		for _, c := range acc.BaseVestingAccount.OriginalVesting {
+			if err := addCoin(original, c); err != nil {
+				report("original_vesting: %v", err)
+			}
+		}
+		if len(original) == 0 {
+			report("original_vesting is empty")
+		}
+
+		end, err := strconv.ParseInt(acc.BaseVestingAccount.EndTime, 10, 64)
+		if err != nil || end <= 0 {
+			report("invalid end_time %q", acc.BaseVestingAccount.EndTime)
+			continue
+		}
+		if kind != "Delayed" {
+			start, err := strconv.ParseInt(acc.StartTime, 10, 64)
+			if err != nil {
+				report("invalid start_time %q", acc.StartTime)
+				continue
+			}
+			if start >= end {
+				report("start_time %s is not before end_time %s", unixTime(start), unixTime(end))
+			}
+			if genesisUnix > 0 && start < genesisUnix {
+				report("schedule starts at %s, before genesis time %s", unixTime(start), genesisTime)
+			}
+
+			if kind == "Periodic" {
+				periods := map[string]*big.Int{}
+				length := start
+				for i, p := range acc.VestingPeriods {
+					l, err := strconv.ParseInt(p.Length, 10, 64)
+					if err != nil || l <= 0 {
+						report("period %d: invalid length %q", i, p.Length)
+					}
+					length += l
+					for _, c := range p.Amount {
+						if err := addCoin(periods, c); err != nil {
+							report("period %d: %v", i, err)
+						}
+					}
+				}
+				if length != end {
+					report("periods end at %s, end_time is %s", unixTime(length), unixTime(end))
+				}
+				if d := diffCoins(periods, original); d != "" {
+					report("periods add up to %s, original_vesting is %s", d, coinsString(original))
+				}
+			}
+		}
+
+		have := map[string]*big.Int{}
+		for _, c := range balances[addr] {
+			_ = addCoin(have, c)
+		}
+		for _, c := range acc.BaseVestingAccount.DelegatedVesting {
+			if err := addCoin(have, c); err != nil {
+				report("delegated_vesting: %v", err)
+			}
+		}
+		for _, c := range sortedCoins(original) {
+			if amt := original[c.Denom]; have[c.Denom] == nil || have[c.Denom].Cmp(amt) < 0 {
+				report("original_vesting of %s%s exceeds the balance plus delegated vesting of %s%s", amt, c.Denom, orZero(have[c.Denom]), c.Denom)
+			}
+		}
+	}
+	return r, nil
+}
+
+// diffCoins returns a, formatted, when it differs from b, or "".
+func diffCoins(a, b map[string]*big.Int) string {
+	if len(a) != len(b) {
+		return coinsString(a)
+	}
+	for d, amt := range a {
+		if b[d] == nil || b[d].Cmp(amt) != 0 {
+			return coinsString(a)
+		}
+	}
+	return ""
+}
+
+func coinsString(m map[string]*big.Int) string {
+	var parts []string
+	for _, c := range sortedCoins(m) {
+		parts = append(parts, c.Amount+c.Denom)
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ",")
+}
+
+func unixTime(sec int64) string {
+	return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,107 @@
+package genesisinspect
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckVesting(t *testing.T) {
+	const genesisTime = "2024-01-01T00:00:00Z" // 1704067200
+
+	tests := []struct {
+		name     string
+		account  string
+		balance  string
+		problems []string
+	}{
+		{
+			name: "continuous ok",
+			account: `{"@type": "/cosmos.vesting.v1beta1.ContinuousVestingAccount",
+				"base_vesting_account": {"base_account": {"address": "a"}, "original_vesting": [{"denom": "uward", "amount": "100"}], "end_time": "1704153600"},
+				"start_time": "1704067200"}`,
+			balance: `[{"denom": "uward", "amount": "100"}]`,
+		},
+		{
+			name: "delayed needs no start time",
+			account: `{"@type": "/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				"base_vesting_account": {"base_account": {"address": "a"}, "original_vesting": [{"denom": "uward", "amount": "100"}], "end_time": "1704153600"}}`,
+			balance: `[{"denom": "uward", "amount": "100"}]`,
+		},
+		{
+			name: "delegated vesting counts towards the balance",
+			account: `{"@type": "/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				"base_vesting_account": {"base_account": {"address": "a"}, "original_vesting": [{"denom": "uward", "amount": "100"}],
+					"delegated_vesting": [{"denom": "uward", "amount": "60"}], "end_time": "1704153600"}}`,
+			balance: `[{"denom": "uward", "amount": "40"}]`,
+		},
+		{
+			name: "balance too low",
+			account: `{"@type": "/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				"base_vesting_account": {"base_account": {"address": "a"}, "original_vesting": [{"denom": "uward", "amount": "100"}],
+					"delegated_vesting": [{"denom": "uward", "amount": "10"}], "end_time": "1704153600"}}`,
+			balance:  `[{"denom": "uward", "amount": "40"}]`,
+			problems: []string{"original_vesting of 100uward exceeds the balance plus delegated vesting of 50uward"},
+		},
+		{
+			name: "every denom is checked, in order",
+			account: `{"@type": "/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				"base_vesting_account": {"base_account": {"address": "a"},
+					"original_vesting": [{"denom": "uward", "amount": "100"}, {"denom": "award", "amount": "100"}, {"denom": "ufoo", "amount": "100"}], "end_time": "1704153600"}}`,
+			balance: `[{"denom": "ufoo", "amount": "100"}]`,
+			problems: []string{
+				"original_vesting of 100award exceeds the balance plus delegated vesting of 0award",
+				"original_vesting of 100uward exceeds the balance plus delegated vesting of 0uward",
+			},
+		},
+		{
+			name: "start after end and before genesis",
+			account: `{"@type": "/cosmos.vesting.v1beta1.ContinuousVestingAccount",
+				"base_vesting_account": {"base_account": {"address": "a"}, "original_vesting": [{"denom": "uward", "amount": "100"}], "end_time": "1700000000"},
+				"start_time": "1700000001"}`,
+			balance:  `[{"denom": "uward", "amount": "100"}]`,
+			problems: []string{"is not before end_time", "before genesis time"},
+		},
+		{
+			name: "periods do not add up",
+			account: `{"@type": "/cosmos.vesting.v1beta1.PeriodicVestingAccount",
+				"base_vesting_account": {"base_account": {"address": "a"}, "original_vesting": [{"denom": "uward", "amount": "100"}], "end_time": "1704067300"},
+				"start_time": "1704067200",
+				"vesting_periods": [{"length": "50", "amount": [{"denom": "uward", "amount": "50"}]}, {"length": "40", "amount": [{"denom": "uward", "amount": "40"}]}]}`,
+			balance:  `[{"denom": "uward", "amount": "100"}]`,
+			problems: []string{"periods end at", "periods add up to 90uward"},
+		},
+		{
+			name: "invalid end time",
+			account: `{"@type": "/cosmos.vesting.v1beta1.DelayedVestingAccount",
+				"base_vesting_account": {"base_account": {"address": "a"}, "original_vesting": [], "end_time": "soon"}}`,
+			balance:  `[]`,
+			problems: []string{"original_vesting is empty", `invalid end_time "soon"`},
+		},
+		{
+			name:    "base accounts are ignored",
+			account: `{"@type": "/cosmos.auth.v1beta1.BaseAccount", "address": "a"}`,
+			balance: `[]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appState := map[string]json.RawMessage{
+				"auth": json.RawMessage(`{"accounts": [` + tt.account + `]}`),
+				"bank": json.RawMessage(`{"balances": [{"address": "a", "coins": ` + tt.balance + `}]}`),
+			}
+			r, err := checkVesting(appState, genesisTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(r.Problems) != len(tt.problems) {
+				t.Fatalf("problems = %q, want %d matching %q", r.Problems, len(tt.problems), tt.problems)
+			}
+			for i, want := range tt.problems {
+				if !strings.Contains(r.Problems[i], want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, r.Problems[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package genesisinspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/warden-protocol/networks/internal/bech32"
+)
+
+// wardenReport summarizes the x/warden state of a genesis, with the
+// templates of x/act (or, on older chains, the intents of x/intent) that
+// spaces refer to.
+type wardenReport struct {
+	Keychains int      `json:"keychains"`
+	Spaces    int      `json:"spaces"`
+	Keys      int      `json:"keys"`
+	Templates int      `json:"templates"`
+	Problems  []string `json:"problems"`
+}
+
+type wardenState struct {
+	Keychains []struct {
+		ID      json.RawMessage `json:"id"`
+		Creator string          `json:"creator"`
+		Name    string          `json:"name"`
+		Admins  []string        `json:"admins"`
+		Writers []string        `json:"writers"`
+		Fees    *struct {
+			KeyReq []coin `json:"key_req"`
+			SigReq []coin `json:"sig_req"`
+		} `json:"fees"`
+	} `json:"keychains"`
+	Spaces []struct {
+		ID      json.RawMessage `json:"id"`
+		Creator string          `json:"creator"`
+		Owners  []string        `json:"owners"`
+		// Template references; x/act chains use the first four, x/intent
+		// chains the last two.
+		ApproveAdminTemplateID json.RawMessage `json:"approve_admin_template_id"`
+		RejectAdminTemplateID  json.RawMessage `json:"reject_admin_template_id"`
+		ApproveSignTemplateID  json.RawMessage `json:"approve_sign_template_id"`
+		RejectSignTemplateID   json.RawMessage `json:"reject_sign_template_id"`
+		AdminIntentID          json.RawMessage `json:"admin_intent_id"`
+		SignIntentID           json.RawMessage `json:"sign_intent_id"`
+	} `json:"spaces"`
+	Keys []struct {
+		ID         json.RawMessage `json:"id"`
+		SpaceID    json.RawMessage `json:"space_id"`
+		KeychainID json.RawMessage `json:"keychain_id"`
+		PublicKey  string          `json:"public_key"`
+	} `json:"keys"`
+}
+
+type template struct {
+	ID         json.RawMessage `json:"id"`
+	Name       string          `json:"name"`
+	Definition string          `json:"definition"`
+}
+
+// checkWarden validates the x/warden state: keychains with an ID, a name,
+// admins and well-formed addresses; spaces owned by accounts that exist in
+// auth; keys belonging to existing spaces and keychains; and the templates
+// spaces refer to existing, with definitions that are lexically well-formed
+// (balanced brackets and closed strings). The full expression grammar is
+// wardend's, so a definition passing here may still fail to parse there. It
+// returns nil for genesis files without a warden module.
+func checkWarden(appState map[string]json.RawMessage) (*wardenReport, error) {
+	raw, ok := appState["warden"]
+	if !ok {
+		return nil, nil
+	}
+	var warden wardenState
+	if err := json.Unmarshal(raw, &warden); err != nil {
+		return nil, fmt.Errorf("decode warden state: %w", err)
+	}
+
+	r := &wardenReport{Problems: []string{}}
+	report := func(format string, args ...any) {
+		r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+	}
+
+	accounts, hrp, err := authAddresses(appState)
+	if err != nil {
+		return nil, err
+	}
+	checkAddress := func(what, addr string) {
+		got, _, err := bech32.Decode(addr)
+		switch {
+		case err != nil:
+			report("%s %q is not a bech32 address: %v", what, addr, err)
+		case hrp != "" && got != hrp:
+			report("%s %s has prefix %q, accounts use %q", what, addr, got, hrp)
+		}
+	}
+
+	keychains := map[string]bool{}
+	for i, k := range warden.Keychains {
+		id := rawID(k.ID)
+		what := fmt.Sprintf("keychain %s", id)
+		switch {
+		case id == "" || id == "0":
+			report("keychains[%d] has no id", i)
+		case keychains[id]:
+			report("%s is defined twice", what)
+		}
+		keychains[id] = true
+		if strings.TrimSpace(k.Name) == "" {
+			report("%s has no name", what)
+		}
+		checkAddress(what+" creator", k.Creator)
+		if len(k.Admins) == 0 {
+			report("%s has no admins", what)
+		}
+		for _, a := range k.Admins {
+			checkAddress(what+" admin", a)
+		}
+		for _, a := range k.Writers {
+			checkAddress(what+" writer", a)
+		}
+		if k.Fees != nil {
+			for _, c := range append(append([]coin{}, k.Fees.KeyReq...), k.Fees.SigReq...) {
+				if err := addCoin(map[string]*big.Int{}, c); err != nil {
+					report("%s fees: %v", what, err)
+				}
+			}
+		}
+	}
+	r.Keychains = len(warden.Keychains)
+
+	templates, err := collectTemplates(appState)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for i, t := range templates {
+		id := rawID(t.ID)
+		what := fmt.Sprintf("template %s", id)
+		switch {
+		case id == "" || id == "0":
+			report("templates[%d] has no id", i)
+		case ids[id]:
+			report("%s is defined twice", what)
+		}
+		ids[id] = true
+		if err := checkDefinition(t.Definition); err != nil {
+			report("%s (%s): definition %q: %v", what, t.Name, t.Definition, err)
+		}
+	}
+	r.Templates = len(templates)
+
+	spaces := map[string]bool{}
+	for i, s := range warden.Spaces {
+		id := rawID(s.ID)
+		what := fmt.Sprintf("space %s", id)
+		switch {
+		case id == "" || id == "0":
+			report("spaces[%d] has no id", i)
+		case spaces[id]:
+			report("%s is defined twice", what)
+		}
+		spaces[id] = true
+		checkAddress(what+" creator", s.Creator)
+		if len(s.Owners) == 0 {
+			report("%s has no owners", what)
+		}
+		for _, o := range s.Owners {
+			checkAddress(what+" owner", o)
+			if accounts != nil && !accounts[o] {
+				report("%s owner %s has no account in auth", what, o)
+			}
+		}
+		for _, ref := range []json.RawMessage{
+			s.ApproveAdminTemplateID, s.RejectAdminTemplateID,
+			s.ApproveSignTemplateID, s.RejectSignTemplateID,
+			s.AdminIntentID, s.SignIntentID,
+		} {
+			// Zero selects the module's default template.
+			if t := rawID(ref); t != "" && t != "0" && !ids[t] {
+				report("%s refers to template %s, which does not exist", what, t)
+			}
+		}
+	}
+	r.Spaces = len(warden.Spaces)
+
+	keys := map[string]bool{}
+	for i, k := range warden.Keys {
+		id := rawID(k.ID)
+		what := fmt.Sprintf("key %s", id)
+		switch {
+		case id == "" || id == "0":
+			report("keys[%d] has no id", i)
+		case keys[id]:
+			report("%s is defined twice", what)
+		}
+		keys[id] = true
+		if s := rawID(k.SpaceID); !spaces[s] {
+			report("%s belongs to space %s, which does not exist", what, s)
+		}
+		if kc := rawID(k.KeychainID); !keychains[kc] {
+			report("%s belongs to keychain %s, which does not exist", what, kc)
+		}
+		if k.PublicKey == "" {
+			report("%s has no public key", what)
+		}
+	}
+	r.Keys = len(warden.Keys)
+
+	return r, nil
+}
+
+// authAddresses returns the addresses of the auth accounts and their bech32
+// prefix, or a nil set when the genesis has no auth module.
+func authAddresses(appState map[string]json.RawMessage) (map[string]bool, string, error) {
+	raw, ok := appState["auth"]
+	if !ok {
+		return nil, "", nil
+	}
+	var auth struct {
+		Accounts []struct {
+			Address     string `json:"address"`
+			BaseAccount *struct {
+				Address string `json:"address"`
+			} `json:"base_account"`
+			BaseVestingAccount *struct {
+				BaseAccount struct {
+					Address string `json:"address"`
+				} `json:"base_account"`
+			} `json:"base_vesting_account"`
+		} `json:"accounts"`
+	}
+	if err := json.Unmarshal(raw, &auth); err != nil {
+		return nil, "", fmt.Errorf("decode auth state: %w", err)
+	}
+
+	addrs := map[string]bool{}
+	hrp := ""
+	for _, a := range auth.Accounts {
+		addr := a.Address
+		switch {
+		case a.BaseAccount != nil:
+			addr = a.BaseAccount.Address
+		case a.BaseVestingAccount != nil:
+			addr = a.BaseVestingAccount.BaseAccount.Address
+		}
+		if addr == "" {
+			continue
+		}
+		addrs[addr] = true
+		if hrp == "" {
+			hrp, _, _ = bech32.Decode(addr)
+		}
+	}
+	return addrs, hrp, nil
+}
+
+// collectTemplates returns the templates of x/act, or the intents of
+// x/intent on chains that predate it.
+func collectTemplates(appState map[string]json.RawMessage) ([]template, error) {
+	if raw, ok := appState["act"]; ok {
+		var act struct {
+			Templates []template `json:"templates"`
+		}
+		if err := json.Unmarshal(raw, &act); err != nil {
+			return nil, fmt.Errorf("decode act state: %w", err)
+		}
+		return act.Templates, nil
+	}
+	if raw, ok := appState["intent"]; ok {
+		var intent struct {
+			Intents []template `json:"intents"`
+		}
+		if err := json.Unmarshal(raw, &intent); err != nil {
+			return nil, fmt.Errorf("decode intent state: %w", err)
+		}
+		return intent.Intents, nil
+	}
+	return nil, nil
+}
+
+// checkDefinition checks that a template definition is non-empty, closes
+// its string literals and balances its brackets.
+func checkDefinition(def string) error {
+	if strings.TrimSpace(def) == "" {
+		return fmt.Errorf("empty")
+	}
+	var open []rune
+	for i := 0; i < len(def); i++ {
+		switch c := rune(def[i]); c {
+		case '"', '\'':
+			end := strings.IndexRune(def[i+1:], c)
+			if end < 0 {
+				return fmt.Errorf("unterminated string at offset %d", i)
+			}
+			i += end + 1
+		case '(', '[', '{':
+			open = append(open, c)
+		case ')', ']', '}':
+			want := map[rune]rune{')': '(', ']': '[', '}': '{'}[c]
+			if len(open) == 0 || open[len(open)-1] != want {
+				return fmt.Errorf("unbalanced %q at offset %d", c, i)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("unclosed %q", open[len(open)-1])
+	}
+	return nil
+}
+
+// rawID returns a uint64 encoded as a JSON number or string.
+func rawID(raw json.RawMessage) string {
+	if string(raw) == "null" {
+		return ""
+	}
+	return strings.Trim(string(raw), `"`)
+}
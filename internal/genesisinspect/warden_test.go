@@ -0,0 +1,122 @@
+package genesisinspect
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckWarden(t *testing.T) {
+	data, err := os.ReadFile("../../testnets/alfama/genesis.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc genesisDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		acc      = "warden1vw3xl9jjp9xy6yek0ap3yzc9f9hqvtam4754mf" // in the alfama auth state
+		stranger = "warden1qct98ulz4vmxwn8x0hwy5p709mt3nd0mjxptyz" // only in buenavista
+	)
+	keychain := `{"id":"1","creator":"` + acc + `","name":"kc","admins":["` + acc + `"],"fees":{"key_req":[{"denom":"uward","amount":"5"}]}}`
+	space := `{"id":"1","creator":"` + acc + `","owners":["` + acc + `"],"approve_sign_template_id":"1"}`
+	key := `{"id":"1","space_id":"1","keychain_id":"1","public_key":"AQID"}`
+	act := `{"templates":[{"id":"1","name":"any","definition":"any(1, [` + acc + `])"}]}`
+	warden := func(keychains, spaces, keys string) string {
+		return `{"keychains":[` + keychains + `],"spaces":[` + spaces + `],"keys":[` + keys + `]}`
+	}
+
+	tests := []struct {
+		name     string
+		warden   string            // replaces app_state.warden unless empty
+		modules  map[string]string // other app_state modules to replace
+		problems []string          // substrings of the expected problems, in order
+	}{
+		{name: "fixture"},
+		{
+			name:    "valid state",
+			warden:  warden(keychain, space, key),
+			modules: map[string]string{"act": act},
+		},
+		{
+			name:    "intents of older chains",
+			warden:  warden(keychain, strings.Replace(space, "approve_sign_template_id", "sign_intent_id", 1), ""),
+			modules: map[string]string{"intent": `{"intents":[{"id":"1","definition":"` + acc + `"}]}`},
+		},
+		{
+			name:     "keychain without name or admins",
+			warden:   warden(`{"id":"2","creator":"`+acc+`"}`, "", ""),
+			problems: []string{"keychain 2 has no name", "keychain 2 has no admins"},
+		},
+		{
+			name:     "duplicate keychain",
+			warden:   warden(keychain+","+keychain, "", ""),
+			problems: []string{"keychain 1 is defined twice"},
+		},
+		{
+			name:     "bad admin address",
+			warden:   warden(strings.Replace(keychain, `"admins":["`+acc, `"admins":["`+acc[:len(acc)-1]+"q", 1), "", ""),
+			problems: []string{`keychain 1 admin "` + acc[:len(acc)-1] + `q" is not a bech32 address: invalid checksum`},
+		},
+		{
+			name:     "bad keychain fee",
+			warden:   warden(strings.Replace(keychain, `"amount":"5"`, `"amount":"five"`, 1), "", ""),
+			problems: []string{"keychain 1 fees: invalid amount"},
+		},
+		{
+			name:     "owner without account",
+			warden:   warden("", strings.Replace(space, `"owners":["`+acc, `"owners":["`+stranger, 1), ""),
+			problems: []string{"space 1 owner " + stranger + " has no account in auth", "template 1, which does not exist"},
+		},
+		{
+			name:     "space without owners",
+			warden:   warden("", `{"id":"3","creator":"`+acc+`","owners":[]}`, ""),
+			problems: []string{"space 3 has no owners"},
+		},
+		{
+			name:     "missing template",
+			warden:   warden("", space, ""),
+			problems: []string{"space 1 refers to template 1, which does not exist"},
+		},
+		{
+			name:     "key of unknown space and keychain",
+			warden:   warden("", "", `{"id":"4","space_id":"9","keychain_id":"8"}`),
+			problems: []string{"key 4 belongs to space 9", "key 4 belongs to keychain 8", "key 4 has no public key"},
+		},
+		{
+			name:     "malformed template",
+			modules:  map[string]string{"act": `{"templates":[{"id":"1","name":"bad","definition":"any(1, [a]"},{"id":"2","definition":"'open"}]}`},
+			problems: []string{`template 1 (bad): definition "any(1, [a]": unclosed '('`, "template 2 (): definition \"'open\": unterminated string"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appState := map[string]json.RawMessage{}
+			for m, raw := range doc.AppState {
+				appState[m] = raw
+			}
+			if tt.warden != "" {
+				appState["warden"] = json.RawMessage(tt.warden)
+			}
+			for m, raw := range tt.modules {
+				appState[m] = json.RawMessage(raw)
+			}
+
+			r, err := checkWarden(appState)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(r.Problems) != len(tt.problems) {
+				t.Fatalf("problems = %q, want %d containing %q", r.Problems, len(tt.problems), tt.problems)
+			}
+			for i, p := range tt.problems {
+				if !strings.Contains(r.Problems[i], p) {
+					t.Errorf("problems[%d] = %q, want it to contain %q", i, r.Problems[i], p)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package gentxlint
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/warden-protocol/networks/internal/bech32"
+)
+
+// delegator returns the account self-delegating in msg: its
+// delegator_address or, as SDK v0.50 gentxs leave that empty, the account
+// of its validator operator address.
+func delegator(msg *message) (string, error) {
+	if msg.DelegatorAddress != "" {
+		return msg.DelegatorAddress, nil
+	}
+	hrp, data, err := bech32.Decode(msg.ValidatorAddress)
+	if err != nil {
+		return "", fmt.Errorf("validator address %q: %v", msg.ValidatorAddress, err)
+	}
+	prefix, ok := strings.CutSuffix(hrp, "valoper")
+	if !ok {
+		return "", fmt.Errorf("validator address %q: prefix %s is not a valoper prefix", msg.ValidatorAddress, hrp)
+	}
+	return bech32.Encode(prefix, data)
+}
+
+// checkFunds returns what is wrong with the genesis balances funding tx:
+// the delegator needs the self-delegation, and the fee payer (the granter,
+// the payer or else the delegator) the fee, in every denom. collect-gentxs
+// only fails on these with a generic insufficient funds error.
+func checkFunds(tx *gentx, balances map[string][]coin) []string {
+	msg := &tx.Body.Messages[0]
+	addr, err := delegator(msg)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	feePayer := addr
+	for _, a := range []string{tx.AuthInfo.Fee.Granter, tx.AuthInfo.Fee.Payer} {
+		if a != "" {
+			feePayer = a
+			break
+		}
+	}
+
+	needs := map[string]map[string]*big.Int{}
+	add := func(addr string, c coin) error {
+		amount, ok := new(big.Int).SetString(c.Amount, 10)
+		if !ok || amount.Sign() < 0 {
+			return fmt.Errorf("amount %q of %s is not a non-negative integer", c.Amount, c.Denom)
+		}
+		if needs[addr] == nil {
+			needs[addr] = map[string]*big.Int{}
+		}
+		if needs[addr][c.Denom] == nil {
+			needs[addr][c.Denom] = new(big.Int)
+		}
+		needs[addr][c.Denom].Add(needs[addr][c.Denom], amount)
+		return nil
+	}
+	if err := add(addr, msg.Value); err != nil {
+		return []string{"self-delegation " + err.Error()}
+	}
+	for _, c := range tx.AuthInfo.Fee.Amount {
+		if err := add(feePayer, c); err != nil {
+			return []string{"fee " + err.Error()}
+		}
+	}
+
+	var problems []string
+	for _, a := range sortedKeys(needs) {
+		what := "self-delegation and fee"
+		switch {
+		case a != addr:
+			what = "fee"
+		case a != feePayer || len(tx.AuthInfo.Fee.Amount) == 0:
+			what = "self-delegation"
+		}
+		have := map[string]*big.Int{}
+		for _, c := range balances[a] {
+			if n, ok := new(big.Int).SetString(c.Amount, 10); ok {
+				have[c.Denom] = n
+			}
+		}
+		for _, denom := range sortedKeys(needs[a]) {
+			need := needs[a][denom]
+			got := have[denom]
+			if got == nil {
+				got = new(big.Int)
+			}
+			if got.Cmp(need) < 0 {
+				problems = append(problems, fmt.Sprintf("account %s has %s%s in the genesis, needs %s%s for %s", a, got, denom, need, denom, what))
+			}
+		}
+	}
+	return problems
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,58 @@
+package gentxlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckFunds(t *testing.T) {
+	const (
+		valoper = "wardenvaloper1vw3xl9jjp9xy6yek0ap3yzc9f9hqvtamy9ks5c"
+		account = "warden1vw3xl9jjp9xy6yek0ap3yzc9f9hqvtam4754mf"
+		other   = "warden152lph6t6aref2e7y0up0yv2y7wjz7nmj4zv8he"
+	)
+	uward := func(amount string) []coin { return []coin{{Denom: "uward", Amount: amount}} }
+
+	tests := []struct {
+		name      string
+		delegator string // delegator_address, "" to derive it from the valoper
+		fee       []coin
+		payer     string
+		balances  map[string][]coin
+		want      []string
+	}{
+		{"funded", "", nil, "", map[string][]coin{account: uward("1000")}, nil},
+		{"funded with fee", "", uward("10"), "", map[string][]coin{account: uward("1010")}, nil},
+		{"explicit delegator", other, nil, "", map[string][]coin{other: uward("1000")}, nil},
+		{"no balance", "", nil, "", map[string][]coin{}, []string{"account " + account + " has 0uward in the genesis, needs 1000uward for self-delegation"}},
+		{"fee not covered", "", uward("10"), "", map[string][]coin{account: uward("1005")}, []string{"has 1005uward in the genesis, needs 1010uward for self-delegation and fee"}},
+		{"fee in another denom", "", []coin{{Denom: "uatom", Amount: "5"}}, "", map[string][]coin{account: uward("1000")}, []string{"has 0uatom in the genesis, needs 5uatom for self-delegation and fee"}},
+		{"fee payer", "", uward("10"), other, map[string][]coin{account: uward("1000")}, []string{"account " + other + " has 0uward in the genesis, needs 10uward for fee"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &gentx{}
+			tx.Body.Messages = []message{{DelegatorAddress: tt.delegator, ValidatorAddress: valoper, Value: coin{Denom: "uward", Amount: "1000"}}}
+			tx.AuthInfo.Fee.Amount, tx.AuthInfo.Fee.Payer = tt.fee, tt.payer
+			got := checkFunds(tx, tt.balances)
+			if len(got) != len(tt.want) {
+				t.Fatalf("checkFunds() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("checkFunds()[%d] = %q, want it to contain %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDelegator(t *testing.T) {
+	got, err := delegator(&message{ValidatorAddress: "wardenvaloper1vw3xl9jjp9xy6yek0ap3yzc9f9hqvtamy9ks5c"})
+	if want := "warden1vw3xl9jjp9xy6yek0ap3yzc9f9hqvtam4754mf"; got != want || err != nil {
+		t.Errorf("delegator() = %q, %v, want %q", got, err, want)
+	}
+	if _, err := delegator(&message{ValidatorAddress: "warden1vw3xl9jjp9xy6yek0ap3yzc9f9hqvtam4754mf"}); err == nil {
+		t.Error("delegator() accepted an account address as validator address")
+	}
+}
@@ -0,0 +1,65 @@
+package gentxlint
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var decimalRE = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// checkCommission returns what is wrong with the commission of a gentx:
+// every rate is a decimal between 0 and 1, the rate and max change rate do
+// not exceed the max rate, and the rate is at least min, when not nil.
+// wardend rejects some of these only at collect time, with errors that do
+// not name the file.
+func checkCommission(msg *message, min *big.Rat) []string {
+	var problems []string
+	c := msg.Commission
+	rates := map[string]*big.Rat{}
+	for _, f := range []struct{ name, value string }{
+		{"rate", c.Rate},
+		{"max_rate", c.MaxRate},
+		{"max_change_rate", c.MaxChangeRate},
+	} {
+		r, err := parseRate(f.value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("commission %s %q: %v", f.name, f.value, err))
+			continue
+		}
+		rates[f.name] = r
+	}
+	rate, maxRate, maxChange := rates["rate"], rates["max_rate"], rates["max_change_rate"]
+
+	if maxRate != nil {
+		if rate != nil && rate.Cmp(maxRate) > 0 {
+			problems = append(problems, fmt.Sprintf("commission rate %s is above max_rate %s", c.Rate, c.MaxRate))
+		}
+		if maxChange != nil && maxChange.Cmp(maxRate) > 0 {
+			problems = append(problems, fmt.Sprintf("commission max_change_rate %s is above max_rate %s", c.MaxChangeRate, c.MaxRate))
+		}
+	}
+	if min != nil && rate != nil && rate.Cmp(min) < 0 {
+		problems = append(problems, fmt.Sprintf("commission rate %s is below the network minimum of %s", c.Rate, formatRate(min)))
+	}
+	return problems
+}
+
+// formatRate formats r, which has at most 18 decimals like every sdk.Dec,
+// without trailing zeros.
+func formatRate(r *big.Rat) string {
+	return strings.TrimSuffix(strings.TrimRight(r.FloatString(18), "0"), ".")
+}
+
+// parseRate parses a commission rate: a decimal between 0 and 1.
+func parseRate(s string) (*big.Rat, error) {
+	if !decimalRE.MatchString(s) {
+		return nil, fmt.Errorf("not a decimal")
+	}
+	r, _ := new(big.Rat).SetString(s)
+	if r.Cmp(big.NewRat(1, 1)) > 0 {
+		return nil, fmt.Errorf("above 100%%")
+	}
+	return r, nil
+}
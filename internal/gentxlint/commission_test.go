@@ -0,0 +1,47 @@
+package gentxlint
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestCheckCommission(t *testing.T) {
+	tests := []struct {
+		name                     string
+		rate, maxRate, maxChange string
+		min                      string // "" for no minimum
+		want                     []string
+	}{
+		{"valid", "0.100000000000000000", "0.200000000000000000", "0.010000000000000000", "", nil},
+		{"rate equals max rate", "0.2", "0.2", "0.2", "", nil},
+		{"zero rate", "0", "1", "0.01", "", nil},
+		{"max rate above 100%", "0.1", "1.5", "0.01", "", []string{`commission max_rate "1.5": above 100%`}},
+		{"rate above max rate", "0.3", "0.2", "0.01", "", []string{"commission rate 0.3 is above max_rate 0.2"}},
+		{"change rate above max rate", "0.1", "0.2", "0.5", "", []string{"commission max_change_rate 0.5 is above max_rate 0.2"}},
+		{"below network minimum", "0.01", "0.2", "0.01", "0.05", []string{"commission rate 0.01 is below the network minimum of 0.05"}},
+		{"at network minimum", "0.05", "0.2", "0.01", "0.05", nil},
+		{"negative", "-0.1", "0.2", "0.01", "", []string{`commission rate "-0.1": not a decimal`}},
+		{"percentage", "10%", "0.2", "0.01", "", []string{`commission rate "10%": not a decimal`}},
+		{"missing", "0.1", "", "0.01", "", []string{`commission max_rate "": not a decimal`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &message{}
+			msg.Commission.Rate, msg.Commission.MaxRate, msg.Commission.MaxChangeRate = tt.rate, tt.maxRate, tt.maxChange
+			var min *big.Rat
+			if tt.min != "" {
+				min, _ = new(big.Rat).SetString(tt.min)
+			}
+			got := checkCommission(msg, min)
+			if len(got) != len(tt.want) {
+				t.Fatalf("checkCommission() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("checkCommission()[%d] = %q, want it to contain %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package gentxlint
+
+import "strings"
+
+// entry is what identifies the validator of one gentx file.
+type entry struct {
+	file    string
+	moniker string
+	valoper string
+	pubKey  string
+	// nodeID is empty when the memo is not a valid peer address.
+	nodeID string
+}
+
+// checkDuplicates reports validators sharing an operator address, consensus
+// pubkey, node ID or moniker, on the later file of each pair. wardend only
+// panics on these deep into collect-gentxs.
+func checkDuplicates(validators []*entry, report func(file, format string, args ...any)) {
+	for _, field := range []struct {
+		what string
+		key  func(v *entry) string
+	}{
+		{"operator address", func(v *entry) string { return v.valoper }},
+		{"consensus pubkey", func(v *entry) string { return v.pubKey }},
+		{"node ID", func(v *entry) string { return v.nodeID }},
+		// Monikers differing only by case or surrounding spaces are just as
+		// confusing in explorers.
+		{"moniker", func(v *entry) string { return strings.ToLower(strings.TrimSpace(v.moniker)) }},
+	} {
+		seen := map[string]string{}
+		for _, v := range validators {
+			key := field.key(v)
+			if key == "" {
+				continue
+			}
+			if other, ok := seen[key]; ok {
+				report(v.file, "same %s as %s", field.what, other)
+				continue
+			}
+			seen[key] = v.file
+		}
+	}
+}
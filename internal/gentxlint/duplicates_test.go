@@ -0,0 +1,41 @@
+package gentxlint
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestCheckDuplicates(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(a, b *entry)
+		want []string
+	}{
+		{"distinct", func(a, b *entry) {}, nil},
+		{"operator address", func(a, b *entry) { b.valoper = "wardenvaloper1a" }, []string{"b.json: same operator address as a.json"}},
+		{"consensus pubkey", func(a, b *entry) { b.pubKey = "key-a" }, []string{"b.json: same consensus pubkey as a.json"}},
+		{"node ID", func(a, b *entry) { b.nodeID = "node-a" }, []string{"b.json: same node ID as a.json"}},
+		{"moniker", func(a, b *entry) { b.moniker = " Alpha" }, []string{"b.json: same moniker as a.json"}},
+		{"empty keys", func(a, b *entry) { a.pubKey, a.nodeID, b.pubKey, b.nodeID = "", "", "", "" }, nil},
+		{"several fields", func(a, b *entry) { b.valoper, b.pubKey = "wardenvaloper1a", "key-a" }, []string{
+			"b.json: same operator address as a.json",
+			"b.json: same consensus pubkey as a.json",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &entry{file: "a.json", moniker: "alpha", valoper: "wardenvaloper1a", pubKey: "key-a", nodeID: "node-a"}
+			b := &entry{file: "b.json", moniker: "beta", valoper: "wardenvaloper1b", pubKey: "key-b", nodeID: "node-b"}
+			tt.edit(a, b)
+
+			var got []string
+			checkDuplicates([]*entry{a, b}, func(file, format string, args ...any) {
+				got = append(got, file+": "+fmt.Sprintf(format, args...))
+			})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("checkDuplicates reported %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
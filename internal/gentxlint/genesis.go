@@ -0,0 +1,97 @@
+package gentxlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// genesisState is what Check needs from the genesis the gentxs are
+// collected into.
+type genesisState struct {
+	// balances are the bank balances, by address.
+	balances map[string][]coin
+	// valopers and pubKeys map the operator addresses and consensus pubkeys
+	// of the validators the genesis already has to their moniker.
+	valopers map[string]string
+	pubKeys  map[string]string
+}
+
+// loadGenesis reads the genesis file at path.
+//
+// The validators of a collected genesis are in its gen_txs, which are the
+// gentxs being checked; only validators created otherwise, in the staking
+// state or the CometBFT validator set, count as existing.
+func loadGenesis(path string) (*genesisState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var g struct {
+		Validators []struct {
+			Name   string `json:"name"`
+			PubKey struct {
+				Value string `json:"value"`
+			} `json:"pub_key"`
+		} `json:"validators"`
+		AppState struct {
+			Bank struct {
+				Balances []struct {
+					Address string `json:"address"`
+					Coins   []coin `json:"coins"`
+				} `json:"balances"`
+			} `json:"bank"`
+			Staking struct {
+				Validators []struct {
+					OperatorAddress string  `json:"operator_address"`
+					ConsensusPubKey *pubKey `json:"consensus_pubkey"`
+					Description     struct {
+						Moniker string `json:"moniker"`
+					} `json:"description"`
+				} `json:"validators"`
+			} `json:"staking"`
+		} `json:"app_state"`
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	st := &genesisState{
+		balances: make(map[string][]coin, len(g.AppState.Bank.Balances)),
+		valopers: map[string]string{},
+		pubKeys:  map[string]string{},
+	}
+	for _, b := range g.AppState.Bank.Balances {
+		st.balances[b.Address] = append(st.balances[b.Address], b.Coins...)
+	}
+	for _, v := range g.AppState.Staking.Validators {
+		st.valopers[v.OperatorAddress] = v.Description.Moniker
+		if v.ConsensusPubKey != nil && v.ConsensusPubKey.Key != "" {
+			st.pubKeys[v.ConsensusPubKey.Key] = v.Description.Moniker
+		}
+	}
+	for _, v := range g.Validators {
+		if _, ok := st.pubKeys[v.PubKey.Value]; !ok && v.PubKey.Value != "" {
+			st.pubKeys[v.PubKey.Value] = v.Name
+		}
+	}
+	return st, nil
+}
+
+// checkExisting returns the problems of a gentx creating a validator the
+// genesis already has, by operator address or consensus pubkey. wardend
+// only fails on these with "validator already exist for this operator
+// address; must use new validator operator address" or its pubkey
+// equivalent, without naming the file.
+func checkExisting(msg *message, st *genesisState) []string {
+	var problems []string
+	if moniker, ok := st.valopers[msg.ValidatorAddress]; ok {
+		problems = append(problems, fmt.Sprintf("validator already exists in genesis: operator address %s is used by %q", msg.ValidatorAddress, moniker))
+	}
+	if msg.PubKey != nil {
+		if moniker, ok := st.pubKeys[msg.PubKey.Key]; ok {
+			problems = append(problems, fmt.Sprintf("validator already exists in genesis: consensus pubkey %s is used by %q", msg.PubKey.Key, moniker))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,60 @@
+package gentxlint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckExisting(t *testing.T) {
+	const genesis = `{
+  "validators": [{"name": "cometbft-only", "pub_key": {"type": "tendermint/PubKeyEd25519", "value": "cometKey="}}],
+  "app_state": {
+    "bank": {"balances": [{"address": "warden1a", "coins": [{"denom": "uward", "amount": "1"}]}]},
+    "staking": {"validators": [{
+      "operator_address": "wardenvaloper1existing",
+      "consensus_pubkey": {"@type": "/cosmos.crypto.ed25519.PubKey", "key": "stakingKey="},
+      "description": {"moniker": "genesis-validator"}
+    }]}
+  }
+}`
+	path := filepath.Join(t.TempDir(), "genesis.json")
+	if err := os.WriteFile(path, []byte(genesis), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	st, err := loadGenesis(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := st.balances["warden1a"]; len(got) != 1 || got[0].Amount != "1" {
+		t.Errorf("balances = %v", st.balances)
+	}
+
+	tests := []struct {
+		name    string
+		valoper string
+		key     string
+		want    []string
+	}{
+		{"new validator", "wardenvaloper1new", "newKey=", nil},
+		{"existing operator", "wardenvaloper1existing", "newKey=", []string{`operator address wardenvaloper1existing is used by "genesis-validator"`}},
+		{"existing staking pubkey", "wardenvaloper1new", "stakingKey=", []string{`consensus pubkey stakingKey= is used by "genesis-validator"`}},
+		{"existing cometbft pubkey", "wardenvaloper1new", "cometKey=", []string{`consensus pubkey cometKey= is used by "cometbft-only"`}},
+		{"both", "wardenvaloper1existing", "stakingKey=", []string{"operator address", "consensus pubkey"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &message{ValidatorAddress: tt.valoper, PubKey: &pubKey{Key: tt.key}}
+			got := checkExisting(msg, st)
+			if len(got) != len(tt.want) {
+				t.Fatalf("checkExisting() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], "validator already exists in genesis: ") || !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("checkExisting()[%d] = %q, want it to contain %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,510 @@
+// Package gentxlint implements the gentx-lint command, which checks the gentx
+// files of the networks in this repo before they are collected into a
+// genesis, so that a bad submission is rejected with a precise error instead
+// of failing deep inside wardend.
+//
+// With -inspect, the MsgCreateValidator of a single gentx file is printed
+// instead, for reviewers to eyeball a submission without jq.
+//
+// Usage:
+//
+//	gentx-lint
+//	gentx-lint -inspect testnets/alfama/gentx/gentx-validator-1.json
+//	gentx-lint -format json alfama
+//	gentx-lint -chain-id alfama alfama
+//	gentx-lint -public-memos -dial 5s alfama
+//	gentx-lint -min-self-delegation 1000000 -max-self-delegation 10000000 alfama
+//	gentx-lint -min-commission 0.05 alfama
+//	gentx-lint -genesis init_genesis.json alfama
+//	gentx-lint -denom award alfama
+//	gentx-lint -min-gas-prices 0.0025uward alfama
+//	gentx-lint -min-gas 100000 -max-gas 1000000 alfama
+//	gentx-lint -pubkey-types ed25519,secp256k1 alfama
+package gentxlint
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+const msgCreateValidator = "/cosmos.staking.v1beta1.MsgCreateValidator"
+
+// Problem is a mistake found in a gentx file.
+type Problem struct {
+	Network string `json:"network"`
+	File    string `json:"file"`
+	Problem string `json:"problem"`
+}
+
+type gentx struct {
+	Body struct {
+		Messages      []message `json:"messages"`
+		Memo          string    `json:"memo"`
+		TimeoutHeight string    `json:"timeout_height"`
+	} `json:"body"`
+	AuthInfo struct {
+		SignerInfos []signerInfo `json:"signer_infos"`
+		Fee         struct {
+			Amount   []coin `json:"amount"`
+			GasLimit string `json:"gas_limit"`
+			Payer    string `json:"payer"`
+			Granter  string `json:"granter"`
+		} `json:"fee"`
+	} `json:"auth_info"`
+	Signatures []string `json:"signatures"`
+}
+
+// message holds the fields of a MsgCreateValidator.
+type message struct {
+	Type        string `json:"@type"`
+	Description struct {
+		Moniker         string `json:"moniker"`
+		Identity        string `json:"identity"`
+		Website         string `json:"website"`
+		SecurityContact string `json:"security_contact"`
+		Details         string `json:"details"`
+	} `json:"description"`
+	Commission struct {
+		Rate          string `json:"rate"`
+		MaxRate       string `json:"max_rate"`
+		MaxChangeRate string `json:"max_change_rate"`
+	} `json:"commission"`
+	MinSelfDelegation string  `json:"min_self_delegation"`
+	DelegatorAddress  string  `json:"delegator_address"`
+	ValidatorAddress  string  `json:"validator_address"`
+	PubKey            *pubKey `json:"pubkey"`
+	Value             coin    `json:"value"`
+}
+
+type signerInfo struct {
+	PublicKey *pubKey `json:"public_key"`
+	ModeInfo  struct {
+		Single *singleMode `json:"single"`
+	} `json:"mode_info"`
+	Sequence string `json:"sequence"`
+}
+
+type singleMode struct {
+	Mode string `json:"mode"`
+}
+
+type coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+// Options configure Check.
+type Options struct {
+	// ChainID is the chain-id the gentxs must be signed for; signatures
+	// are not checked when it is empty.
+	ChainID string
+	// OtherChainIDs are tried when a signature does not verify for
+	// ChainID, to tell which chain the gentx was signed for.
+	OtherChainIDs []string
+	// PublicMemos rejects memo addresses other nodes cannot dial.
+	PublicMemos bool
+	// Dial, when not zero, is the timeout of a TCP connection to each memo
+	// address.
+	Dial time.Duration
+	// MinSelfDelegation and MaxSelfDelegation, when not nil, bound the
+	// amount each validator self-delegates, in base units of the staking
+	// denom.
+	MinSelfDelegation *big.Int
+	MaxSelfDelegation *big.Int
+	// MinCommission, when not nil, is the lowest commission rate allowed.
+	MinCommission *big.Rat
+	// Genesis is the genesis file the gentxs are collected into: its bank
+	// balances must fund each gentx, and it must not have their validators
+	// already. Neither is checked when it is empty.
+	Genesis string
+	// Denom is the denom of the self-delegation, and of fees when there is
+	// no fee policy; it is not checked when empty.
+	Denom string
+	// Fees is the fee policy: the denoms accepted for fees and their
+	// minimum gas prices.
+	Fees []networks.FeeToken
+	// MinGas and MaxGas bound the fee's gas limit; a zero MaxGas is no
+	// upper bound.
+	MinGas, MaxGas uint64
+	// PubKeyTypes are the consensus pubkey types allowed, by the last
+	// element of their type URL ("ed25519"); pubkeys are not checked when
+	// it is empty.
+	PubKeyTypes []string
+}
+
+// Command is the gentx-lint command.
+var Command = &cli.Command{
+	Name:  "gentx-lint",
+	Args:  "[network...] | -inspect <gentx.json>",
+	Short: "Check the gentx files of networks: structure, schema, file names, signatures, denoms, consensus pubkeys, self-delegations, commissions, funds, memos and duplicate validators; or print a single gentx.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format      = cli.FormatFlag(fs, "text", "json")
+			chainID     = fs.String("chain-id", "", "chain-id the gentxs are signed for (default: the network's)")
+			publicMemos = fs.Bool("public-memos", false, "reject memos with loopback, private or link-local addresses (always on for mainnets)")
+			dial        = fs.Duration("dial", 0, "check that memo addresses accept TCP connections within this timeout; 0 skips the check")
+			minSelf     = fs.String("min-self-delegation", "", "smallest self-delegation allowed, in base units of the staking denom")
+			maxSelf     = fs.String("max-self-delegation", "", "largest self-delegation allowed, in base units of the staking denom")
+			minComm     = fs.String("min-commission", "", "lowest commission rate allowed, as a decimal (e.g. 0.05)")
+			genesis     = fs.String("genesis", "", "genesis the gentxs are collected into, to check funds and existing validators against (default: the network's init_genesis.json or genesis.json)")
+			denom       = fs.String("denom", "", "denom of the self-delegation and fees (default: the network's staking denom)")
+			gasPrices   = fs.String("min-gas-prices", "", "fee policy as comma-separated minimum gas prices, e.g. 0.0025uward (default: the fee_tokens of the network's chain.json)")
+			minGas      = fs.Uint64("min-gas", 200_000, "lowest allowed fee gas_limit")
+			maxGas      = fs.Uint64("max-gas", 10_000_000, "highest allowed fee gas_limit; 0 means no limit")
+			pubKeyTypes = fs.String("pubkey-types", "ed25519", "comma-separated consensus pubkey types allowed on the network")
+			inspect     = fs.String("inspect", "", "print the validator created by this gentx file instead of checking networks")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if *inspect != "" {
+				if len(args) != 0 {
+					return cli.ErrUsage
+				}
+				s, err := Inspect(*inspect)
+				if err != nil {
+					return err
+				}
+				if format.JSON() {
+					return cli.WriteJSON(env.Stdout, s)
+				}
+				return writeSummary(env.Stdout, s)
+			}
+
+			minSelfDelegation, err := parseAmount("min-self-delegation", *minSelf)
+			if err != nil {
+				return err
+			}
+			maxSelfDelegation, err := parseAmount("max-self-delegation", *maxSelf)
+			if err != nil {
+				return err
+			}
+			if minSelfDelegation != nil && maxSelfDelegation != nil && minSelfDelegation.Cmp(maxSelfDelegation) > 0 {
+				return fmt.Errorf("-min-self-delegation %s is above -max-self-delegation %s", minSelfDelegation, maxSelfDelegation)
+			}
+			fees, err := parseGasPrices(*gasPrices)
+			if err != nil {
+				return fmt.Errorf("-min-gas-prices: %v", err)
+			}
+			var minCommission *big.Rat
+			if *minComm != "" {
+				if minCommission, err = parseRate(*minComm); err != nil {
+					return fmt.Errorf("-min-commission %q: %v", *minComm, err)
+				}
+			}
+
+			dirs, err := gentxDirs(env.Root, args)
+			if err != nil {
+				return err
+			}
+			if *genesis != "" && len(dirs) > 1 {
+				return fmt.Errorf("-genesis needs a single network, got %d", len(dirs))
+			}
+			chainIDs, err := knownChainIDs(env.Root)
+			if err != nil {
+				return err
+			}
+
+			problems := []Problem{}
+			files := 0
+			for _, dir := range dirs {
+				n, err := network.Load(filepath.Dir(dir))
+				if err != nil {
+					return err
+				}
+				opts := Options{
+					ChainID:           *chainID,
+					OtherChainIDs:     chainIDs,
+					PublicMemos:       *publicMemos || n.Mainnet(),
+					Dial:              *dial,
+					MinSelfDelegation: minSelfDelegation,
+					MaxSelfDelegation: maxSelfDelegation,
+					MinCommission:     minCommission,
+					Genesis:           *genesis,
+					Denom:             *denom,
+					Fees:              fees,
+					MinGas:            *minGas,
+					MaxGas:            *maxGas,
+					PubKeyTypes:       network.SplitList(*pubKeyTypes),
+				}
+				if opts.ChainID == "" {
+					if n.ChainID == "" {
+						fmt.Fprintf(env.Stderr, "warning: no chain-id known for %s, pass -chain-id to check signatures\n", n.Dir)
+					}
+					opts.ChainID = n.ChainID
+				}
+				if opts.Genesis == "" {
+					opts.Genesis = initGenesis(n)
+				}
+				if opts.Fees == nil {
+					opts.Fees = n.Fees
+				}
+				if opts.Denom == "" {
+					opts.Denom = n.StakingDenom
+				}
+				if opts.Denom == "" {
+					if opts.Denom, err = stakingDenom(opts.Genesis); err != nil {
+						return err
+					}
+					if opts.Denom == "" {
+						fmt.Fprintf(env.Stderr, "warning: no staking denom known for %s, pass -denom to check denoms\n", n.Dir)
+					}
+				}
+				p, c, err := Check(dir, opts)
+				if err != nil {
+					return err
+				}
+				problems = append(problems, p...)
+				files += c
+			}
+
+			if format.JSON() {
+				if err := cli.WriteJSON(env.Stdout, problems); err != nil {
+					return err
+				}
+			} else {
+				for _, p := range problems {
+					fmt.Fprintf(env.Stdout, "%s: %s: %s\n", p.Network, p.File, p.Problem)
+				}
+			}
+			if len(problems) > 0 {
+				bad := map[string]bool{}
+				for _, p := range problems {
+					bad[p.Network+"/"+p.File] = true
+				}
+				return fmt.Errorf("%d of %d gentx file(s) have problems", len(bad), files)
+			}
+			if !format.JSON() {
+				fmt.Fprintf(env.Stdout, "%d gentx file(s) in %d network(s) OK\n", files, len(dirs))
+			}
+			return nil
+		}
+	},
+}
+
+// knownChainIDs returns the chain-ids of the networks under root, sorted.
+func knownChainIDs(root string) ([]string, error) {
+	networks, err := network.LoadAll(root, nil)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var ids []string
+	for _, n := range networks {
+		if n.ChainID != "" && !seen[n.ChainID] {
+			seen[n.ChainID] = true
+			ids = append(ids, n.ChainID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// gentxDirs returns the gentx directories of the given networks, or of all
+// networks that have one when names is empty.
+func gentxDirs(root string, names []string) ([]string, error) {
+	var dirs []string
+	if len(names) == 0 {
+		all, err := network.List(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range all {
+			if isDir(filepath.Join(d, "gentx")) {
+				dirs = append(dirs, filepath.Join(d, "gentx"))
+			}
+		}
+		if len(dirs) == 0 {
+			return nil, fmt.Errorf("no network under %s has a gentx directory", root)
+		}
+		return dirs, nil
+	}
+	for _, name := range names {
+		d, err := network.Resolve(root, name)
+		if err != nil {
+			return nil, err
+		}
+		if !isDir(filepath.Join(d, "gentx")) {
+			return nil, fmt.Errorf("%s has no gentx directory", d)
+		}
+		dirs = append(dirs, filepath.Join(d, "gentx"))
+	}
+	return dirs, nil
+}
+
+// Check checks the files in the gentx directory dir and returns the
+// problems found and the number of files checked.
+//
+// A file carrying anything but a single MsgCreateValidator is reported by
+// checkStructure, and one whose fields do not match gentx.schema.json by
+// checkSchema; neither is looked at further. The others get their file
+// name, consensus pubkey, signature, sign mode and sequence, gas limit,
+// denoms, self-delegation, commission, funds, memo and uniqueness
+// checked, the latter against the validators of the genesis too.
+func Check(dir string, opts Options) ([]Problem, int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	name := filepath.Base(filepath.Dir(dir))
+
+	var genesis *genesisState
+	if opts.Genesis != "" {
+		if genesis, err = loadGenesis(opts.Genesis); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var problems []Problem
+	report := func(file, format string, args ...any) {
+		problems = append(problems, Problem{Network: name, File: file, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	var validators []*entry
+	var names []string
+	files := 0
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		files++
+		file := e.Name()
+		names = append(names, file)
+
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, 0, err
+		}
+		if p := checkStructure(data); len(p) > 0 {
+			for _, p := range p {
+				report(file, "%s", p)
+			}
+			continue
+		}
+		if p := checkSchema(data); len(p) > 0 {
+			for _, p := range p {
+				report(file, "%s", p)
+			}
+			continue
+		}
+		var tx gentx
+		if err := json.Unmarshal(data, &tx); err != nil {
+			report(file, "decode: %v", err)
+			continue
+		}
+
+		if opts.ChainID != "" {
+			if p := checkSignature(&tx, opts.ChainID, opts.OtherChainIDs); p != "" {
+				report(file, "%s", p)
+			}
+		}
+
+		for _, p := range checkTx(&tx, opts) {
+			report(file, "%s", p)
+		}
+		msg := &tx.Body.Messages[0]
+		if p := checkFileName(file, msg); p != "" {
+			report(file, "%s", p)
+		}
+		if len(opts.PubKeyTypes) > 0 {
+			if p := checkPubKey(msg.PubKey, opts.PubKeyTypes); p != "" {
+				report(file, "%s", p)
+			}
+		}
+		for _, p := range checkSelfDelegation(msg, opts.MinSelfDelegation, opts.MaxSelfDelegation) {
+			report(file, "%s", p)
+		}
+		for _, p := range checkCommission(msg, opts.MinCommission) {
+			report(file, "%s", p)
+		}
+		if genesis != nil {
+			for _, p := range checkFunds(&tx, genesis.balances) {
+				report(file, "%s", p)
+			}
+			for _, p := range checkExisting(msg, genesis) {
+				report(file, "%s", p)
+			}
+		}
+		v := &entry{file: file, moniker: msg.Description.Moniker, valoper: msg.ValidatorAddress}
+		if msg.PubKey != nil {
+			v.pubKey = msg.PubKey.Key
+		}
+		validators = append(validators, v)
+		if p := checkMemo(tx.Body.Memo, opts.PublicMemos); p != "" {
+			report(file, "%s", p)
+		} else {
+			var addr string
+			v.nodeID, addr, _ = networks.ParsePeer(tx.Body.Memo)
+			if opts.Dial > 0 {
+				conn, err := net.DialTimeout("tcp", addr, opts.Dial)
+				if err != nil {
+					report(file, "memo %q: node is not reachable: %v", tx.Body.Memo, err)
+				} else {
+					conn.Close()
+				}
+			}
+		}
+	}
+
+	checkDuplicates(validators, report)
+	checkFileCase(names, report)
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].File < problems[j].File })
+	return problems, files, nil
+}
+
+// stakingDenom returns the bond_denom of the genesis file at path, or ""
+// when path is empty or the genesis has none.
+func stakingDenom(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var g struct {
+		AppState struct {
+			Staking struct {
+				Params struct {
+					BondDenom string `json:"bond_denom"`
+				} `json:"params"`
+			} `json:"staking"`
+		} `json:"app_state"`
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		return "", fmt.Errorf("decode %s: %w", path, err)
+	}
+	return g.AppState.Staking.Params.BondDenom, nil
+}
+
+// initGenesis returns the genesis the gentxs of n are collected into: its
+// init_genesis.json, or its genesis file.
+func initGenesis(n *network.Network) string {
+	if p := filepath.Join(n.Dir, "init_genesis.json"); fileExists(p) {
+		return p
+	}
+	return n.Genesis
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+func fileExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
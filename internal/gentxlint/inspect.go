@@ -0,0 +1,112 @@
+package gentxlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Summary is the validator a gentx creates, as printed by -inspect.
+type Summary struct {
+	Moniker           string   `json:"moniker"`
+	Identity          string   `json:"identity,omitempty"`
+	Website           string   `json:"website,omitempty"`
+	SecurityContact   string   `json:"security_contact,omitempty"`
+	Details           string   `json:"details,omitempty"`
+	OperatorAddress   string   `json:"operator_address"`
+	DelegatorAddress  string   `json:"delegator_address,omitempty"`
+	ConsensusPubKey   string   `json:"consensus_pubkey"`
+	SelfDelegation    string   `json:"self_delegation"`
+	MinSelfDelegation string   `json:"min_self_delegation"`
+	CommissionRate    string   `json:"commission_rate"`
+	CommissionMaxRate string   `json:"commission_max_rate"`
+	CommissionMaxDiff string   `json:"commission_max_change_rate"`
+	Peer              string   `json:"peer"`
+	Fee               string   `json:"fee"`
+	GasLimit          string   `json:"gas_limit"`
+	Signers           []string `json:"signers"`
+}
+
+// Inspect decodes the gentx file at path and summarizes its
+// MsgCreateValidator.
+func Inspect(path string) (*Summary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tx gentx
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	i := slices.IndexFunc(tx.Body.Messages, func(m message) bool { return m.Type == msgCreateValidator })
+	if i < 0 {
+		return nil, fmt.Errorf("%s: no MsgCreateValidator", path)
+	}
+	m := tx.Body.Messages[i]
+
+	s := &Summary{
+		Moniker:           m.Description.Moniker,
+		Identity:          m.Description.Identity,
+		Website:           m.Description.Website,
+		SecurityContact:   m.Description.SecurityContact,
+		Details:           m.Description.Details,
+		OperatorAddress:   m.ValidatorAddress,
+		DelegatorAddress:  m.DelegatorAddress,
+		SelfDelegation:    m.Value.Amount + m.Value.Denom,
+		MinSelfDelegation: m.MinSelfDelegation,
+		CommissionRate:    m.Commission.Rate,
+		CommissionMaxRate: m.Commission.MaxRate,
+		CommissionMaxDiff: m.Commission.MaxChangeRate,
+		Peer:              tx.Body.Memo,
+		Fee:               "none",
+		GasLimit:          tx.AuthInfo.Fee.GasLimit,
+		Signers:           []string{},
+	}
+	if m.PubKey != nil {
+		s.ConsensusPubKey = m.PubKey.keyType() + " " + m.PubKey.Key
+	}
+	var fee []string
+	for _, c := range tx.AuthInfo.Fee.Amount {
+		fee = append(fee, c.Amount+c.Denom)
+	}
+	if len(fee) > 0 {
+		s.Fee = strings.Join(fee, ",")
+	}
+	for _, si := range tx.AuthInfo.SignerInfos {
+		mode := "multisig"
+		if si.ModeInfo.Single != nil {
+			mode = si.ModeInfo.Single.Mode
+		}
+		s.Signers = append(s.Signers, fmt.Sprintf("%s, sequence %s", mode, si.Sequence))
+	}
+	return s, nil
+}
+
+func writeSummary(w io.Writer, s *Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range [][2]string{
+		{"Moniker", s.Moniker},
+		{"Identity", s.Identity},
+		{"Website", s.Website},
+		{"Security contact", s.SecurityContact},
+		{"Details", s.Details},
+		{"Operator address", s.OperatorAddress},
+		{"Delegator address", s.DelegatorAddress},
+		{"Consensus pubkey", s.ConsensusPubKey},
+		{"Self-delegation", s.SelfDelegation},
+		{"Min self-delegation", s.MinSelfDelegation},
+		{"Commission", fmt.Sprintf("%s (max %s, max change %s)", s.CommissionRate, s.CommissionMaxRate, s.CommissionMaxDiff)},
+		{"Peer", s.Peer},
+		{"Fee", fmt.Sprintf("%s, gas limit %s", s.Fee, s.GasLimit)},
+		{"Signers", strings.Join(s.Signers, "; ")},
+	} {
+		if row[1] != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", row[0], row[1])
+		}
+	}
+	return tw.Flush()
+}
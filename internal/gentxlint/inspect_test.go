@@ -0,0 +1,103 @@
+package gentxlint
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/warden-protocol/networks/internal/cli"
+)
+
+func TestInspect(t *testing.T) {
+	s, err := Inspect("../../testnets/alfama/gentx/gentx-validator-1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Summary{
+		Moniker:           "validator-1",
+		OperatorAddress:   "wardenvaloper1vw3xl9jjp9xy6yek0ap3yzc9f9hqvtamy9ks5c",
+		ConsensusPubKey:   "ed25519 umlXq6tojRLPe6JAfoRO6RqFCbl9q3L6Z67jFZqndIM=",
+		SelfDelegation:    "100000000uward",
+		MinSelfDelegation: "1",
+		CommissionRate:    "0.100000000000000000",
+		CommissionMaxRate: "0.200000000000000000",
+		CommissionMaxDiff: "0.010000000000000000",
+		Peer:              "4b41a522de2124e719227622fa3cb65dd6745d20@10.1.8.90:26656",
+		Fee:               "none",
+		GasLimit:          "200000",
+		Signers:           []string{"SIGN_MODE_DIRECT, sequence 0"},
+	}
+	if !reflect.DeepEqual(*s, want) {
+		t.Errorf("Inspect() = %+v, want %+v", *s, want)
+	}
+
+	var buf bytes.Buffer
+	if err := writeSummary(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"Moniker:              validator-1\n",
+		"Commission:           0.100000000000000000 (max 0.200000000000000000, max change 0.010000000000000000)\n",
+		"Fee:                  none, gas limit 200000\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("summary does not contain %q:\n%s", line, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "Website:") {
+		t.Errorf("summary prints empty fields:\n%s", buf.String())
+	}
+}
+
+func TestInspectErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"not json", "{", "decode"},
+		{"no validator", `{"body":{"messages":[{"@type":"/cosmos.bank.v1beta1.MsgSend"}]}}`, "no MsgCreateValidator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "gentx.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Inspect(path)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Inspect() error = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestInspectCommand(t *testing.T) {
+	const file = "../../testnets/alfama/gentx/gentx-validator-1.json"
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+	}{
+		{"text", []string{"-inspect", file}, 0, "Moniker:              validator-1\n"},
+		{"json", []string{"-format", "json", "-inspect", file}, 0, `"moniker": "validator-1"`},
+		{"with networks", []string{"-inspect", file, "alfama"}, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			env := &cli.Env{Stdout: &stdout, Stderr: &stderr, Root: "../.."}
+			if code := cli.Exec(context.Background(), "gentx-lint", Command, env, tt.args); code != tt.code {
+				t.Fatalf("exit status %d, want %d: %s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package gentxlint
+
+import (
+	"fmt"
+
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// checkMemo returns what is wrong with the node address in a gentx memo, or
+// "" when it is a valid "nodeID@host:port" peer address, publicly routable if
+// public is set. The memos of a network end up as its persistent peers.
+func checkMemo(memo string, public bool) string {
+	if memo == "" {
+		return "empty memo, expected the node's nodeID@ip:port"
+	}
+	_, addr, err := networks.ParsePeer(memo)
+	if err != nil {
+		return fmt.Sprintf("memo %q: %v", memo, err)
+	}
+	if public {
+		if err := networks.CheckPublic(addr); err != nil {
+			return fmt.Sprintf("memo %q: %v, which other nodes cannot dial", memo, err)
+		}
+	}
+	return ""
+}
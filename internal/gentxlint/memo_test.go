@@ -0,0 +1,45 @@
+package gentxlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckMemo(t *testing.T) {
+	const id = "4b41a522de2124e719227622fa3cb65dd6745d20"
+
+	tests := []struct {
+		name   string
+		memo   string
+		public bool
+		want   string // substring of the problem, "" for none
+	}{
+		{"private", id + "@10.1.8.90:26656", false, ""},
+		{"private on public network", id + "@10.1.8.90:26656", true, "private address 10.1.8.90"},
+		{"loopback", id + "@127.0.0.1:26656", false, ""},
+		{"loopback on public network", id + "@127.0.0.1:26656", true, "loopback address 127.0.0.1"},
+		{"localhost on public network", id + "@localhost:26656", true, "loopback host localhost"},
+		{"link-local on public network", id + "@[fe80::1]:26656", true, "non-routable address fe80::1"},
+		{"public", id + "@203.0.113.7:26656", false, ""},
+		{"public on public network", id + "@203.0.113.7:26656", true, ""},
+		{"hostname on public network", id + "@node.example.org:26656", true, ""},
+		{"upper-case node id", strings.ToUpper(id) + "@203.0.113.7:26656", true, ""},
+		{"empty", "", false, "empty memo"},
+		{"no node id", "203.0.113.7:26656", false, "expected nodeID@host:port"},
+		{"short node id", "4b41a522@203.0.113.7:26656", false, "not 40 hex characters"},
+		{"no port", id + "@203.0.113.7", false, "missing port"},
+		{"port out of range", id + "@203.0.113.7:70000", false, `invalid port "70000"`},
+		{"port zero", id + "@203.0.113.7:0", false, `invalid port "0"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkMemo(tt.memo, tt.public)
+			switch {
+			case tt.want == "" && got != "":
+				t.Errorf("checkMemo(%q, %t) = %q, want no problem", tt.memo, tt.public, got)
+			case tt.want != "" && !strings.Contains(got, tt.want):
+				t.Errorf("checkMemo(%q, %t) = %q, want it to contain %q", tt.memo, tt.public, got, tt.want)
+			}
+		})
+	}
+}
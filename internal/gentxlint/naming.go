@@ -0,0 +1,69 @@
+package gentxlint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// checkFileName returns what is wrong with the name of the gentx file
+// holding msg, or "". A gentx file is named after the validator's moniker,
+// "gentx-<moniker>.json", or after its operator address,
+// "<valoper-address>.json", and the name must match the message. Monikers
+// are compared as monikerSlug, so "Validator 3 (EQLab)" matches
+// gentx-validator-3-eqlab.json.
+func checkFileName(file string, msg *message) string {
+	base, ok := strings.CutSuffix(file, ".json")
+	if !ok {
+		return "not a .json file"
+	}
+	moniker, valoper := msg.Description.Moniker, msg.ValidatorAddress
+	if slug, ok := strings.CutPrefix(base, "gentx-"); ok {
+		if want := monikerSlug(moniker); slug != want {
+			return fmt.Sprintf("named after moniker %q, expected gentx-%s.json for moniker %q", slug, want, moniker)
+		}
+		return ""
+	}
+	if strings.Contains(base, "valoper1") {
+		if base != valoper {
+			return fmt.Sprintf("named after operator address %s, but the gentx is for %s", base, valoper)
+		}
+		return ""
+	}
+	return fmt.Sprintf("expected gentx-%s.json or %s.json", monikerSlug(moniker), valoper)
+}
+
+// checkFileCase reports file names differing only by case, on all but the
+// first of each group in sorted order: they collide when the repo is checked
+// out on a case-insensitive filesystem.
+func checkFileCase(files []string, report func(file, format string, args ...any)) {
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+	seen := map[string]string{}
+	for _, f := range files {
+		key := strings.ToLower(f)
+		if other, ok := seen[key]; ok {
+			report(f, "name differs only by case from %s", other)
+			continue
+		}
+		seen[key] = f
+	}
+}
+
+// monikerSlug returns the file name form of a moniker: lower case, with
+// runs of characters other than letters, digits, "." and "_" replaced by
+// "-".
+func monikerSlug(moniker string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(moniker) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
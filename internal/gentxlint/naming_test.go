@@ -0,0 +1,70 @@
+package gentxlint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMonikerSlug(t *testing.T) {
+	tests := []struct {
+		moniker, want string
+	}{
+		{"validator-1", "validator-1"},
+		{"Validator 3 (EQLab)", "validator-3-eqlab"},
+		{"  leading and trailing  ", "leading-and-trailing"},
+		{"node.one_two", "node.one_two"},
+		{"a -- b", "a-b"},
+		{"Zürich Node", "z-rich-node"},
+		{"!!!", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := monikerSlug(tt.moniker); got != tt.want {
+			t.Errorf("monikerSlug(%q) = %q, want %q", tt.moniker, got, tt.want)
+		}
+	}
+}
+
+func TestCheckFileName(t *testing.T) {
+	const valoper = "wardenvaloper1vw3xl9jjp9xy6yek0ap3yzc9f9hqvtamy9ks5c"
+	msg := &message{ValidatorAddress: valoper}
+	msg.Description.Moniker = "Validator 3 (EQLab)"
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"gentx-validator-3-eqlab.json", ""},
+		{valoper + ".json", ""},
+		{"gentx-validator-3.json", `named after moniker "validator-3", expected gentx-validator-3-eqlab.json for moniker "Validator 3 (EQLab)"`},
+		{"gentx-Validator-3-EQLab.json", `named after moniker "Validator-3-EQLab"`},
+		{"wardenvaloper1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqnrql8a.json", "named after operator address wardenvaloper1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqnrql8a, but the gentx is for " + valoper},
+		{"eqlab.json", "expected gentx-validator-3-eqlab.json or " + valoper + ".json"},
+		{"gentx-validator-3-eqlab.txt", "not a .json file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got := checkFileName(tt.file, msg)
+			if tt.want == "" && got != "" || !strings.HasPrefix(got, tt.want) {
+				t.Errorf("checkFileName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFileCase(t *testing.T) {
+	files := []string{"gentx-b.json", "gentx-A.json", "gentx-a.json", "gentx-B.json", "gentx-c.json", "GENTX-A.json"}
+	var got []string
+	checkFileCase(files, func(file, format string, args ...any) {
+		got = append(got, file+": "+fmt.Sprintf(format, args...))
+	})
+	want := []string{
+		"gentx-A.json: name differs only by case from GENTX-A.json",
+		"gentx-a.json: name differs only by case from GENTX-A.json",
+		"gentx-b.json: name differs only by case from gentx-B.json",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("checkFileCase() reported %q, want %q", got, want)
+	}
+}
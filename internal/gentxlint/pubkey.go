@@ -0,0 +1,71 @@
+package gentxlint
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// pubKeySizes are the lengths of the consensus key types CometBFT supports,
+// by the last element of their type URL.
+var pubKeySizes = map[string]int{
+	"ed25519":   32,
+	"secp256k1": 33,
+	"bls12_381": 48,
+}
+
+// testKeys are well-known public keys whose private keys are public or
+// trivially derived, such as keys made from a fixed seed in tests and
+// examples. A validator using one can be impersonated by anyone.
+var testKeys = map[string]string{
+	"O2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik=": "the ed25519 key of the all-zero seed",
+	"iojj3XQJ8ZX9UtstPLpdcspnCb8dlBIb83SIAbQPb1w=": "the ed25519 key of the all-0x01 seed",
+	"WGZmZmZmZmZmZmZmZmZmZmZmZmZmZmZmZmZmZmZmZmY=": "the ed25519 base point, the key of private scalar 1",
+	"Anm+Zn753LusVaBilc6HCwcCm/zbLc4o2VnygVsW+BeY": "the secp256k1 generator, the key of private key 1",
+}
+
+type pubKey struct {
+	Type string `json:"@type"`
+	Key  string `json:"key"`
+}
+
+// keyType returns the short type of pk, "ed25519" for
+// "/cosmos.crypto.ed25519.PubKey".
+func (pk *pubKey) keyType() string {
+	t := strings.TrimSuffix(pk.Type, ".PubKey")
+	return t[strings.LastIndex(t, ".")+1:]
+}
+
+// checkPubKey returns what is wrong with the consensus pubkey pk, or "" when
+// it is a well-formed key of one of the allowed types.
+func checkPubKey(pk *pubKey, allowed []string) string {
+	if pk == nil || pk.Type == "" && pk.Key == "" {
+		return "no consensus pubkey"
+	}
+	kind := pk.keyType()
+	if !slices.Contains(allowed, kind) {
+		return fmt.Sprintf("consensus pubkey type %s is not allowed (allowed: %s)", pk.Type, strings.Join(allowed, ", "))
+	}
+	key, err := base64.StdEncoding.DecodeString(pk.Key)
+	if err != nil {
+		return fmt.Sprintf("consensus pubkey %q is not valid base64: %v", pk.Key, err)
+	}
+	if size, ok := pubKeySizes[kind]; ok && len(key) != size {
+		return fmt.Sprintf("consensus pubkey is %d bytes, %s keys are %d", len(key), kind, size)
+	}
+	zero := true
+	for _, b := range key {
+		if b != 0 {
+			zero = false
+			break
+		}
+	}
+	if zero {
+		return "consensus pubkey is all zeros"
+	}
+	if what, ok := testKeys[pk.Key]; ok {
+		return fmt.Sprintf("consensus pubkey is %s, whose private key is public", what)
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package gentxlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPubKey(t *testing.T) {
+	const ed25519Type = "/cosmos.crypto.ed25519.PubKey"
+
+	tests := []struct {
+		name    string
+		pk      *pubKey
+		allowed []string
+		want    string // substring of the problem, "" for none
+	}{
+		{"valid", &pubKey{ed25519Type, "umlXq6tojRLPe6JAfoRO6RqFCbl9q3L6Z67jFZqndIM="}, []string{"ed25519"}, ""},
+		{"missing", nil, []string{"ed25519"}, "no consensus pubkey"},
+		{"empty", &pubKey{}, []string{"ed25519"}, "no consensus pubkey"},
+		{"type not allowed", &pubKey{"/cosmos.crypto.secp256k1.PubKey", "Anm+Zn753LusVaBilc6HCwcCm/zbLc4o2VnygVsW+BeZ"}, []string{"ed25519"}, "is not allowed"},
+		{"other allowed type", &pubKey{"/cosmos.crypto.secp256k1.PubKey", "AphjF1zpcSUwD6aNlF/uH9eYft6fOV65QulLJjsvOgdK"}, []string{"ed25519", "secp256k1"}, ""},
+		{"bad base64", &pubKey{ed25519Type, "not base64!"}, []string{"ed25519"}, "not valid base64"},
+		{"wrong length", &pubKey{ed25519Type, "AAEC"}, []string{"ed25519"}, "is 3 bytes, ed25519 keys are 32"},
+		{"all zeros", &pubKey{ed25519Type, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}, []string{"ed25519"}, "all zeros"},
+		{"zero seed test key", &pubKey{ed25519Type, "O2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik="}, []string{"ed25519"}, "all-zero seed"},
+		{"secp256k1 generator", &pubKey{"/cosmos.crypto.secp256k1.PubKey", "Anm+Zn753LusVaBilc6HCwcCm/zbLc4o2VnygVsW+BeY"}, []string{"secp256k1"}, "generator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkPubKey(tt.pk, tt.allowed)
+			switch {
+			case tt.want == "" && got != "":
+				t.Errorf("checkPubKey() = %q, want no problem", got)
+			case tt.want != "" && !strings.Contains(got, tt.want):
+				t.Errorf("checkPubKey() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package gentxlint
+
+import (
+	_ "embed"
+
+	"github.com/warden-protocol/networks/internal/jsonschema"
+)
+
+//go:embed gentx.schema.json
+var gentxSchemaJSON []byte
+
+// gentxSchema is the JSON Schema of a gentx document.
+var gentxSchema = jsonschema.MustCompile(gentxSchemaJSON)
+
+// checkSchema returns the fields of the gentx data that do not match
+// gentx.schema.json, each prefixed with its JSON pointer: missing fields,
+// unexpected ones and values of the wrong type, such as a number where the
+// SDK writes amounts as strings. Such files would make wardend fail with an
+// unmarshalling error that does not say where.
+func checkSchema(data []byte) []string {
+	errs, err := gentxSchema.Validate(data)
+	if err != nil {
+		return []string{"decode: " + err.Error()}
+	}
+	problems := make([]string, len(errs))
+	for i, e := range errs {
+		problems[i] = e.Error()
+	}
+	return problems
+}
@@ -0,0 +1,45 @@
+package gentxlint
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckSchema(t *testing.T) {
+	valid, err := os.ReadFile("../../testnets/alfama/gentx/gentx-validator-1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		old, new string // replaced in the valid gentx
+		want     []string
+	}{
+		{"valid", "", "", nil},
+		{"number amount", `"amount":"100000000"`, `"amount":100000000`, []string{"/body/messages/0/value/amount: is integer, want string"}},
+		{"missing pubkey", `"pubkey":{"@type":"/cosmos.crypto.ed25519.PubKey","key":"umlXq6tojRLPe6JAfoRO6RqFCbl9q3L6Z67jFZqndIM="},`, "", []string{"/body/messages/0/pubkey: required property is missing"}},
+		{"extra description field", `"details":""`, `"details":"","twitter":"@v"`, []string{"/body/messages/0/description/twitter: unexpected property"}},
+		{"empty moniker", `"moniker":"validator-1"`, `"moniker":""`, []string{`/body/messages/0/description/moniker: "" is shorter than 1 character(s)`}},
+		{"decimal self-delegation", `"min_self_delegation":"1"`, `"min_self_delegation":"1.5"`, []string{`/body/messages/0/min_self_delegation: "1.5" does not match ^[0-9]+$`}},
+		{"string gas limit as number", `"gas_limit":"200000"`, `"gas_limit":200000`, []string{"/auth_info/fee/gas_limit: is integer, want string"}},
+		{"multisig mode info", `"mode_info":{"single":{"mode":"SIGN_MODE_DIRECT"}}`, `"mode_info":{"multi":{}}`,
+			[]string{"/auth_info/signer_infos/0/mode_info/single: required property is missing", "/auth_info/signer_infos/0/mode_info/multi: unexpected property"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := string(valid)
+			if tt.old != "" {
+				if !strings.Contains(data, tt.old) {
+					t.Fatalf("%q not found in the gentx", tt.old)
+				}
+				data = strings.Replace(data, tt.old, tt.new, 1)
+			}
+			got := checkSchema([]byte(data))
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("checkSchema() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package gentxlint
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+const secp256k1PubKey = "/cosmos.crypto.secp256k1.PubKey"
+
+// signModes are the protobuf values of the sign modes a gentx may declare.
+var signModes = map[string]uint64{
+	"SIGN_MODE_UNSPECIFIED":       0,
+	"SIGN_MODE_DIRECT":            1,
+	"SIGN_MODE_TEXTUAL":           2,
+	"SIGN_MODE_DIRECT_AUX":        3,
+	"SIGN_MODE_LEGACY_AMINO_JSON": 127,
+	"SIGN_MODE_EIP_191":           191,
+}
+
+// checkSignature returns what is wrong with the signature of tx, or "" when
+// it is the signature of its signer over tx for chainID at account number
+// and sequence 0, as wardend verifies it when the genesis is delivered.
+//
+// A signature that does not verify for chainID is tried against the
+// chain-ids in others, so that a gentx signed for another network is
+// reported as such rather than as modified.
+//
+// Only SIGN_MODE_DIRECT signatures by secp256k1 account keys can be checked
+// offline; others are left to wardend.
+func checkSignature(tx *gentx, chainID string, others []string) string {
+	infos := tx.AuthInfo.SignerInfos
+	if len(infos) != 1 || len(tx.Signatures) != 1 {
+		return fmt.Sprintf("%d signer(s) and %d signature(s), a gentx is signed by its validator alone", len(infos), len(tx.Signatures))
+	}
+	si := infos[0]
+	if si.PublicKey == nil || si.PublicKey.Key == "" {
+		return "auth_info.signer_infos[0] has no public_key"
+	}
+	if si.PublicKey.Type != secp256k1PubKey || si.ModeInfo.Single == nil || si.ModeInfo.Single.Mode != "SIGN_MODE_DIRECT" {
+		return ""
+	}
+
+	key, err := base64.StdEncoding.DecodeString(si.PublicKey.Key)
+	if err != nil {
+		return fmt.Sprintf("signer public key is not valid base64: %v", err)
+	}
+	pub, err := secp256k1.ParsePubKey(key)
+	if err != nil {
+		return fmt.Sprintf("signer public key: %v", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(tx.Signatures[0])
+	if err != nil || len(sig) != 64 {
+		return "signature is not 64 bytes of base64"
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(sig[:32]) || s.SetByteSlice(sig[32:]) {
+		return "signature is out of range"
+	}
+	if s.IsOverHalfOrder() {
+		return "signature is not in low-S form, which the chain rejects"
+	}
+
+	signature := ecdsa.NewSignature(&r, &s)
+	verify := func(chainID string) (bool, error) {
+		doc, err := signBytes(tx, chainID)
+		if err != nil {
+			return false, err
+		}
+		hash := sha256.Sum256(doc)
+		return signature.Verify(hash[:], pub), nil
+	}
+
+	ok, err := verify(chainID)
+	if err != nil {
+		return fmt.Sprintf("cannot rebuild the signed bytes: %v", err)
+	}
+	if ok {
+		return ""
+	}
+	for _, other := range others {
+		if other == chainID {
+			continue
+		}
+		if ok, _ := verify(other); ok {
+			return fmt.Sprintf("signed for chain-id %q, expected %q", other, chainID)
+		}
+	}
+	return fmt.Sprintf("signature does not verify for chain-id %q at account number 0; the gentx was modified after signing or signed for another chain", chainID)
+}
+
+// signBytes returns the SIGN_MODE_DIRECT sign bytes of tx for chainID: the
+// protobuf encoding of its SignDoc. Gentxs are signed at account number 0,
+// which is left out like every other zero value.
+func signBytes(tx *gentx, chainID string) ([]byte, error) {
+	var body []byte
+	for _, m := range tx.Body.Messages {
+		msg, err := encodeMsgCreateValidator(&m)
+		if err != nil {
+			return nil, err
+		}
+		body = appendMessage(body, 1, encodeAny(m.Type, msg))
+	}
+	body = appendString(body, 2, tx.Body.Memo)
+	if h := tx.Body.TimeoutHeight; h != "" {
+		height, err := strconv.ParseUint(h, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("body.timeout_height %q is not a number", h)
+		}
+		body = appendVarint(body, 3, height)
+	}
+
+	var authInfo []byte
+	for _, si := range tx.AuthInfo.SignerInfos {
+		var info []byte
+		if si.PublicKey != nil {
+			key, err := encodePubKey(si.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+			info = appendMessage(info, 1, key)
+		}
+		if si.ModeInfo.Single != nil {
+			mode, ok := signModes[si.ModeInfo.Single.Mode]
+			if !ok {
+				return nil, fmt.Errorf("unknown sign mode %s", si.ModeInfo.Single.Mode)
+			}
+			info = appendMessage(info, 2, appendMessage(nil, 1, appendVarint(nil, 1, mode)))
+		}
+		seq, err := strconv.ParseUint(si.Sequence, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sequence %q is not a number", si.Sequence)
+		}
+		authInfo = appendMessage(authInfo, 1, appendVarint(info, 3, seq))
+	}
+	fee := tx.AuthInfo.Fee
+	var feeBytes []byte
+	for _, c := range fee.Amount {
+		feeBytes = appendMessage(feeBytes, 1, encodeCoin(c))
+	}
+	gas, err := strconv.ParseUint(fee.GasLimit, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("auth_info.fee.gas_limit %q is not a number", fee.GasLimit)
+	}
+	feeBytes = appendVarint(feeBytes, 2, gas)
+	feeBytes = appendString(feeBytes, 3, fee.Payer)
+	feeBytes = appendString(feeBytes, 4, fee.Granter)
+	authInfo = appendMessage(authInfo, 2, feeBytes)
+
+	var doc []byte
+	doc = appendMessage(doc, 1, body)
+	doc = appendMessage(doc, 2, authInfo)
+	doc = appendString(doc, 3, chainID)
+	return doc, nil
+}
+
+// encodeMsgCreateValidator returns the protobuf encoding of m. Decimals and
+// integers are cosmos-sdk custom types, which are always encoded, as their
+// string form.
+func encodeMsgCreateValidator(m *message) ([]byte, error) {
+	d := m.Description
+	var desc []byte
+	for i, s := range []string{d.Moniker, d.Identity, d.Website, d.SecurityContact, d.Details} {
+		desc = appendString(desc, i+1, s)
+	}
+
+	var rates []byte
+	for i, s := range []string{m.Commission.Rate, m.Commission.MaxRate, m.Commission.MaxChangeRate} {
+		dec, err := legacyDec(s)
+		if err != nil {
+			return nil, fmt.Errorf("commission: %w", err)
+		}
+		rates = appendMessage(rates, i+1, []byte(dec))
+	}
+
+	var b []byte
+	b = appendMessage(b, 1, desc)
+	b = appendMessage(b, 2, rates)
+	b = appendMessage(b, 3, []byte(m.MinSelfDelegation))
+	b = appendString(b, 4, m.DelegatorAddress)
+	b = appendString(b, 5, m.ValidatorAddress)
+	if m.PubKey != nil {
+		key, err := encodePubKey(m.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		b = appendMessage(b, 6, key)
+	}
+	b = appendMessage(b, 7, encodeCoin(m.Value))
+	return b, nil
+}
+
+// encodePubKey returns the protobuf encoding of pk as an Any.
+func encodePubKey(pk *pubKey) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(pk.Key)
+	if err != nil {
+		return nil, fmt.Errorf("public key %q is not valid base64", pk.Key)
+	}
+	return encodeAny(pk.Type, appendString(nil, 1, string(key))), nil
+}
+
+func encodeAny(typeURL string, value []byte) []byte {
+	return appendString(appendString(nil, 1, typeURL), 2, string(value))
+}
+
+func encodeCoin(c coin) []byte {
+	return appendMessage(appendString(nil, 1, c.Denom), 2, []byte(c.Amount))
+}
+
+// legacyDec returns the protobuf form of a cosmos-sdk decimal: the integer
+// of its value times 10^18.
+func legacyDec(s string) (string, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > 18 {
+		return "", fmt.Errorf("decimal %q has more than 18 decimal places", s)
+	}
+	i, ok := new(big.Int).SetString(whole+frac+strings.Repeat("0", 18-len(frac)), 10)
+	if !ok {
+		return "", fmt.Errorf("invalid decimal %q", s)
+	}
+	return i.String(), nil
+}
+
+// appendMessage appends a length-delimited field, even when empty, as
+// non-nullable submessages and custom types are.
+func appendMessage(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendString appends a string or bytes field, left out when empty.
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendMessage(b, field, []byte(s))
+}
+
+// appendVarint appends a varint field, left out when zero.
+func appendVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, v)
+}
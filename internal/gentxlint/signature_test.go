@@ -0,0 +1,91 @@
+package gentxlint
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestCheckSignature(t *testing.T) {
+	data, err := os.ReadFile("../../testnets/alfama/gentx/gentx-validator-2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		chainID string
+		others  []string
+		edit    func(tx *gentx)
+		problem string // substring of the problem; "" when none is expected
+	}{
+		{"valid", "alfama", nil, func(tx *gentx) {}, ""},
+		{"valid with others", "alfama", []string{"alfama", "buenavista-1"}, func(tx *gentx) {}, ""},
+		{"other chain", "buenavista-1", nil, func(tx *gentx) {}, `does not verify for chain-id "buenavista-1"`},
+		{"other known chain", "buenavista-1", []string{"alfama", "buenavista-1"}, func(tx *gentx) {}, `signed for chain-id "alfama", expected "buenavista-1"`},
+		{"other unknown chain", "buenavista-1", []string{"barra_9191-1"}, func(tx *gentx) {}, `does not verify for chain-id "buenavista-1"`},
+		{"memo changed", "alfama", nil, func(tx *gentx) { tx.Body.Memo += "0" }, "does not verify"},
+		{"modified, other chains known", "alfama", []string{"buenavista-1"}, func(tx *gentx) { tx.Body.Memo += "0" }, "modified after signing"},
+		{"stake changed", "alfama", nil, func(tx *gentx) { tx.Body.Messages[0].Value.Amount += "0" }, "does not verify"},
+		{"commission changed", "alfama", nil, func(tx *gentx) { tx.Body.Messages[0].Commission.Rate = "0.5" }, "does not verify"},
+		{"gas changed", "alfama", nil, func(tx *gentx) { tx.AuthInfo.Fee.GasLimit = "300000" }, "does not verify"},
+		{"high s", "alfama", nil, func(tx *gentx) {
+			sig, _ := base64.StdEncoding.DecodeString(tx.Signatures[0])
+			var s secp256k1.ModNScalar
+			s.SetByteSlice(sig[32:])
+			b := s.Negate().Bytes()
+			tx.Signatures[0] = base64.StdEncoding.EncodeToString(append(sig[:32], b[:]...))
+		}, "low-S"},
+		{"short signature", "alfama", nil, func(tx *gentx) { tx.Signatures[0] = "AAEC" }, "not 64 bytes"},
+		{"two signatures", "alfama", nil, func(tx *gentx) { tx.Signatures = append(tx.Signatures, tx.Signatures[0]) }, "1 signer(s) and 2 signature(s)"},
+		{"no public key", "alfama", nil, func(tx *gentx) { tx.AuthInfo.SignerInfos[0].PublicKey = nil }, "no public_key"},
+		{"other key type", "alfama", nil, func(tx *gentx) {
+			tx.AuthInfo.SignerInfos[0].PublicKey.Type = "/ethermint.crypto.v1.ethsecp256k1.PubKey"
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tx gentx
+			if err := json.Unmarshal(data, &tx); err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(&tx)
+			got := checkSignature(&tx, tt.chainID, tt.others)
+			switch {
+			case tt.problem == "" && got != "":
+				t.Errorf("checkSignature() = %q, want no problem", got)
+			case tt.problem != "" && !strings.Contains(got, tt.problem):
+				t.Errorf("checkSignature() = %q, want it to contain %q", got, tt.problem)
+			}
+		})
+	}
+}
+
+func TestLegacyDec(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"0.100000000000000000", "100000000000000000"},
+		{"1.000000000000000000", "1000000000000000000"},
+		{"0.05", "50000000000000000"},
+		{"0", "0"},
+		{"0.0000000000000000001", ""},
+		{"ten", ""},
+	}
+	for _, tt := range tests {
+		got, err := legacyDec(tt.in)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("legacyDec(%q) = %q, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("legacyDec(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
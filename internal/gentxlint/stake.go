@@ -0,0 +1,44 @@
+package gentxlint
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// checkSelfDelegation returns what is wrong with the amount a gentx
+// self-delegates: it must be a positive integer, no less than the
+// min_self_delegation the validator declares, and within the launch policy
+// bounds min and max, which are not checked when nil.
+func checkSelfDelegation(msg *message, min, max *big.Int) []string {
+	amount, ok := new(big.Int).SetString(msg.Value.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return []string{fmt.Sprintf("self-delegation amount %q is not a positive integer", msg.Value.Amount)}
+	}
+
+	var problems []string
+	if declared, ok := new(big.Int).SetString(msg.MinSelfDelegation, 10); !ok || declared.Sign() <= 0 {
+		problems = append(problems, fmt.Sprintf("min_self_delegation %q is not a positive integer", msg.MinSelfDelegation))
+	} else if amount.Cmp(declared) < 0 {
+		problems = append(problems, fmt.Sprintf("self-delegation %s%s is below the validator's own min_self_delegation of %s", amount, msg.Value.Denom, declared))
+	}
+	if min != nil && amount.Cmp(min) < 0 {
+		problems = append(problems, fmt.Sprintf("self-delegation %s%s is below the minimum of %s%s", amount, msg.Value.Denom, min, msg.Value.Denom))
+	}
+	if max != nil && amount.Cmp(max) > 0 {
+		problems = append(problems, fmt.Sprintf("self-delegation %s%s is above the maximum of %s%s", amount, msg.Value.Denom, max, msg.Value.Denom))
+	}
+	return problems
+}
+
+// parseAmount parses the value of an amount flag, returning nil when it is
+// empty.
+func parseAmount(flag, s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("-%s %q: expected a non-negative integer amount in base units", flag, s)
+	}
+	return n, nil
+}
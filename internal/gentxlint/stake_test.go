@@ -0,0 +1,47 @@
+package gentxlint
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestCheckSelfDelegation(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		declared string
+		min, max int64 // 0 for no bound
+		want     []string
+	}{
+		{"no bounds", "1000", "1", 0, 0, nil},
+		{"within bounds", "1000", "1", 1000, 1000, nil},
+		{"below minimum", "999", "1", 1000, 0, []string{"self-delegation 999uward is below the minimum of 1000uward"}},
+		{"above maximum", "1001", "1", 0, 1000, []string{"self-delegation 1001uward is above the maximum of 1000uward"}},
+		{"below declared minimum", "10", "100", 0, 0, []string{"below the validator's own min_self_delegation of 100"}},
+		{"zero amount", "0", "1", 0, 0, []string{`self-delegation amount "0" is not a positive integer`}},
+		{"decimal amount", "1.5", "1", 0, 0, []string{`self-delegation amount "1.5" is not a positive integer`}},
+		{"bad declared minimum", "1000", "", 0, 0, []string{`min_self_delegation "" is not a positive integer`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &message{MinSelfDelegation: tt.declared, Value: coin{Denom: "uward", Amount: tt.amount}}
+			var min, max *big.Int
+			if tt.min != 0 {
+				min = big.NewInt(tt.min)
+			}
+			if tt.max != 0 {
+				max = big.NewInt(tt.max)
+			}
+			got := checkSelfDelegation(msg, min, max)
+			if len(got) != len(tt.want) {
+				t.Fatalf("checkSelfDelegation() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("checkSelfDelegation()[%d] = %q, want it to contain %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
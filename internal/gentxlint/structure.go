@@ -0,0 +1,103 @@
+package gentxlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// gentxFields are the fields a gentx may have, by JSON object. Anything
+// else is refused rather than ignored: a gentx is collected into the genesis
+// as is, so whatever an unknown field carries would reach the chain without
+// anyone reviewing it.
+var gentxFields = map[string][]string{
+	"":          {"body", "auth_info", "signatures"},
+	"body":      {"messages", "memo", "timeout_height", "extension_options", "non_critical_extension_options"},
+	"auth_info": {"signer_infos", "fee", "tip"},
+}
+
+// checkStructure returns what is wrong with the shape of the gentx data: a
+// gentx is a single MsgCreateValidator with no extra fields, extension
+// options or tip. Extra messages (a MsgSend smuggled into a genesis
+// transaction, say) would be executed at genesis with the validator's
+// signature.
+func checkStructure(data []byte) []string {
+	var problems []string
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return []string{fmt.Sprintf("decode: %v", err)}
+	}
+	unknown := unknownFields(top, "")
+	var body, authInfo map[string]json.RawMessage
+	for _, part := range []struct {
+		name string
+		dst  *map[string]json.RawMessage
+	}{{"body", &body}, {"auth_info", &authInfo}} {
+		if err := json.Unmarshal(top[part.name], part.dst); err != nil || *part.dst == nil {
+			report("%s is missing or not an object", part.name)
+			continue
+		}
+		unknown = append(unknown, unknownFields(*part.dst, part.name)...)
+	}
+	for _, f := range unknown {
+		report("unexpected field %s: refusing to collect data a gentx does not carry into the genesis", f)
+	}
+	if body == nil {
+		return problems
+	}
+
+	for _, opts := range []string{"extension_options", "non_critical_extension_options"} {
+		var list []json.RawMessage
+		if raw, ok := body[opts]; ok && (json.Unmarshal(raw, &list) != nil || len(list) > 0) {
+			report("body.%s is not empty: a gentx has no extension options", opts)
+		}
+	}
+	if raw, ok := authInfo["tip"]; ok && string(raw) != "null" {
+		report("auth_info.tip is set: a gentx pays no tip")
+	}
+
+	var messages []struct {
+		Type string `json:"@type"`
+	}
+	if err := json.Unmarshal(body["messages"], &messages); err != nil {
+		report("body.messages is not a list of messages")
+		return problems
+	}
+	creates := 0
+	for i, m := range messages {
+		if m.Type == msgCreateValidator {
+			creates++
+			continue
+		}
+		report("body.messages[%d] is a %s: a gentx may only create its validator, refusing to execute anything else at genesis", i, m.Type)
+	}
+	switch {
+	case len(messages) == 0:
+		report("body.messages is empty, expected a single MsgCreateValidator")
+	case creates > 1:
+		report("body.messages has %d MsgCreateValidator, expected a single one", creates)
+	}
+	return problems
+}
+
+// unknownFields returns the fields of obj, the object at path, that are not
+// in gentxFields, sorted.
+func unknownFields(obj map[string]json.RawMessage, path string) []string {
+	var unknown []string
+	for f := range obj {
+		known := false
+		for _, k := range gentxFields[path] {
+			known = known || f == k
+		}
+		if !known {
+			unknown = append(unknown, strings.TrimPrefix(path+"."+f, "."))
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,54 @@
+package gentxlint
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckStructure(t *testing.T) {
+	valid, err := os.ReadFile("../../testnets/alfama/gentx/gentx-validator-1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit := func(old, new string) string {
+		if !strings.Contains(string(valid), old) {
+			t.Fatalf("fixture does not contain %q", old)
+		}
+		return strings.Replace(string(valid), old, new, 1)
+	}
+	const send = `{"@type":"/cosmos.bank.v1beta1.MsgSend","from_address":"warden1x","to_address":"warden1y","amount":[]}`
+
+	tests := []struct {
+		name    string
+		data    string
+		problem string // substring of the first problem; "" when none is expected
+	}{
+		{"valid", string(valid), ""},
+		{"not json", "{", "decode"},
+		{"extra top-level field", edit(`{"body":`, `{"payload":"x","body":`), "unexpected field payload"},
+		{"extra body field", edit(`"memo":`, `"note":"x","memo":`), "unexpected field body.note"},
+		{"extra auth_info field", edit(`"tip":null`, `"tip":null,"extra":1`), "unexpected field auth_info.extra"},
+		{"no body", `{"auth_info":{},"signatures":[]}`, "body is missing"},
+		{"smuggled message", edit(`}],"memo"`, `},`+send+`],"memo"`), "body.messages[1] is a /cosmos.bank.v1beta1.MsgSend"},
+		{"only another message", edit(`"messages":[`, `"messages":[`+send+`,`), "body.messages[0] is a /cosmos.bank.v1beta1.MsgSend"},
+		{"no messages", `{"body":{"messages":[]},"auth_info":{}}`, "body.messages is empty"},
+		{"two validators", `{"body":{"messages":[{"@type":"` + msgCreateValidator + `"},{"@type":"` + msgCreateValidator + `"}]},"auth_info":{}}`, "2 MsgCreateValidator"},
+		{"extension option", edit(`"extension_options":[]`, `"extension_options":[{}]`), "body.extension_options is not empty"},
+		{"tip", edit(`"tip":null`, `"tip":{"amount":[],"tipper":"warden1x"}`), "auth_info.tip is set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := checkStructure([]byte(tt.data))
+			if tt.problem == "" {
+				if len(problems) > 0 {
+					t.Fatalf("checkStructure problems = %q", problems)
+				}
+				return
+			}
+			if len(problems) == 0 || !strings.Contains(problems[0], tt.problem) {
+				t.Fatalf("checkStructure problems = %q, want one containing %q", problems, tt.problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package gentxlint
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// checkTx returns what is wrong with the transaction fields of tx.
+//
+// Every signer must sign with SIGN_MODE_DIRECT at sequence 0: gentxs signed
+// otherwise, for example with legacy amino, pass collect-gentxs but fail
+// when the chain starts. The account number is part of the signed bytes
+// only, so a wrong one shows up as a signature failure instead.
+//
+// The fee's gas limit must be within MinGas and MaxGas: below, the
+// MsgCreateValidator runs out of gas at genesis; above, the limit is far
+// more than a MsgCreateValidator uses and most likely a typo.
+//
+// The self-delegation must be in the network's staking denom. Every fee
+// coin must be in a denom of the fee policy, or the staking denom without
+// one, listed once with a positive integer amount. When the policy sets
+// minimum gas prices, the fee must pay at least gas_limit times the minimum
+// gas price in one of its denoms, as a node's minimum-gas-prices would
+// require.
+func checkTx(tx *gentx, opts Options) []string {
+	var problems []string
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if len(tx.AuthInfo.SignerInfos) == 0 {
+		report("auth_info.signer_infos is empty")
+	}
+	for j, si := range tx.AuthInfo.SignerInfos {
+		if si.Sequence != "0" {
+			report("auth_info.signer_infos[%d].sequence is %q, gentxs are signed at sequence 0", j, si.Sequence)
+		}
+		switch {
+		case si.ModeInfo.Single == nil:
+			report("auth_info.signer_infos[%d] is not a single signer", j)
+		case si.ModeInfo.Single.Mode != "SIGN_MODE_DIRECT":
+			report("auth_info.signer_infos[%d].mode_info.single.mode is %s, expected SIGN_MODE_DIRECT", j, si.ModeInfo.Single.Mode)
+		}
+	}
+
+	if opts.Denom != "" {
+		if d := tx.Body.Messages[0].Value.Denom; d != opts.Denom {
+			report("body.messages[0].value.denom is %q, expected %q", d, opts.Denom)
+		}
+	}
+
+	accepted := map[string]bool{}
+	for _, t := range opts.Fees {
+		accepted[t.Denom] = true
+	}
+	if len(accepted) == 0 && opts.Denom != "" {
+		accepted[opts.Denom] = true
+	}
+	fee := map[string]*big.Int{}
+	for j, c := range tx.AuthInfo.Fee.Amount {
+		amount, ok := new(big.Int).SetString(c.Amount, 10)
+		switch {
+		case len(accepted) > 0 && !accepted[c.Denom]:
+			report("auth_info.fee.amount[%d].denom is %q, expected %s", j, c.Denom, oneOf(sortedKeys(accepted)))
+		case fee[c.Denom] != nil:
+			report("auth_info.fee.amount[%d]: denom %q is listed twice", j, c.Denom)
+		case !ok || amount.Sign() <= 0:
+			report("auth_info.fee.amount[%d].amount %q is not a positive integer", j, c.Amount)
+		default:
+			fee[c.Denom] = amount
+			continue
+		}
+		if fee[c.Denom] == nil {
+			fee[c.Denom] = new(big.Int)
+		}
+	}
+
+	gas, err := strconv.ParseUint(tx.AuthInfo.Fee.GasLimit, 10, 64)
+	switch {
+	case errors.Is(err, strconv.ErrRange):
+		report("auth_info.fee.gas_limit %s does not fit in 64 bits", tx.AuthInfo.Fee.GasLimit)
+		return problems
+	case err != nil:
+		// gentx.schema.json rejects a gas_limit that is not a uint.
+		return problems
+	case gas < opts.MinGas:
+		report("auth_info.fee.gas_limit %d is below %d, the MsgCreateValidator would run out of gas", gas, opts.MinGas)
+	case opts.MaxGas > 0 && gas > opts.MaxGas:
+		report("auth_info.fee.gas_limit %d is above %d", gas, opts.MaxGas)
+	}
+	var mins []string
+	for _, t := range opts.Fees {
+		if t.MinGasPrice <= 0 {
+			return problems
+		}
+		price, _ := new(big.Rat).SetString(strconv.FormatFloat(t.MinGasPrice, 'f', -1, 64))
+		min := price.Mul(price, new(big.Rat).SetInt(new(big.Int).SetUint64(gas)))
+		// Round up, as the node compares the fee to the exact product.
+		need, rem := new(big.Int).QuoRem(min.Num(), min.Denom(), new(big.Int))
+		if rem.Sign() > 0 {
+			need.Add(need, big.NewInt(1))
+		}
+		if paid := fee[t.Denom]; paid != nil && paid.Cmp(need) >= 0 {
+			return problems
+		}
+		mins = append(mins, need.String()+t.Denom)
+	}
+	if len(mins) > 0 {
+		report("auth_info.fee pays %s for gas_limit %d, expected at least %s", formatCoins(tx.AuthInfo.Fee.Amount), gas, strings.Join(mins, " or "))
+	}
+	return problems
+}
+
+// oneOf formats a list of choices for a problem.
+func oneOf(choices []string) string {
+	if len(choices) == 1 {
+		return strconv.Quote(choices[0])
+	}
+	quoted := make([]string, len(choices))
+	for i, c := range choices {
+		quoted[i] = strconv.Quote(c)
+	}
+	return "one of " + strings.Join(quoted, ", ")
+}
+
+func formatCoins(coins []coin) string {
+	if len(coins) == 0 {
+		return "nothing"
+	}
+	s := make([]string, len(coins))
+	for i, c := range coins {
+		s[i] = c.Amount + c.Denom
+	}
+	return strings.Join(s, ",")
+}
+
+// parseGasPrices parses a minimum-gas-prices value such as
+// "0.0025uward,1award".
+func parseGasPrices(s string) ([]networks.FeeToken, error) {
+	var tokens []networks.FeeToken
+	for _, p := range network.SplitList(s) {
+		i := strings.IndexFunc(p, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return nil, fmt.Errorf("%q: expected an amount followed by a denom", p)
+		}
+		price, err := strconv.ParseFloat(p[:i], 64)
+		if err != nil || !decimalRE.MatchString(p[:i]) {
+			return nil, fmt.Errorf("%q: invalid amount %q", p, p[:i])
+		}
+		tokens = append(tokens, networks.FeeToken{Denom: p[i:], MinGasPrice: price})
+	}
+	return tokens, nil
+}
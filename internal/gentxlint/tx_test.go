@@ -0,0 +1,147 @@
+package gentxlint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+func TestCheckTx(t *testing.T) {
+	uward := coin{Denom: "uward", Amount: "1000"}
+	policy := []networks.FeeToken{{Denom: "uward", MinGasPrice: 0.0025}, {Denom: "award", MinGasPrice: 2500000000}}
+
+	tests := []struct {
+		name  string
+		opts  Options
+		value coin
+		fee   []coin
+		gas   string
+		want  []string
+	}{
+		{"valid", Options{Denom: "uward"}, uward, []coin{{"uward", "10"}}, "", nil},
+		{"no fee", Options{Denom: "uward"}, uward, nil, "", nil},
+		{"no denom known", Options{}, coin{"uatom", "1"}, []coin{{"uatom", "1"}}, "", nil},
+		{"wrong self-delegation denom", Options{Denom: "uward"}, coin{"award", "1"}, nil, "",
+			[]string{`body.messages[0].value.denom is "award", expected "uward"`}},
+		{"wrong fee denom", Options{Denom: "uward"}, uward, []coin{{"uward", "1"}, {"uatom", "1"}}, "",
+			[]string{`auth_info.fee.amount[1].denom is "uatom", expected "uward"`}},
+		{"fee denom listed twice", Options{Denom: "uward"}, uward, []coin{{"uward", "1"}, {"uward", "2"}}, "",
+			[]string{`auth_info.fee.amount[1]: denom "uward" is listed twice`}},
+		{"zero fee amount", Options{Denom: "uward"}, uward, []coin{{"uward", "0"}}, "",
+			[]string{`auth_info.fee.amount[0].amount "0" is not a positive integer`}},
+		{"fee pays the minimum", Options{Denom: "uward", Fees: policy}, uward, []coin{{"uward", "500"}}, "200000", nil},
+		{"fee pays the minimum in another denom", Options{Denom: "uward", Fees: policy}, uward, []coin{{"award", "500000000000000"}}, "200000", nil},
+		{"fee rounds up", Options{Fees: []networks.FeeToken{{Denom: "uward", MinGasPrice: 0.0025}}}, uward, []coin{{"uward", "1"}}, "1", nil},
+		{"fee below the minimum", Options{Denom: "uward", Fees: policy}, uward, []coin{{"uward", "499"}}, "200000",
+			[]string{"auth_info.fee pays 499uward for gas_limit 200000, expected at least 500uward or 500000000000000award"}},
+		{"no fee with a minimum", Options{Denom: "uward", Fees: policy}, uward, nil, "200000",
+			[]string{"auth_info.fee pays nothing for gas_limit 200000, expected at least 500uward or 500000000000000award"}},
+		{"every fee coin is checked", Options{Denom: "uward", Fees: policy}, uward, []coin{{"uward", "500"}, {"uatom", "1"}}, "200000",
+			[]string{`auth_info.fee.amount[1].denom is "uatom", expected one of "award", "uward"`}},
+		{"free denom", Options{Fees: []networks.FeeToken{{Denom: "uward"}}}, uward, nil, "200000", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &gentx{}
+			tx.Body.Messages = []message{{Value: tt.value}}
+			tx.AuthInfo.SignerInfos = []signerInfo{directSigner("0")}
+			tx.AuthInfo.Fee.Amount, tx.AuthInfo.Fee.GasLimit = tt.fee, tt.gas
+			got := checkTx(tx, tt.opts)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("checkTx() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// directSigner returns a signer info signing with SIGN_MODE_DIRECT at
+// sequence.
+func directSigner(sequence string) signerInfo {
+	var si signerInfo
+	si.ModeInfo.Single = &singleMode{Mode: "SIGN_MODE_DIRECT"}
+	si.Sequence = sequence
+	return si
+}
+
+func TestCheckTxGasLimit(t *testing.T) {
+	bounds := Options{MinGas: 200_000, MaxGas: 10_000_000}
+
+	tests := []struct {
+		name string
+		opts Options
+		gas  string
+		want []string
+	}{
+		{"within bounds", bounds, "200000", nil},
+		{"at the upper bound", bounds, "10000000", nil},
+		{"below the lower bound", bounds, "199999",
+			[]string{"auth_info.fee.gas_limit 199999 is below 200000, the MsgCreateValidator would run out of gas"}},
+		{"zero", bounds, "0",
+			[]string{"auth_info.fee.gas_limit 0 is below 200000, the MsgCreateValidator would run out of gas"}},
+		{"above the upper bound", bounds, "10000001", []string{"auth_info.fee.gas_limit 10000001 is above 10000000"}},
+		{"no upper bound", Options{MinGas: 200_000}, "18446744073709551615", nil},
+		{"out of range", Options{}, "18446744073709551616", []string{"auth_info.fee.gas_limit 18446744073709551616 does not fit in 64 bits"}},
+		{"not a number", bounds, "lots", nil}, // reported by checkSchema
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &gentx{}
+			tx.Body.Messages = []message{{}}
+			tx.AuthInfo.SignerInfos = []signerInfo{directSigner("0")}
+			tx.AuthInfo.Fee.GasLimit = tt.gas
+			got := checkTx(tx, tt.opts)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("checkTx() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTxSignerInfos(t *testing.T) {
+	amino := directSigner("0")
+	amino.ModeInfo.Single = &singleMode{Mode: "SIGN_MODE_LEGACY_AMINO_JSON"}
+	multi := directSigner("0")
+	multi.ModeInfo.Single = nil
+
+	tests := []struct {
+		name    string
+		signers []signerInfo
+		want    []string
+	}{
+		{"valid", []signerInfo{directSigner("0")}, nil},
+		{"no signer", nil, []string{"auth_info.signer_infos is empty"}},
+		{"non-zero sequence", []signerInfo{directSigner("1")},
+			[]string{`auth_info.signer_infos[0].sequence is "1", gentxs are signed at sequence 0`}},
+		{"legacy amino", []signerInfo{amino},
+			[]string{"auth_info.signer_infos[0].mode_info.single.mode is SIGN_MODE_LEGACY_AMINO_JSON, expected SIGN_MODE_DIRECT"}},
+		{"not a single signer", []signerInfo{multi}, []string{"auth_info.signer_infos[0] is not a single signer"}},
+		{"every signer is checked", []signerInfo{directSigner("0"), amino, directSigner("7")}, []string{
+			"auth_info.signer_infos[1].mode_info.single.mode is SIGN_MODE_LEGACY_AMINO_JSON, expected SIGN_MODE_DIRECT",
+			`auth_info.signer_infos[2].sequence is "7", gentxs are signed at sequence 0`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &gentx{}
+			tx.Body.Messages = []message{{}}
+			tx.AuthInfo.SignerInfos = tt.signers
+			got := checkTx(tx, Options{})
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("checkTx() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGasPrices(t *testing.T) {
+	got, err := parseGasPrices("0.0025uward, 1award")
+	if err != nil || len(got) != 2 || got[0] != (networks.FeeToken{Denom: "uward", MinGasPrice: 0.0025}) || got[1] != (networks.FeeToken{Denom: "award", MinGasPrice: 1}) {
+		t.Errorf("parseGasPrices() = %v, %v", got, err)
+	}
+	for _, s := range []string{"uward", "0.1", "1.uward", "-1uward"} {
+		if _, err := parseGasPrices(s); err == nil {
+			t.Errorf("parseGasPrices(%q) succeeded, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package haltdetector implements the halt-detector command, a liveness
+// watchdog for the networks in this repo.
+//
+// Every poll queries /status on the published RPC endpoints of each network
+// and takes the highest height reported. A network is reported as halted when
+// that height has not advanced for -stall, and as diverging when reachable
+// endpoints disagree by more than -max-spread blocks. The last height and
+// the time it was first seen are kept in a -state file, so the command can
+// run once per cron invocation as well as in a loop with -interval. The
+// state of a network starts over when its chain-id changes or its height
+// goes down, as happens when a testnet is relaunched.
+//
+// While any network is alerting the command exits non-zero. With -webhook,
+// a JSON notification is POSTed when a network's alerts change, so a
+// cron-driven watchdog does not page on every run.
+//
+// Usage:
+//
+//	halt-detector -stall 5m -webhook https://hooks.example.com/warden alfama
+//	halt-detector -interval 30s -state /var/lib/halt-detector.json
+package haltdetector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/healthcheck"
+	"github.com/warden-protocol/networks/internal/network"
+)
+
+// Status is the liveness of one network at one poll.
+type Status struct {
+	Network   string    `json:"network"`
+	ChainID   string    `json:"chain_id"`
+	Height    int64     `json:"height"`
+	Since     time.Time `json:"since"`
+	Reachable int       `json:"reachable"`
+	Endpoints int       `json:"endpoints"`
+	Spread    int64     `json:"spread"`
+	Alerts    []string  `json:"alerts"`
+}
+
+// state is the on-disk state, by network name.
+type state map[string]*netState
+
+type netState struct {
+	ChainID string    `json:"chain_id,omitempty"`
+	Height  int64     `json:"height"`
+	Since   time.Time `json:"since"`
+	Alerts  []string  `json:"alerts,omitempty"`
+}
+
+// Command is the halt-detector command.
+var Command = &cli.Command{
+	Name:  "halt-detector",
+	Args:  "[network...]",
+	Short: "Alert when a network's block height stops advancing or its RPC endpoints disagree.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format    = cli.FormatFlag(fs, "text", "json")
+			stall     = fs.Duration("stall", 5*time.Minute, "alert when the height has not advanced for this long")
+			maxSpread = fs.Int64("max-spread", 20, "alert when reachable endpoints disagree by more than this many blocks")
+			statePath = fs.String("state", "halt-state.json", "JSON state file keeping the last height of each network")
+			interval  = fs.Duration("interval", 0, "keep polling at this interval instead of running once")
+			webhook   = fs.String("webhook", "", "URL to POST a JSON notification to when a network's alerts change")
+			timeout   = fs.Duration("timeout", 10*time.Second, "timeout for each request")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			networks, err := network.LoadAll(env.Root, args)
+			if err != nil {
+				return err
+			}
+			for _, n := range networks {
+				n.REST, n.GRPC, n.EVM = nil, nil, nil
+			}
+
+			st, err := readState(*statePath)
+			if err != nil {
+				return err
+			}
+			d := &Detector{
+				Prober:    &healthcheck.Prober{Client: &http.Client{Timeout: *timeout}, Timeout: *timeout, MaxLag: *maxSpread},
+				Stall:     *stall,
+				MaxSpread: *maxSpread,
+			}
+			client := &http.Client{Timeout: *timeout}
+
+			for {
+				statuses := d.Poll(ctx, networks, st, time.Now())
+				if ctx.Err() != nil {
+					// Interrupted mid-poll: the endpoints were not really
+					// unreachable, so neither alert nor save that.
+					return nil
+				}
+				if format.JSON() {
+					err = cli.WriteJSON(env.Stdout, statuses)
+				} else {
+					err = writeTable(env.Stdout, statuses)
+				}
+				if err != nil {
+					return err
+				}
+
+				alerting := 0
+				for _, s := range statuses {
+					if len(s.Alerts) > 0 {
+						alerting++
+					}
+					prev := st[s.Network].Alerts
+					if *webhook != "" && !slices.Equal(prev, s.Alerts) {
+						// Alerts are only recorded once delivered, so a
+						// failed notification is retried on the next poll.
+						if err := notify(ctx, client, *webhook, s); err != nil {
+							fmt.Fprintf(env.Stderr, "webhook: %v\n", err)
+							continue
+						}
+					}
+					st[s.Network].Alerts = s.Alerts
+				}
+				if err := writeState(*statePath, st); err != nil {
+					return err
+				}
+
+				if *interval <= 0 {
+					if alerting > 0 {
+						return fmt.Errorf("%d of %d network(s) alerting", alerting, len(statuses))
+					}
+					return nil
+				}
+				select {
+				case <-time.After(*interval):
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	},
+}
+
+// Detector polls the RPC endpoints of networks and raises alerts.
+type Detector struct {
+	Prober *healthcheck.Prober
+	// Stall is how long the height may stay the same before the network is
+	// reported as halted.
+	Stall time.Duration
+	// MaxSpread is the number of blocks reachable endpoints may disagree by.
+	MaxSpread int64
+}
+
+// Poll probes networks once and updates st with the heights seen at now.
+// Alerts already raised are left in st for the caller to compare against,
+// also when the rest of a network's state is reset after a relaunch.
+func (d *Detector) Poll(ctx context.Context, networks []*network.Network, st state, now time.Time) []*Status {
+	results := d.Prober.Probe(ctx, networks)
+
+	statuses := make([]*Status, 0, len(networks))
+	for _, n := range networks {
+		s := &Status{Network: n.Name, ChainID: n.ChainID, Endpoints: len(n.RPC), Alerts: []string{}}
+		lowest := int64(-1)
+		for _, r := range results {
+			if r.Network != n.Name || r.Failed() || r.Height == 0 {
+				continue
+			}
+			s.Reachable++
+			s.Height = max(s.Height, r.Height)
+			if lowest < 0 || r.Height < lowest {
+				lowest = r.Height
+			}
+		}
+		if lowest >= 0 {
+			s.Spread = s.Height - lowest
+		}
+
+		prev := st[n.Name]
+		if prev == nil {
+			prev = &netState{}
+			st[n.Name] = prev
+		}
+		if prev.ChainID != n.ChainID || (s.Reachable > 0 && s.Height < prev.Height) {
+			*prev = netState{ChainID: n.ChainID, Alerts: prev.Alerts}
+		}
+		if s.Height > prev.Height {
+			prev.Height, prev.Since = s.Height, now.UTC().Truncate(time.Second)
+		}
+		s.Since = prev.Since
+
+		switch {
+		case s.Endpoints == 0:
+			s.Alerts = append(s.Alerts, "no RPC endpoints published")
+		case s.Reachable == 0:
+			s.Alerts = append(s.Alerts, fmt.Sprintf("none of %d RPC endpoint(s) reachable", s.Endpoints))
+		}
+		if !prev.Since.IsZero() {
+			// The alert text only changes with the height, so repeated
+			// runs do not notify again while the network stays halted.
+			if now.Sub(prev.Since) >= d.Stall {
+				s.Alerts = append(s.Alerts, fmt.Sprintf("height %d has not advanced since %s", prev.Height, prev.Since.Format(time.RFC3339)))
+			}
+		}
+		if s.Spread > d.MaxSpread {
+			s.Alerts = append(s.Alerts, fmt.Sprintf("endpoints disagree by %d blocks", s.Spread))
+		}
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Network < statuses[j].Network })
+	return statuses
+}
+
+// notify POSTs s to url. An empty Alerts list means the network recovered.
+func notify(ctx context.Context, client *http.Client, url string, s *Status) error {
+	text := fmt.Sprintf("%s (%s) recovered at height %d", s.Network, s.ChainID, s.Height)
+	if len(s.Alerts) > 0 {
+		text = fmt.Sprintf("%s (%s): %s", s.Network, s.ChainID, strings.Join(s.Alerts, "; "))
+	}
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+		*Status
+	}{text, s})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, statuses []*Status) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NETWORK\tHEIGHT\tSINCE\tREACHABLE\tSPREAD\tSTATUS")
+	for _, s := range statuses {
+		status := "ok"
+		if len(s.Alerts) > 0 {
+			status = "ALERT: " + strings.Join(s.Alerts, "; ")
+		}
+		since := "-"
+		if !s.Since.IsZero() {
+			since = s.Since.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d/%d\t%d\t%s\n", s.Network, s.Height, since, s.Reachable, s.Endpoints, s.Spread, status)
+	}
+	return tw.Flush()
+}
+
+func readState(path string) (state, error) {
+	st := state{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// writeState replaces the state file atomically.
+func writeState(path string, st state) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := cli.WriteJSON(f, st); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,197 @@
+package haltdetector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/healthcheck"
+	"github.com/warden-protocol/networks/internal/network"
+)
+
+func TestPoll(t *testing.T) {
+	// The server answers /<i>/status with heights[i], or fails when it is 0.
+	var heights []int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/status"))
+		if err != nil || i >= len(heights) || heights[i] == 0 {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		fmt.Fprintf(w, `{"result":{"node_info":{"network":"alfama"},"sync_info":{"latest_block_height":"%d"}}}`, heights[i])
+	}))
+	defer srv.Close()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+
+	tests := []struct {
+		name    string
+		prev    *netState
+		heights []int64
+		height  int64     // height kept in the state
+		since   time.Time // since kept in the state
+		alerts  []string  // substrings of the expected alerts, in order
+	}{
+		{
+			name:    "first poll",
+			heights: []int64{10},
+			height:  10,
+			since:   now,
+		},
+		{
+			name:    "advancing",
+			prev:    &netState{ChainID: "alfama", Height: 100, Since: ago(10 * time.Minute)},
+			heights: []int64{105, 104},
+			height:  105,
+			since:   now,
+		},
+		{
+			name:    "stalled",
+			prev:    &netState{ChainID: "alfama", Height: 105, Since: ago(10 * time.Minute)},
+			heights: []int64{105, 105},
+			height:  105,
+			since:   ago(10 * time.Minute),
+			alerts:  []string{"height 105 has not advanced"},
+		},
+		{
+			name:    "not stalled yet",
+			prev:    &netState{ChainID: "alfama", Height: 105, Since: ago(time.Minute)},
+			heights: []int64{105},
+			height:  105,
+			since:   ago(time.Minute),
+		},
+		{
+			name:    "spread",
+			prev:    &netState{ChainID: "alfama", Height: 100, Since: ago(time.Minute)},
+			heights: []int64{200, 150},
+			height:  200,
+			since:   now,
+			alerts:  []string{"endpoints disagree by 50 blocks"},
+		},
+		{
+			name:    "unreachable",
+			prev:    &netState{ChainID: "alfama", Height: 100, Since: ago(time.Minute)},
+			heights: []int64{0, 0},
+			height:  100,
+			since:   ago(time.Minute),
+			alerts:  []string{"none of 2 RPC endpoint(s) reachable"},
+		},
+		{
+			name:    "chain-id changed",
+			prev:    &netState{ChainID: "buenavista", Height: 500, Since: ago(time.Hour)},
+			heights: []int64{3},
+			height:  3,
+			since:   now,
+		},
+		{
+			name:    "height decreased",
+			prev:    &netState{ChainID: "alfama", Height: 500, Since: ago(time.Hour)},
+			heights: []int64{3, 2},
+			height:  3,
+			since:   now,
+		},
+		{
+			name:   "no endpoints",
+			prev:   &netState{ChainID: "alfama", Height: 100, Since: ago(time.Minute)},
+			height: 100,
+			since:  ago(time.Minute),
+			alerts: []string{"no RPC endpoints published"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heights = tt.heights
+			n := &network.Network{Name: "alfama", ChainID: "alfama"}
+			for i := range tt.heights {
+				n.RPC = append(n.RPC, fmt.Sprintf("%s/%d", srv.URL, i))
+			}
+			st := state{}
+			if tt.prev != nil {
+				prev := *tt.prev
+				prev.Alerts = []string{"previous"}
+				st[n.Name] = &prev
+			}
+
+			d := &Detector{
+				Prober:    &healthcheck.Prober{Client: srv.Client(), Timeout: time.Second, MaxLag: 20},
+				Stall:     5 * time.Minute,
+				MaxSpread: 20,
+			}
+			statuses := d.Poll(context.Background(), []*network.Network{n}, st, now)
+			if len(statuses) != 1 {
+				t.Fatalf("Poll returned %d statuses, want 1", len(statuses))
+			}
+			s := statuses[0]
+
+			if len(s.Alerts) != len(tt.alerts) {
+				t.Fatalf("alerts = %q, want %q", s.Alerts, tt.alerts)
+			}
+			for i, a := range tt.alerts {
+				if !strings.Contains(s.Alerts[i], a) {
+					t.Errorf("alerts[%d] = %q, want it to contain %q", i, s.Alerts[i], a)
+				}
+			}
+
+			got := st[n.Name]
+			if got.ChainID != "alfama" || got.Height != tt.height || !got.Since.Equal(tt.since) {
+				t.Errorf("state = %s %d since %s, want alfama %d since %s", got.ChainID, got.Height, got.Since, tt.height, tt.since)
+			}
+			if !s.Since.Equal(tt.since) {
+				t.Errorf("Status.Since = %s, want %s", s.Since, tt.since)
+			}
+			// Alerts are compared against by the caller, so Poll keeps them.
+			if tt.prev != nil && !slices.Equal(got.Alerts, []string{"previous"}) {
+				t.Errorf("state alerts = %q, want the previous ones", got.Alerts)
+			}
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var got struct {
+		Text   string   `json:"text"`
+		Height int64    `json:"height"`
+		Alerts []string `json:"alerts"`
+	}
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		alerts []string
+		status int
+		text   string
+		err    string
+	}{
+		{"alerting", []string{"height 105 has not advanced", "endpoints disagree by 50 blocks"}, http.StatusOK, "alfama (alfama): height 105 has not advanced; endpoints disagree by 50 blocks", ""},
+		{"recovered", []string{}, http.StatusNoContent, "alfama (alfama) recovered at height 105", ""},
+		{"rejected", []string{"x"}, http.StatusInternalServerError, "alfama (alfama): x", "500 Internal Server Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status = tt.status
+			s := &Status{Network: "alfama", ChainID: "alfama", Height: 105, Alerts: tt.alerts}
+			err := notify(context.Background(), srv.Client(), srv.URL, s)
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("notify() error = %v, want %q", err, tt.err)
+			}
+			if got.Text != tt.text || got.Height != 105 || !slices.Equal(got.Alerts, tt.alerts) {
+				t.Errorf("posted %+v, want text %q", got, tt.text)
+			}
+		})
+	}
+}
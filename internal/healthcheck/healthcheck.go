@@ -0,0 +1,480 @@
+// Package healthcheck implements the rpc-healthcheck command, which probes
+// the public endpoints published in this repo.
+//
+// For every network it checks the CometBFT RPC (/status), REST
+// (cosmos/base/tendermint node_info, latest block and syncing), EVM JSON-RPC
+// (eth_chainId, eth_blockNumber, eth_syncing) and gRPC endpoints, and
+// reports latency, latest height, chain-id match, catching-up state and TLS
+// certificate expiry. gRPC endpoints are only checked for TLS/TCP
+// reachability, as querying them needs the chain's protobuf definitions.
+//
+// The command exits non-zero when any endpoint is down or serves another
+// chain; lagging endpoints and certificates close to expiry are reported as
+// warnings.
+//
+// Usage:
+//
+//	rpc-healthcheck                 # every network in the repo
+//	rpc-healthcheck -format json alfama buenavista
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// Endpoint kinds.
+const (
+	KindRPC  = "rpc"
+	KindREST = "rest"
+	KindGRPC = "grpc"
+	KindEVM  = "evm"
+)
+
+// Statuses, from best to worst.
+const (
+	StatusOK         = "ok"
+	StatusWarn       = "warn"
+	StatusWrongChain = "wrong-chain"
+	StatusDown       = "down"
+)
+
+// Result is the outcome of probing one endpoint.
+type Result struct {
+	Network    string        `json:"network"`
+	Kind       string        `json:"kind"`
+	Endpoint   string        `json:"endpoint"`
+	Status     string        `json:"status"`
+	ChainID    string        `json:"chain_id,omitempty"`
+	Height     int64         `json:"height,omitempty"`
+	CatchingUp bool          `json:"catching_up,omitempty"`
+	Latency    time.Duration `json:"latency_ns"`
+	// Calls is the latency of each request the probe made, in order; a
+	// probe makes one to three requests depending on its kind.
+	Calls     []time.Duration `json:"call_latencies_ns,omitempty"`
+	TLSExpiry *time.Time      `json:"tls_expiry,omitempty"`
+	Detail    string          `json:"detail,omitempty"`
+}
+
+// Failed reports whether r should fail the run.
+func (r *Result) Failed() bool {
+	return r.Status == StatusDown || r.Status == StatusWrongChain
+}
+
+// Command is the rpc-healthcheck command.
+var Command = &cli.Command{
+	Name:  "rpc-healthcheck",
+	Args:  "[network...]",
+	Short: "Probe the published RPC, REST, gRPC and EVM endpoints and report their health.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format  = cli.FormatFlag(fs, "text", "json")
+			timeout = fs.Duration("timeout", 10*time.Second, "timeout for each probe")
+			maxLag  = fs.Int64("max-lag", 50, "blocks an endpoint may lag behind the highest one before it is reported")
+			tlsWarn = fs.Duration("tls-warn", 14*24*time.Hour, "report certificates expiring within this duration")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			networks, err := network.LoadAll(env.Root, args)
+			if err != nil {
+				return err
+			}
+
+			p := &Prober{
+				Client:  &http.Client{Timeout: *timeout},
+				Timeout: *timeout,
+				MaxLag:  *maxLag,
+				TLSWarn: *tlsWarn,
+			}
+			results := p.Probe(ctx, networks)
+
+			if format.JSON() {
+				err = cli.WriteJSON(env.Stdout, results)
+			} else {
+				err = WriteTable(env.Stdout, results)
+			}
+			if err != nil {
+				return err
+			}
+
+			var failed int
+			for _, r := range results {
+				if r.Failed() {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d endpoints are down or on the wrong chain", failed, len(results))
+			}
+			return nil
+		}
+	},
+}
+
+// Prober probes endpoints.
+type Prober struct {
+	Client  *http.Client
+	Timeout time.Duration
+	// MaxLag is the number of blocks an endpoint may be behind the highest
+	// endpoint of the same network before it is reported.
+	MaxLag int64
+	// TLSWarn reports certificates expiring within this duration.
+	TLSWarn time.Duration
+}
+
+// Probe checks every endpoint of networks concurrently. Results are sorted
+// by network, kind and endpoint.
+func (p *Prober) Probe(ctx context.Context, networks []*network.Network) []*Result {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []*Result
+	)
+	for _, n := range networks {
+		for kind, eps := range map[string][]string{KindRPC: n.RPC, KindREST: n.REST, KindGRPC: n.GRPC, KindEVM: n.EVM} {
+			for _, ep := range eps {
+				wg.Add(1)
+				go func(n *network.Network, kind, ep string) {
+					defer wg.Done()
+					r := p.probe(ctx, n, kind, ep)
+					mu.Lock()
+					results = append(results, r)
+					mu.Unlock()
+				}(n, kind, ep)
+			}
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Endpoint < b.Endpoint
+	})
+	p.markLagging(results)
+	return results
+}
+
+func (p *Prober) probe(ctx context.Context, n *network.Network, kind, ep string) *Result {
+	r := &Result{Network: n.Name, Kind: kind, Endpoint: ep, Status: StatusOK}
+
+	start := time.Now()
+	var err error
+	switch kind {
+	case KindRPC:
+		err = p.probeRPC(ctx, r)
+	case KindREST:
+		err = p.probeREST(ctx, r)
+	case KindGRPC:
+		err = p.probeGRPC(ctx, r)
+	case KindEVM:
+		err = p.probeEVM(ctx, r)
+	}
+	r.Latency = time.Since(start)
+
+	if err != nil {
+		r.Status = StatusDown
+		r.Detail = err.Error()
+		return r
+	}
+
+	switch {
+	case kind == KindEVM:
+		if want, ok := networks.EVMChainID(n.ChainID); ok && r.ChainID != strconv.FormatUint(want, 10) {
+			r.Status = StatusWrongChain
+			r.Detail = fmt.Sprintf("EVM chain ID %s, expected %d", r.ChainID, want)
+		}
+	case r.ChainID != "" && n.ChainID != "" && r.ChainID != n.ChainID:
+		r.Status = StatusWrongChain
+		r.Detail = fmt.Sprintf("chain-id %s, expected %s", r.ChainID, n.ChainID)
+	}
+	if r.Status != StatusOK {
+		return r
+	}
+
+	if r.CatchingUp {
+		p.warn(r, "catching up")
+	}
+	if r.TLSExpiry != nil {
+		switch left := time.Until(*r.TLSExpiry); {
+		case left <= 0:
+			r.Status = StatusDown
+			r.Detail = "TLS certificate expired"
+		case left < p.TLSWarn:
+			p.warn(r, fmt.Sprintf("TLS certificate expires in %s", left.Round(time.Hour)))
+		}
+	}
+	return r
+}
+
+func (p *Prober) warn(r *Result, detail string) {
+	if r.Status == StatusOK {
+		r.Status = StatusWarn
+	}
+	if r.Detail != "" {
+		r.Detail += "; "
+	}
+	r.Detail += detail
+}
+
+// markLagging warns about endpoints more than MaxLag blocks behind the
+// highest endpoint of the same network. EVM heights are the same block
+// heights, so all kinds are compared together.
+func (p *Prober) markLagging(results []*Result) {
+	highest := map[string]int64{}
+	for _, r := range results {
+		if r.Height > highest[r.Network] {
+			highest[r.Network] = r.Height
+		}
+	}
+	for _, r := range results {
+		if r.Height == 0 || r.Status == StatusDown || r.Status == StatusWrongChain {
+			continue
+		}
+		if lag := highest[r.Network] - r.Height; lag > p.MaxLag {
+			p.warn(r, fmt.Sprintf("%d blocks behind", lag))
+		}
+	}
+}
+
+func (p *Prober) probeRPC(ctx context.Context, r *Result) error {
+	var status struct {
+		Result struct {
+			NodeInfo struct {
+				Network string `json:"network"`
+			} `json:"node_info"`
+			SyncInfo struct {
+				LatestBlockHeight string `json:"latest_block_height"`
+				CatchingUp        bool   `json:"catching_up"`
+			} `json:"sync_info"`
+		} `json:"result"`
+	}
+	if err := p.getJSON(ctx, r, r.Endpoint+"/status", &status); err != nil {
+		return err
+	}
+	r.ChainID = status.Result.NodeInfo.Network
+	r.CatchingUp = status.Result.SyncInfo.CatchingUp
+	h, err := strconv.ParseInt(status.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid latest_block_height: %w", err)
+	}
+	r.Height = h
+	return nil
+}
+
+func (p *Prober) probeREST(ctx context.Context, r *Result) error {
+	var info struct {
+		DefaultNodeInfo struct {
+			Network string `json:"network"`
+		} `json:"default_node_info"`
+	}
+	if err := p.getJSON(ctx, r, r.Endpoint+"/cosmos/base/tendermint/v1beta1/node_info", &info); err != nil {
+		return err
+	}
+	r.ChainID = info.DefaultNodeInfo.Network
+
+	var latest struct {
+		Block struct {
+			Header struct {
+				Height string `json:"height"`
+			} `json:"header"`
+		} `json:"block"`
+	}
+	if err := p.getJSON(ctx, r, r.Endpoint+"/cosmos/base/tendermint/v1beta1/blocks/latest", &latest); err != nil {
+		return err
+	}
+	h, err := strconv.ParseInt(latest.Block.Header.Height, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid block height: %w", err)
+	}
+	r.Height = h
+
+	var syncing struct {
+		Syncing bool `json:"syncing"`
+	}
+	if err := p.getJSON(ctx, r, r.Endpoint+"/cosmos/base/tendermint/v1beta1/syncing", &syncing); err != nil {
+		return err
+	}
+	r.CatchingUp = syncing.Syncing
+	return nil
+}
+
+func (p *Prober) probeGRPC(ctx context.Context, r *Result) error {
+	host, useTLS, err := hostPort(r.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{Timeout: p.Timeout}
+	start := time.Now()
+	if !useTLS {
+		conn, err := dialer.DialContext(ctx, "tcp", host)
+		if err != nil {
+			return err
+		}
+		r.Calls = append(r.Calls, time.Since(start))
+		return conn.Close()
+	}
+
+	td := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{NextProtos: []string{"h2"}}}
+	conn, err := td.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	r.Calls = append(r.Calls, time.Since(start))
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) > 0 {
+		expiry := state.PeerCertificates[0].NotAfter
+		r.TLSExpiry = &expiry
+	}
+	if state.NegotiatedProtocol != "h2" {
+		return errors.New("server does not speak HTTP/2, required by gRPC")
+	}
+	return nil
+}
+
+func (p *Prober) probeEVM(ctx context.Context, r *Result) error {
+	var chainID, block string
+	if err := p.ethCall(ctx, r, "eth_chainId", &chainID); err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(chainID, "0x"), 16, 64)
+	if err != nil {
+		return fmt.Errorf("invalid eth_chainId %q", chainID)
+	}
+	r.ChainID = strconv.FormatUint(id, 10)
+
+	if err := p.ethCall(ctx, r, "eth_blockNumber", &block); err != nil {
+		return err
+	}
+	if r.Height, err = strconv.ParseInt(strings.TrimPrefix(block, "0x"), 16, 64); err != nil {
+		return fmt.Errorf("invalid eth_blockNumber %q", block)
+	}
+
+	// eth_syncing returns false, or an object describing sync progress.
+	var syncing json.RawMessage
+	if err := p.ethCall(ctx, r, "eth_syncing", &syncing); err != nil {
+		return err
+	}
+	r.CatchingUp = string(syncing) != "false"
+	return nil
+}
+
+func (p *Prober) ethCall(ctx context.Context, r *Result, method string, v any) error {
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": []any{}})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := p.do(req, r, &resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%s: %s", method, resp.Error.Message)
+	}
+	return json.Unmarshal(resp.Result, v)
+}
+
+func (p *Prober) getJSON(ctx context.Context, r *Result, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	return p.do(req, r, v)
+}
+
+func (p *Prober) do(req *http.Request, r *Result, v any) error {
+	start := time.Now()
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	defer func() { r.Calls = append(r.Calls, time.Since(start)) }()
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		expiry := resp.TLS.PeerCertificates[0].NotAfter
+		r.TLSExpiry = &expiry
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(v)
+}
+
+// hostPort returns the host:port to dial for a gRPC endpoint given as a URL
+// or a bare host:port, and whether TLS should be used.
+func hostPort(endpoint string) (string, bool, error) {
+	if !strings.Contains(endpoint, "://") {
+		_, port, err := net.SplitHostPort(endpoint)
+		if err != nil {
+			return "", false, err
+		}
+		return endpoint, port == "443", nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", false, err
+	}
+	useTLS := u.Scheme == "https" || u.Scheme == "grpcs"
+	if u.Port() != "" {
+		return u.Host, useTLS, nil
+	}
+	if useTLS {
+		return net.JoinHostPort(u.Hostname(), "443"), true, nil
+	}
+	return net.JoinHostPort(u.Hostname(), "80"), false, nil
+}
+
+// WriteTable writes results as a text table.
+func WriteTable(w io.Writer, results []*Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NETWORK\tKIND\tENDPOINT\tSTATUS\tHEIGHT\tLATENCY\tTLS EXPIRY\tDETAIL")
+	for _, r := range results {
+		height, expiry := "-", "-"
+		if r.Height > 0 {
+			height = strconv.FormatInt(r.Height, 10)
+		}
+		if r.TLSExpiry != nil {
+			expiry = r.TLSExpiry.Format(time.DateOnly)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Network, r.Kind, r.Endpoint, r.Status, height, r.Latency.Round(time.Millisecond), expiry, r.Detail)
+	}
+	return tw.Flush()
+}
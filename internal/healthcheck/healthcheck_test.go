@@ -0,0 +1,208 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/network"
+)
+
+// statusHandler serves a CometBFT /status response.
+func statusHandler(chainID string, height int64, catchingUp bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"result":{"node_info":{"network":%q},"sync_info":{"latest_block_height":"%d","catching_up":%t}}}`,
+			chainID, height, catchingUp)
+	})
+}
+
+func TestProbeRPC(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.Handler
+		status  string
+		detail  string
+	}{
+		{"ok", statusHandler("chiado_10010-1", 100, false), StatusOK, ""},
+		{"catching up", statusHandler("chiado_10010-1", 100, true), StatusWarn, "catching up"},
+		{"wrong chain", statusHandler("barra_9191-1", 100, false), StatusWrongChain, "chain-id barra_9191-1, expected chiado_10010-1"},
+		{"http error", http.NotFoundHandler(), StatusDown, "404 Not Found"},
+		{"not json", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "<html>") }), StatusDown, "invalid character"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			n := &network.Network{Name: "chiado", ChainID: "chiado_10010-1", RPC: []string{srv.URL}}
+			p := &Prober{Client: srv.Client(), Timeout: time.Second, MaxLag: 50}
+			results := p.Probe(context.Background(), []*network.Network{n})
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			r := results[0]
+			if r.Status != tt.status || !strings.Contains(r.Detail, tt.detail) {
+				t.Errorf("got status %s (%s), want %s (%s)", r.Status, r.Detail, tt.status, tt.detail)
+			}
+		})
+	}
+}
+
+func TestProbeEVM(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := map[string]string{"eth_chainId": `"0x2711"`, "eth_blockNumber": `"0x64"`, "eth_syncing": `false`}
+		var req struct {
+			Method string `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":%s}`, results[req.Method])
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		chainID string
+		status  string
+	}{
+		{"chiado_10001-1", StatusOK},
+		{"chiado_10010-1", StatusWrongChain},
+		{"chiado", StatusOK}, // no EVM chain ID to compare against
+	}
+	for _, tt := range tests {
+		t.Run(tt.chainID, func(t *testing.T) {
+			n := &network.Network{Name: "chiado", ChainID: tt.chainID, EVM: []string{srv.URL}}
+			p := &Prober{Client: srv.Client(), Timeout: time.Second}
+			r := p.Probe(context.Background(), []*network.Network{n})[0]
+			if r.Status != tt.status {
+				t.Errorf("got status %s (%s), want %s", r.Status, r.Detail, tt.status)
+			}
+			if r.Height != 100 {
+				t.Errorf("got height %d, want 100", r.Height)
+			}
+			if len(r.Calls) != 3 {
+				t.Errorf("got %d call latencies, want one per JSON-RPC call", len(r.Calls))
+			}
+		})
+	}
+}
+
+func TestLag(t *testing.T) {
+	heights := []int64{1000, 990, 900}
+	var rpcs []string
+	for _, h := range heights {
+		srv := httptest.NewServer(statusHandler("chiado_10010-1", h, false))
+		defer srv.Close()
+		rpcs = append(rpcs, srv.URL)
+	}
+	down := httptest.NewServer(http.NotFoundHandler())
+	defer down.Close()
+	rpcs = append(rpcs, down.URL)
+
+	n := &network.Network{Name: "chiado", ChainID: "chiado_10010-1", RPC: rpcs}
+	p := &Prober{Client: http.DefaultClient, Timeout: time.Second, MaxLag: 50}
+	want := map[string]string{
+		rpcs[0]: StatusOK,
+		rpcs[1]: StatusOK,
+		rpcs[2]: StatusWarn,
+		rpcs[3]: StatusDown,
+	}
+	for _, r := range p.Probe(context.Background(), []*network.Network{n}) {
+		if r.Status != want[r.Endpoint] {
+			t.Errorf("%s: got status %s (%s), want %s", r.Endpoint, r.Status, r.Detail, want[r.Endpoint])
+		}
+		if r.Endpoint == rpcs[2] && r.Detail != "100 blocks behind" {
+			t.Errorf("%s: got detail %q, want %q", r.Endpoint, r.Detail, "100 blocks behind")
+		}
+	}
+}
+
+func TestTLSExpiry(t *testing.T) {
+	tests := []struct {
+		name     string
+		notAfter time.Duration
+		status   string
+		detail   string
+	}{
+		{"valid", 90 * 24 * time.Hour, StatusOK, ""},
+		{"expiring", 3 * 24 * time.Hour, StatusWarn, "TLS certificate expires in"},
+		{"expired", -time.Hour, StatusDown, "TLS certificate expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewUnstartedServer(statusHandler("chiado_10010-1", 100, false))
+			srv.TLS = &tls.Config{Certificates: []tls.Certificate{testCert(t, time.Now().Add(tt.notAfter))}}
+			srv.StartTLS()
+			defer srv.Close()
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+			n := &network.Network{Name: "chiado", ChainID: "chiado_10010-1", RPC: []string{srv.URL}}
+			p := &Prober{Client: client, Timeout: time.Second, TLSWarn: 14 * 24 * time.Hour}
+			r := p.Probe(context.Background(), []*network.Network{n})[0]
+			if r.Status != tt.status || !strings.Contains(r.Detail, tt.detail) {
+				t.Errorf("got status %s (%s), want %s (%s)", r.Status, r.Detail, tt.status, tt.detail)
+			}
+			if r.TLSExpiry == nil {
+				t.Error("TLS expiry not recorded")
+			}
+		})
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		host     string
+		tls      bool
+	}{
+		{"grpc.chiado.wardenprotocol.org:443", "grpc.chiado.wardenprotocol.org:443", true},
+		{"localhost:9090", "localhost:9090", false},
+		{"https://grpc.chiado.wardenprotocol.org", "grpc.chiado.wardenprotocol.org:443", true},
+		{"http://localhost", "localhost:80", false},
+		{"grpcs://localhost:9443", "localhost:9443", true},
+	}
+	for _, tt := range tests {
+		host, useTLS, err := hostPort(tt.endpoint)
+		if err != nil || host != tt.host || useTLS != tt.tls {
+			t.Errorf("hostPort(%q) = %q, %t, %v, want %q, %t", tt.endpoint, host, useTLS, err, tt.host, tt.tls)
+		}
+	}
+}
+
+// testCert returns a self-signed certificate for 127.0.0.1 expiring at
+// notAfter.
+func testCert(t *testing.T, notAfter time.Time) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
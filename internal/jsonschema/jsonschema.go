@@ -0,0 +1,500 @@
+// Package jsonschema validates JSON documents against JSON Schema draft-07
+// schemas, such as the cosmos/chain-registry ones, and reports each
+// violation at the JSON pointer of the offending value.
+//
+// The keywords supported are the validation keywords of draft-07 with local
+// "#/..." references. format is only checked for "uri"; the other formats
+// are annotations, as the draft allows.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root     any
+	patterns map[string]*regexp.Regexp
+}
+
+// Error is a value that does not match its schema.
+type Error struct {
+	// Pointer is the RFC 6901 JSON pointer of the value; "" is the whole
+	// document.
+	Pointer string
+	Message string
+}
+
+func (e Error) Error() string {
+	if e.Pointer == "" {
+		return "/: " + e.Message
+	}
+	return e.Pointer + ": " + e.Message
+}
+
+// Compile parses a JSON Schema. It fails on invalid JSON, patterns that are
+// not valid regular expressions and references that do not resolve.
+func Compile(data []byte) (*Schema, error) {
+	root, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{root: root, patterns: map[string]*regexp.Regexp{}}
+	if err := s.compile(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MustCompile is like Compile but panics on error. It is meant for
+// embedded schemas.
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic("jsonschema: " + err.Error())
+	}
+	return s
+}
+
+// compile checks the patterns and references of the subschemas of v.
+func (s *Schema) compile(v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		if p, ok := v["pattern"].(string); ok {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("pattern %q: %w", p, err)
+			}
+			s.patterns[p] = re
+		}
+		if pp, ok := v["patternProperties"].(map[string]any); ok {
+			for p := range pp {
+				re, err := regexp.Compile(p)
+				if err != nil {
+					return fmt.Errorf("patternProperties %q: %w", p, err)
+				}
+				s.patterns[p] = re
+			}
+		}
+		if ref, ok := v["$ref"].(string); ok {
+			if _, err := s.resolve(ref); err != nil {
+				return err
+			}
+		}
+		for k, sub := range v {
+			// The values of enum and const are data, not schemas.
+			if k == "enum" || k == "const" {
+				continue
+			}
+			if err := s.compile(sub); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, sub := range v {
+			if err := s.compile(sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the subschema a "#/..." reference points to.
+func (s *Schema) resolve(ref string) (any, error) {
+	ptr, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("$ref %q: only references within the schema are supported", ref)
+	}
+	v := s.root
+	if ptr == "" {
+		return v, nil
+	}
+	for _, tok := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch cur := v.(type) {
+		case map[string]any:
+			if v, ok = cur[tok]; !ok {
+				return nil, fmt.Errorf("$ref %q does not resolve", ref)
+			}
+		case []any:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(cur) {
+				return nil, fmt.Errorf("$ref %q does not resolve", ref)
+			}
+			v = cur[i]
+		default:
+			return nil, fmt.Errorf("$ref %q does not resolve", ref)
+		}
+	}
+	return v, nil
+}
+
+// Validate checks the JSON document data against s and returns the
+// violations found, in document order with object properties sorted. The
+// error is for data that is not JSON.
+func (s *Schema) Validate(data []byte) ([]Error, error) {
+	doc, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.validate(s.root, doc, ""), nil
+}
+
+func (s *Schema) validate(schema, v any, ptr string) []Error {
+	var errs []Error
+	fail := func(format string, args ...any) {
+		errs = append(errs, Error{Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+	}
+
+	sch, ok := schema.(map[string]any)
+	if !ok {
+		if b, ok := schema.(bool); ok && !b {
+			fail("no value is allowed here")
+		}
+		return nil
+	}
+	if ref, ok := sch["$ref"].(string); ok {
+		// In draft-07 a $ref overrides the keywords next to it.
+		target, _ := s.resolve(ref)
+		return s.validate(target, v, ptr)
+	}
+
+	if t, ok := sch["type"]; ok && !hasType(v, t) {
+		fail("is %s, want %s", typeOf(v), typeList(t))
+		return errs
+	}
+	if enum, ok := sch["enum"].([]any); ok && !contains(enum, v) {
+		fail("%s is not one of %s", show(v), showList(enum))
+	}
+	if c, ok := sch["const"]; ok && !equal(c, v) {
+		fail("is %s, want %s", show(v), show(c))
+	}
+
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if min, ok := number(sch["minLength"]); ok && float64(n) < min {
+			fail("%q is shorter than %v character(s)", v, min)
+		}
+		if max, ok := number(sch["maxLength"]); ok && float64(n) > max {
+			fail("is longer than %v character(s)", max)
+		}
+		if p, ok := sch["pattern"].(string); ok && !s.patterns[p].MatchString(v) {
+			fail("%q does not match %s", v, p)
+		}
+		if sch["format"] == "uri" {
+			if u, err := url.Parse(v); err != nil || u.Scheme == "" {
+				fail("%q is not an absolute URI", v)
+			}
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if min, ok := number(sch["minimum"]); ok && f < min {
+			fail("%s is less than %v", v, min)
+		}
+		if max, ok := number(sch["maximum"]); ok && f > max {
+			fail("%s is more than %v", v, max)
+		}
+		if min, ok := number(sch["exclusiveMinimum"]); ok && f <= min {
+			fail("%s is not more than %v", v, min)
+		}
+		if max, ok := number(sch["exclusiveMaximum"]); ok && f >= max {
+			fail("%s is not less than %v", v, max)
+		}
+	case []any:
+		if min, ok := number(sch["minItems"]); ok && float64(len(v)) < min {
+			fail("has %d item(s), want at least %v", len(v), min)
+		}
+		if max, ok := number(sch["maxItems"]); ok && float64(len(v)) > max {
+			fail("has %d item(s), want at most %v", len(v), max)
+		}
+		if sch["uniqueItems"] == true {
+			for i := range v {
+				for j := i + 1; j < len(v); j++ {
+					if equal(v[i], v[j]) {
+						fail("items %d and %d are equal", i, j)
+					}
+				}
+			}
+		}
+		switch items := sch["items"].(type) {
+		case []any:
+			for i, item := range v {
+				if i < len(items) {
+					errs = append(errs, s.validate(items[i], item, ptr+"/"+strconv.Itoa(i))...)
+				} else if add, ok := sch["additionalItems"]; ok {
+					errs = append(errs, s.validate(add, item, ptr+"/"+strconv.Itoa(i))...)
+				}
+			}
+		case nil:
+		default:
+			for i, item := range v {
+				errs = append(errs, s.validate(items, item, ptr+"/"+strconv.Itoa(i))...)
+			}
+		}
+	case map[string]any:
+		if req, ok := sch["required"].([]any); ok {
+			for _, r := range req {
+				if name, _ := r.(string); name != "" {
+					if _, ok := v[name]; !ok {
+						errs = append(errs, Error{Pointer: ptr + "/" + escape(name), Message: "required property is missing"})
+					}
+				}
+			}
+		}
+		if min, ok := number(sch["minProperties"]); ok && float64(len(v)) < min {
+			fail("has %d propert(ies), want at least %v", len(v), min)
+		}
+		props, _ := sch["properties"].(map[string]any)
+		patternProps, _ := sch["patternProperties"].(map[string]any)
+		for _, k := range sortedKeys(v) {
+			p := ptr + "/" + escape(k)
+			matched := false
+			if sub, ok := props[k]; ok {
+				matched = true
+				errs = append(errs, s.validate(sub, v[k], p)...)
+			}
+			for _, pattern := range sortedKeys(patternProps) {
+				if sub := patternProps[pattern]; s.patterns[pattern].MatchString(k) {
+					matched = true
+					errs = append(errs, s.validate(sub, v[k], p)...)
+				}
+			}
+			if add, ok := sch["additionalProperties"]; ok && !matched {
+				if add == false {
+					errs = append(errs, Error{Pointer: p, Message: "unexpected property"})
+				} else {
+					errs = append(errs, s.validate(add, v[k], p)...)
+				}
+			}
+		}
+		if deps, ok := sch["dependencies"].(map[string]any); ok {
+			for _, k := range sortedKeys(deps) {
+				if _, ok := v[k]; !ok {
+					continue
+				}
+				switch dep := deps[k].(type) {
+				case []any:
+					for _, r := range dep {
+						if name, _ := r.(string); name != "" {
+							if _, ok := v[name]; !ok {
+								errs = append(errs, Error{Pointer: ptr + "/" + escape(name), Message: fmt.Sprintf("required property is missing, as %s is set", k)})
+							}
+						}
+					}
+				default:
+					errs = append(errs, s.validate(dep, v, ptr)...)
+				}
+			}
+		}
+	}
+
+	if all, ok := sch["allOf"].([]any); ok {
+		for _, sub := range all {
+			errs = append(errs, s.validate(sub, v, ptr)...)
+		}
+	}
+	if anyOf, ok := sch["anyOf"].([]any); ok && s.matching(anyOf, v, ptr) == 0 {
+		fail("does not match any of the allowed schemas")
+	}
+	if oneOf, ok := sch["oneOf"].([]any); ok {
+		if n := s.matching(oneOf, v, ptr); n != 1 {
+			fail("matches %d of the schemas, want exactly one", n)
+		}
+	}
+	if not, ok := sch["not"]; ok && len(s.validate(not, v, ptr)) == 0 {
+		fail("matches a schema it must not")
+	}
+	if cond, ok := sch["if"]; ok {
+		if len(s.validate(cond, v, ptr)) == 0 {
+			if then, ok := sch["then"]; ok {
+				errs = append(errs, s.validate(then, v, ptr)...)
+			}
+		} else if els, ok := sch["else"]; ok {
+			errs = append(errs, s.validate(els, v, ptr)...)
+		}
+	}
+	return errs
+}
+
+// matching returns how many of schemas v matches.
+func (s *Schema) matching(schemas []any, v any, ptr string) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(s.validate(sub, v, ptr)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("data after the JSON value")
+	}
+	return v, nil
+}
+
+func hasType(v, t any) bool {
+	switch t := t.(type) {
+	case string:
+		return isType(v, t)
+	case []any:
+		for _, name := range t {
+			if name, ok := name.(string); ok && isType(v, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isType(v any, name string) bool {
+	got := typeOf(v)
+	if got == name {
+		return true
+	}
+	if name == "number" && got == "integer" {
+		return true
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type of v, telling integers apart from
+// other numbers.
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(v.String()); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func typeList(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, len(list))
+		for i, n := range list {
+			names[i] = fmt.Sprint(n)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func number(v any) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+func contains(list []any, v any) bool {
+	for _, e := range list {
+		if equal(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal reports whether two decoded JSON values are equal, comparing
+// numbers by value.
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okA := new(big.Rat).SetString(a.String())
+		y, okB := new(big.Rat).SetString(b.String())
+		return okA && okB && x.Cmp(y) == 0
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := b[k]
+			if !ok || !equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func show(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func showList(list []any) string {
+	s := make([]string, len(list))
+	for i, v := range list {
+		s[i] = show(v)
+	}
+	return strings.Join(s, ", ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escape escapes a property name for use as a JSON pointer token.
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,104 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["name", "coins"],
+  "additionalProperties": false,
+  "properties": {
+    "name": {"type": "string", "pattern": "^[a-z0-9]+$", "minLength": 2},
+    "status": {"enum": ["live", "killed"]},
+    "version": {"const": 1},
+    "url": {"type": "string", "format": "uri"},
+    "coins": {"type": "array", "minItems": 1, "uniqueItems": true, "items": {"$ref": "#/definitions/coin"}},
+    "kind": {"type": "string"},
+    "address": {"type": "string"},
+    "labels": {"type": "object", "patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": false}
+  },
+  "if": {"properties": {"kind": {"const": "cw20"}}, "required": ["kind"]},
+  "then": {"required": ["address"]},
+  "definitions": {
+    "coin": {
+      "type": "object",
+      "required": ["denom", "amount"],
+      "additionalProperties": false,
+      "properties": {
+        "denom": {"type": "string"},
+        "amount": {"type": "string", "pattern": "^[0-9]+$"},
+        "exponent": {"type": "integer", "minimum": 0}
+      }
+    }
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := Compile([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const coin = `{"denom":"uward","amount":"1"}`
+	tests := []struct {
+		name string
+		doc  string
+		want []string // the errors, in order
+	}{
+		{"valid", `{"name":"ward","coins":[` + coin + `],"status":"live","version":1.0,"url":"https://x.org/a","labels":{"x-a":"b"}}`, nil},
+		{"missing properties", `{}`, []string{"/name: required property is missing", "/coins: required property is missing"}},
+		{"extra property", `{"name":"ward","coins":[` + coin + `],"nmae":"x"}`, []string{"/nmae: unexpected property"}},
+		{"number for string", `{"name":"ward","coins":[{"denom":"uward","amount":1}]}`, []string{"/coins/0/amount: is integer, want string"}},
+		{"missing nested property", `{"name":"ward","coins":[{"amount":"1"}]}`, []string{"/coins/0/denom: required property is missing"}},
+		{"pattern", `{"name":"Ward","coins":[` + coin + `]}`, []string{`/name: "Ward" does not match ^[a-z0-9]+$`}},
+		{"min length", `{"name":"w","coins":[` + coin + `]}`, []string{`/name: "w" is shorter than 2 character(s)`}},
+		{"enum", `{"name":"ward","coins":[` + coin + `],"status":"dead"}`, []string{`/status: "dead" is not one of "live", "killed"`}},
+		{"const", `{"name":"ward","coins":[` + coin + `],"version":2}`, []string{"/version: is 2, want 1"}},
+		{"uri", `{"name":"ward","coins":[` + coin + `],"url":"x.org/a"}`, []string{`/url: "x.org/a" is not an absolute URI`}},
+		{"integer", `{"name":"ward","coins":[{"denom":"uward","amount":"1","exponent":1.5}]}`, []string{"/coins/0/exponent: is number, want integer"}},
+		{"minimum", `{"name":"ward","coins":[{"denom":"uward","amount":"1","exponent":-1}]}`, []string{"/coins/0/exponent: -1 is less than 0"}},
+		{"min items", `{"name":"ward","coins":[]}`, []string{"/coins: has 0 item(s), want at least 1"}},
+		{"unique items", `{"name":"ward","coins":[` + coin + `,` + coin + `]}`, []string{"/coins: items 0 and 1 are equal"}},
+		{"pattern properties", `{"name":"ward","coins":[` + coin + `],"labels":{"x-a":1,"y":"b"}}`, []string{"/labels/x-a: is integer, want string", "/labels/y: unexpected property"}},
+		{"if then", `{"name":"ward","coins":[` + coin + `],"kind":"cw20"}`, []string{"/address: required property is missing"}},
+		{"escaped pointer", `{"name":"ward","coins":[` + coin + `],"a/b~c":1}`, []string{"/a~1b~0c: unexpected property"}},
+		{"not an object", `[]`, []string{"/: is array, want object"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := s.Validate([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name, schema, err string
+	}{
+		{"bad pattern", `{"pattern":"("}`, "pattern"},
+		{"dangling ref", `{"$ref":"#/definitions/x"}`, "does not resolve"},
+		{"remote ref", `{"$ref":"https://example.org/s.json"}`, "only references within the schema"},
+		{"not json", `{`, "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Compile() error = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
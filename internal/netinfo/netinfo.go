@@ -0,0 +1,314 @@
+// Package netinfo implements the netinfo-server command, an HTTP service
+// that serves the network metadata in this repo as versioned JSON.
+//
+// Routes:
+//
+//	GET /v1/networks            names and chain-ids of all networks
+//	GET /v1/networks/{network}  chain-id, genesis URL and SHA-256, recommended
+//	                            wardend version, peers, seeds and endpoints
+//	GET /healthz                liveness probe
+//
+// The repository is re-read every -refresh, so a server running on a
+// checkout that is periodically pulled serves the current data. On every
+// refresh the published peers are dialed over TCP, and the ones that accept
+// connections are served as live_peers. Network names are the URL keys, so a
+// refresh fails when two network directories share a name.
+//
+// Usage:
+//
+//	netinfo-server -listen :8080
+//	netinfo-server -listen 127.0.0.1:8080 -refresh 1m -base-url https://example.com/networks/
+package netinfo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// APIVersion is the version of the JSON documents served under /v1.
+const APIVersion = "v1"
+
+const defaultBaseURL = "https://raw.githubusercontent.com/warden-protocol/networks/main/"
+
+// Info is the document served at /v1/networks/{network}.
+type Info struct {
+	APIVersion string    `json:"api_version"`
+	Name       string    `json:"name"`
+	ChainID    string    `json:"chain_id"`
+	Version    string    `json:"version,omitempty"`
+	Genesis    *Genesis  `json:"genesis,omitempty"`
+	Peers      []string  `json:"peers"`
+	LivePeers  []string  `json:"live_peers"`
+	Seeds      []string  `json:"seeds"`
+	Endpoints  Endpoints `json:"endpoints"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Genesis locates a network's genesis file.
+type Genesis struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Endpoints are a network's public endpoints.
+type Endpoints struct {
+	RPC  []string `json:"rpc"`
+	REST []string `json:"rest"`
+	GRPC []string `json:"grpc"`
+	EVM  []string `json:"evm"`
+}
+
+// Summary is an entry of /v1/networks.
+type Summary struct {
+	Name    string `json:"name"`
+	ChainID string `json:"chain_id"`
+	URL     string `json:"url"`
+}
+
+// Command is the netinfo-server command.
+var Command = &cli.Command{
+	Name:  "netinfo-server",
+	Short: "Serve the repository's network metadata as versioned JSON over HTTP.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			listen      = fs.String("listen", ":8080", "address to listen on")
+			refresh     = fs.Duration("refresh", 5*time.Minute, "how often to re-read the repository and dial the peers")
+			dialTimeout = fs.Duration("dial-timeout", 3*time.Second, "timeout for dialing each peer")
+			baseURL     = fs.String("base-url", defaultBaseURL, "URL the repository root is published under, used for genesis URLs")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if len(args) != 0 {
+				return cli.ErrUsage
+			}
+			if *refresh <= 0 {
+				return fmt.Errorf("-refresh must be positive, got %s", *refresh)
+			}
+
+			s := &Server{Root: env.Root, BaseURL: *baseURL, DialTimeout: *dialTimeout}
+			if err := s.Refresh(ctx); err != nil {
+				return err
+			}
+			go func() {
+				t := time.NewTicker(*refresh)
+				defer t.Stop()
+				for {
+					select {
+					case <-t.C:
+						if err := s.Refresh(ctx); err != nil {
+							fmt.Fprintf(env.Stderr, "refresh: %v\n", err)
+						}
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+
+			srv := &http.Server{
+				Addr:              *listen,
+				Handler:           s.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			go func() {
+				<-ctx.Done()
+				shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = srv.Shutdown(shutdown)
+			}()
+
+			fmt.Fprintf(env.Stderr, "serving %s on %s\n", env.Root, *listen)
+			if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		}
+	},
+}
+
+// Server serves the metadata of the networks under Root.
+type Server struct {
+	Root        string
+	BaseURL     string
+	DialTimeout time.Duration
+
+	mu       sync.RWMutex
+	networks map[string]*Info
+	order    []string
+}
+
+// Refresh re-reads the networks from the repository and dials their peers.
+// The previous data keeps being served until the refresh completes.
+func (s *Server) Refresh(ctx context.Context) error {
+	networks, err := network.LoadAll(s.Root, nil)
+	if err != nil {
+		return err
+	}
+
+	infos := make(map[string]*Info, len(networks))
+	order := make([]string, 0, len(networks))
+	dirs := make(map[string]string, len(networks))
+	for _, n := range networks {
+		if dir, ok := dirs[n.Name]; ok {
+			return fmt.Errorf("network name %s is used by both %s and %s", n.Name, dir, n.Dir)
+		}
+		dirs[n.Name] = n.Dir
+	}
+
+	var wg sync.WaitGroup
+	for _, n := range networks {
+		info, err := s.info(n)
+		if err != nil {
+			return err
+		}
+		infos[n.Name] = info
+		order = append(order, n.Name)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info.LivePeers = s.livePeers(ctx, n.Peers)
+		}()
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	s.networks, s.order = infos, order
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Server) info(n *network.Network) (*Info, error) {
+	info := &Info{
+		APIVersion: APIVersion,
+		Name:       n.Name,
+		ChainID:    n.ChainID,
+		Version:    n.Version,
+		Peers:      n.Peers,
+		Seeds:      n.Seeds,
+		Endpoints:  Endpoints{RPC: n.RPC, REST: n.REST, GRPC: n.GRPC, EVM: n.EVM},
+		UpdatedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+	if n.Genesis == "" {
+		return info, nil
+	}
+
+	sum, size, err := fileSHA256(n.Genesis)
+	if err != nil {
+		return nil, err
+	}
+	url := n.GenesisURL
+	if url == "" {
+		rel, err := filepath.Rel(s.Root, n.Genesis)
+		if err != nil {
+			return nil, err
+		}
+		url = strings.TrimSuffix(s.BaseURL, "/") + "/" + filepath.ToSlash(rel)
+	}
+	info.Genesis = &Genesis{URL: url, SHA256: sum, Size: size}
+	return info, nil
+}
+
+// livePeers returns the peers that accept TCP connections, in input order.
+func (s *Server) livePeers(ctx context.Context, peers []string) []string {
+	ok := make([]bool, len(peers))
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		_, addr, err := networks.ParsePeer(p)
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := net.Dialer{Timeout: s.DialTimeout}
+			conn, err := d.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return
+			}
+			conn.Close()
+			ok[i] = true
+		}()
+	}
+	wg.Wait()
+
+	live := []string{}
+	for i, p := range peers {
+		if ok[i] {
+			live = append(live, p)
+		}
+	}
+	return live
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /v1/networks", s.handleList)
+	mux.HandleFunc("GET /v1/networks/{network}", s.handleNetwork)
+	return mux
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	list := make([]Summary, 0, len(s.order))
+	for _, name := range s.order {
+		list = append(list, Summary{Name: name, ChainID: s.networks[name].ChainID, URL: "/v1/networks/" + name})
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{"api_version": APIVersion, "networks": list})
+}
+
+func (s *Server) handleNetwork(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("network")
+	s.mu.RLock()
+	info, ok := s.networks[name]
+	s.mu.RUnlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown network %q", name)})
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+	_ = cli.WriteJSON(w, v)
+}
+
+func fileSHA256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
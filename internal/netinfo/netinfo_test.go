@@ -0,0 +1,162 @@
+package netinfo
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeNetwork creates a network directory under root with the given files.
+func writeNetwork(t *testing.T, root, dir string, files map[string]string) {
+	t.Helper()
+	dir = filepath.Join(root, dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	const (
+		idA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		idB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	)
+	live := idA + "@" + ln.Addr().String()
+	dead := idB + "@" + closed.Addr().String()
+
+	root := t.TempDir()
+	writeNetwork(t, root, "testnets/chiado", map[string]string{
+		"chain.json": `{"chain_id":"chiado_10010-1","codebase":{"recommended_version":"v0.5.4","genesis":{"genesis_url":"https://example.com/genesis.json"}},
+			"peers":{"persistent_peers":[{"id":"` + idA + `","address":"` + ln.Addr().String() + `"},{"id":"` + idB + `","address":"` + closed.Addr().String() + `"}]},
+			"apis":{"rpc":[{"address":"https://rpc.chiado.wardenprotocol.org/"}]}}`,
+		"genesis.json": "{}",
+	})
+	writeNetwork(t, root, "testnets/buenavista", map[string]string{
+		"chain-id.txt": "buenavista-1\n",
+		"genesis.json": "{}\n",
+	})
+
+	s := &Server{Root: root, BaseURL: "https://example.org/networks/", DialTimeout: time.Second}
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	get := func(path string, wantStatus int, v any) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("GET %s: got status %d, want %d", path, resp.StatusCode, wantStatus)
+		}
+		if v == nil {
+			return
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("GET %s: got Content-Type %q, want application/json", path, ct)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+	}
+
+	get("/healthz", http.StatusOK, nil)
+
+	var list struct {
+		APIVersion string    `json:"api_version"`
+		Networks   []Summary `json:"networks"`
+	}
+	get("/v1/networks", http.StatusOK, &list)
+	if list.APIVersion != APIVersion || len(list.Networks) != 2 {
+		t.Fatalf("got list %+v, want 2 networks", list)
+	}
+	for _, n := range list.Networks {
+		if n.URL != "/v1/networks/"+n.Name {
+			t.Errorf("%s: got URL %q", n.Name, n.URL)
+		}
+	}
+
+	var chiado Info
+	get("/v1/networks/chiado", http.StatusOK, &chiado)
+	if chiado.ChainID != "chiado_10010-1" || chiado.Version != "v0.5.4" {
+		t.Errorf("got chain-id %q, version %q", chiado.ChainID, chiado.Version)
+	}
+	if chiado.Genesis == nil || chiado.Genesis.URL != "https://example.com/genesis.json" || chiado.Genesis.Size != 2 {
+		t.Errorf("got genesis %+v, want the chain.json URL and a size of 2", chiado.Genesis)
+	}
+	// sha256("{}")
+	if chiado.Genesis != nil && chiado.Genesis.SHA256 != "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a" {
+		t.Errorf("got genesis sha256 %s", chiado.Genesis.SHA256)
+	}
+	if strings.Join(chiado.Peers, ",") != live+","+dead {
+		t.Errorf("got peers %q", chiado.Peers)
+	}
+	if len(chiado.LivePeers) != 1 || chiado.LivePeers[0] != live {
+		t.Errorf("got live peers %q, want [%s]", chiado.LivePeers, live)
+	}
+	if len(chiado.Endpoints.RPC) != 1 || chiado.Endpoints.RPC[0] != "https://rpc.chiado.wardenprotocol.org" {
+		t.Errorf("got RPC endpoints %q", chiado.Endpoints.RPC)
+	}
+
+	var buenavista Info
+	get("/v1/networks/buenavista", http.StatusOK, &buenavista)
+	if buenavista.Genesis == nil || buenavista.Genesis.URL != "https://example.org/networks/testnets/buenavista/genesis.json" {
+		t.Errorf("got genesis %+v, want a URL under -base-url", buenavista.Genesis)
+	}
+	if buenavista.LivePeers == nil {
+		t.Error("live_peers is null, want an empty list")
+	}
+
+	var notFound map[string]string
+	get("/v1/networks/nope", http.StatusNotFound, &notFound)
+	if notFound["error"] != `unknown network "nope"` {
+		t.Errorf("got error %q", notFound["error"])
+	}
+
+	resp, err := http.Post(srv.URL+"/v1/networks", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /v1/networks: got status %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRefreshDuplicateNames(t *testing.T) {
+	root := t.TempDir()
+	writeNetwork(t, root, "mainnets/warden", map[string]string{"chain-id.txt": "warden_8765-1\n"})
+	writeNetwork(t, root, "testnets/warden", map[string]string{"chain-id.txt": "warden_1234-1\n"})
+
+	s := &Server{Root: root}
+	err := s.Refresh(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "network name warden is used by both") {
+		t.Fatalf("Refresh() error = %v, want a duplicate name error", err)
+	}
+}
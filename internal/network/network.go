@@ -0,0 +1,136 @@
+// Package network locates the network directories of this repo. The
+// metadata they contain is read with package networks.
+package network
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// parents lists the repository directories, relative to the root, that hold
+// network directories.
+var parents = []string{"mainnets", "testnets"}
+
+// Network is the metadata of one network directory.
+type Network = networks.Manifest
+
+// Resolve returns the directory of the network called name. name may be a
+// path to a network directory, or a bare name looked up under root: a
+// "mainnet" directory at the root, or a subdirectory of mainnets/ or
+// testnets/.
+func Resolve(root, name string) (string, error) {
+	if name == "" {
+		return "", errors.New("no network given")
+	}
+	if isDir(name) && (strings.ContainsRune(name, filepath.Separator) || strings.ContainsRune(name, '/')) {
+		return filepath.Clean(name), nil
+	}
+
+	candidates := []string{filepath.Join(root, name)}
+	for _, p := range parents {
+		candidates = append(candidates, filepath.Join(root, p, name))
+	}
+	for _, c := range candidates {
+		if isDir(c) && looksLikeNetwork(c) {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("network %q not found under %s", name, root)
+}
+
+// List returns the directories of all networks under root, sorted by name.
+// Directories starting with "_" (e.g. testnets/_IBC) are not networks.
+func List(root string) ([]string, error) {
+	var dirs []string
+	if d := filepath.Join(root, "mainnet"); isDir(d) && looksLikeNetwork(d) {
+		dirs = append(dirs, d)
+	}
+	for _, p := range parents {
+		entries, err := os.ReadDir(filepath.Join(root, p))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			d := filepath.Join(root, p, e.Name())
+			if e.IsDir() && !strings.HasPrefix(e.Name(), "_") && looksLikeNetwork(d) {
+				dirs = append(dirs, d)
+			}
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool { return filepath.Base(dirs[i]) < filepath.Base(dirs[j]) })
+	return dirs, nil
+}
+
+// Load reads the metadata of the network directory dir.
+func Load(dir string) (*Network, error) {
+	return networks.Load(dir)
+}
+
+// LoadAll loads the networks called names, or every network under root
+// when names is empty.
+func LoadAll(root string, names []string) ([]*Network, error) {
+	var dirs []string
+	if len(names) == 0 {
+		var err error
+		if dirs, err = List(root); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range names {
+		dir, err := Resolve(root, name)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, dir)
+	}
+
+	loaded := make([]*Network, 0, len(dirs))
+	for _, d := range dirs {
+		n, err := Load(d)
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, n)
+	}
+	return loaded, nil
+}
+
+// SplitList splits a comma-separated flag value, dropping empty entries and
+// trailing slashes.
+func SplitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, strings.TrimSuffix(p, "/"))
+		}
+	}
+	return out
+}
+
+func looksLikeNetwork(dir string) bool {
+	for _, f := range []string{"chain-id.txt", "chain.json", networks.ManifestFile, "genesis.json", "init_genesis.json"} {
+		if fileExists(filepath.Join(dir, f)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+func fileExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
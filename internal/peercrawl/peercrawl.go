@@ -0,0 +1,458 @@
+// Package peercrawl implements the peer-crawl command, which discovers the
+// live peers of a network by speaking the CometBFT P2P protocol.
+//
+// Starting from the seeds and persistent peers published in this repo, the
+// crawler dials every address, performs the secret-connection and node-info
+// handshake, and asks the peer for its address book over the PEX channel.
+// Newly learned addresses are crawled breadth-first up to -depth hops,
+// skipping private, loopback and link-local ones unless -allow-private is
+// set. Every
+// peer is reported with its handshake latency, moniker and CometBFT version,
+// and the reachable ones on the right chain are ranked by latency into a
+// peers.txt candidate.
+//
+// Usage:
+//
+//	peer-crawl alfama
+//	peer-crawl -depth 3 -out peers.txt -limit 30 buenavista
+package peercrawl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/libs/protoio"
+	"github.com/cometbft/cometbft/p2p"
+	"github.com/cometbft/cometbft/p2p/conn"
+	tmp2p "github.com/cometbft/cometbft/proto/tendermint/p2p"
+	"github.com/cometbft/cometbft/version"
+	"github.com/cosmos/gogoproto/proto"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// pexChannel is the channel ID of the CometBFT PEX reactor.
+const pexChannel = byte(0x00)
+
+// Peer is the outcome of crawling one address.
+type Peer struct {
+	ID        string        `json:"id"`
+	Address   string        `json:"address"`
+	Depth     int           `json:"depth"`
+	Reachable bool          `json:"reachable"`
+	Network   string        `json:"network,omitempty"`
+	Moniker   string        `json:"moniker,omitempty"`
+	Version   string        `json:"version,omitempty"`
+	Latency   time.Duration `json:"latency_ns,omitempty"`
+	Learned   int           `json:"learned"`
+	Error     string        `json:"error,omitempty"`
+}
+
+func (p *Peer) String() string { return p.ID + "@" + p.Address }
+
+// Command is the peer-crawl command.
+var Command = &cli.Command{
+	Name:  "peer-crawl",
+	Args:  "<network>",
+	Short: "Discover live peers over the CometBFT PEX protocol and rank them by handshake latency.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format      = cli.FormatFlag(fs, "text", "json")
+			depth       = fs.Int("depth", 2, "maximum number of PEX hops from the published peers")
+			maxPeers    = fs.Int("max-peers", 300, "maximum number of addresses to dial")
+			concurrency = fs.Int("concurrency", 16, "number of peers dialed in parallel")
+			timeout     = fs.Duration("timeout", 10*time.Second, "timeout for dialing, handshaking and waiting for addresses from one peer")
+			out         = fs.String("out", "", "write the ranked reachable peers to this file in peer-nodes.txt format")
+			limit       = fs.Int("limit", 20, "number of peers written to -out")
+			allowPriv   = fs.Bool("allow-private", false, "also crawl learned private, loopback and link-local addresses")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if len(args) != 1 {
+				return cli.ErrUsage
+			}
+			if *limit < 1 {
+				return fmt.Errorf("-limit must be at least 1, got %d", *limit)
+			}
+			if *concurrency < 1 {
+				return fmt.Errorf("-concurrency must be at least 1, got %d", *concurrency)
+			}
+			dir, err := network.Resolve(env.Root, args[0])
+			if err != nil {
+				return err
+			}
+			n, err := network.Load(dir)
+			if err != nil {
+				return err
+			}
+			if n.ChainID == "" {
+				return fmt.Errorf("network %s has no chain-id", n.Name)
+			}
+			start := append(append([]string{}, n.Seeds...), n.Peers...)
+			if len(start) == 0 {
+				return fmt.Errorf("network %s publishes no seeds or peers", n.Name)
+			}
+
+			c := &Crawler{
+				ChainID:      n.ChainID,
+				Depth:        *depth,
+				MaxPeers:     *maxPeers,
+				Concurrency:  *concurrency,
+				Timeout:      *timeout,
+				AllowPrivate: *allowPriv,
+			}
+			peers := c.Crawl(ctx, start)
+			ranked := Rank(peers, n.ChainID)
+
+			if *out != "" {
+				if err := writePeers(*out, ranked, *limit); err != nil {
+					return err
+				}
+			}
+			if format.JSON() {
+				return cli.WriteJSON(env.Stdout, peers)
+			}
+			return writeTable(env.Stdout, peers)
+		}
+	},
+}
+
+// Crawler crawls a network over PEX.
+type Crawler struct {
+	ChainID     string
+	Depth       int
+	MaxPeers    int
+	Concurrency int
+	Timeout     time.Duration
+
+	// AllowPrivate also crawls learned addresses other nodes on the
+	// internet cannot dial.
+	AllowPrivate bool
+
+	key     ed25519.PrivKey
+	keyOnce sync.Once
+}
+
+type job struct {
+	addr  *p2p.NetAddress
+	depth int
+}
+
+// Crawl dials the start addresses ("nodeID@host:port") and the addresses
+// they return, breadth-first, and returns one Peer per dialed address.
+func (c *Crawler) Crawl(ctx context.Context, start []string) []*Peer {
+	c.keyOnce.Do(c.genKey)
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		seen  = map[p2p.ID]bool{}
+		peers []*Peer
+		sem   = make(chan struct{}, c.Concurrency)
+	)
+
+	var enqueue func(j job)
+	enqueue = func(j job) {
+		mu.Lock()
+		if seen[j.addr.ID] || len(seen) >= c.MaxPeers {
+			mu.Unlock()
+			return
+		}
+		seen[j.addr.ID] = true
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			p, learned := c.visit(ctx, j, true)
+			<-sem
+
+			mu.Lock()
+			peers = append(peers, p)
+			mu.Unlock()
+
+			if j.depth < c.Depth {
+				for _, a := range c.crawlable(learned) {
+					enqueue(job{addr: a, depth: j.depth + 1})
+				}
+			}
+		}()
+	}
+
+	for _, s := range start {
+		addr, err := p2p.NewNetAddressString(s)
+		if err != nil {
+			peers = append(peers, &Peer{Address: s, Error: err.Error()})
+			continue
+		}
+		enqueue(job{addr: addr})
+	}
+	wg.Wait()
+
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].Depth != peers[j].Depth {
+			return peers[i].Depth < peers[j].Depth
+		}
+		return peers[i].String() < peers[j].String()
+	})
+	return peers
+}
+
+// crawlable returns the learned addresses worth dialing: the publicly
+// routable ones, or all of them with AllowPrivate. Peers advertise the
+// private addresses of their neighbors as readily as public ones.
+func (c *Crawler) crawlable(addrs []*p2p.NetAddress) []*p2p.NetAddress {
+	if c.AllowPrivate {
+		return addrs
+	}
+	var out []*p2p.NetAddress
+	for _, a := range addrs {
+		if networks.CheckPublic(a.DialString()) == nil {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// Probe performs the handshake with peer ("nodeID@host:port") without
+// asking it for addresses.
+func (c *Crawler) Probe(ctx context.Context, peer string) *Peer {
+	c.keyOnce.Do(c.genKey)
+	addr, err := p2p.NewNetAddressString(peer)
+	if err != nil {
+		return &Peer{Address: peer, Error: err.Error()}
+	}
+	p, _ := c.visit(ctx, job{addr: addr}, false)
+	return p
+}
+
+func (c *Crawler) genKey() { c.key = ed25519.GenPrivKey() }
+
+func (c *Crawler) visit(ctx context.Context, j job, pex bool) (*Peer, []*p2p.NetAddress) {
+	p := &Peer{
+		ID:      string(j.addr.ID),
+		Address: net.JoinHostPort(j.addr.IP.String(), strconv.Itoa(int(j.addr.Port))),
+		Depth:   j.depth,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	started := time.Now()
+	sc, info, err := c.handshake(ctx, j.addr)
+	if err != nil {
+		p.Error = err.Error()
+		return p, nil
+	}
+	defer sc.Close()
+
+	p.Latency = time.Since(started)
+	p.Reachable = true
+	p.Network = info.Network
+	p.Moniker = info.Moniker
+	p.Version = info.Version
+
+	if info.Network != c.ChainID {
+		p.Error = fmt.Sprintf("peer is on chain %q", info.Network)
+		return p, nil
+	}
+	if !pex {
+		return p, nil
+	}
+
+	addrs, err := requestAddrs(ctx, sc)
+	if err != nil {
+		p.Error = "pex: " + err.Error()
+	}
+	p.Learned = len(addrs)
+	return p, addrs
+}
+
+// handshake dials addr, upgrades to a secret connection authenticated by
+// addr's node ID and exchanges node infos.
+func (c *Crawler) handshake(ctx context.Context, addr *p2p.NetAddress) (*conn.SecretConnection, p2p.DefaultNodeInfo, error) {
+	var d net.Dialer
+	raw, err := d.DialContext(ctx, "tcp", addr.DialString())
+	if err != nil {
+		return nil, p2p.DefaultNodeInfo{}, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = raw.SetDeadline(deadline)
+	}
+
+	sc, err := conn.MakeSecretConnection(raw, c.key)
+	if err != nil {
+		raw.Close()
+		return nil, p2p.DefaultNodeInfo{}, fmt.Errorf("secret connection: %w", err)
+	}
+	if got := p2p.PubKeyToID(sc.RemotePubKey()); got != addr.ID {
+		sc.Close()
+		return nil, p2p.DefaultNodeInfo{}, fmt.Errorf("peer authenticated as %s", got)
+	}
+
+	ours := p2p.DefaultNodeInfo{
+		ProtocolVersion: p2p.NewProtocolVersion(version.P2PProtocol, version.BlockProtocol, 0),
+		DefaultNodeID:   p2p.PubKeyToID(c.key.PubKey()),
+		ListenAddr:      "tcp://0.0.0.0:26656",
+		Network:         c.ChainID,
+		Version:         version.TMCoreSemVer,
+		Channels:        []byte{pexChannel},
+		Moniker:         "wardennet-peer-crawl",
+		Other:           p2p.DefaultNodeInfoOther{TxIndex: "off"},
+	}
+
+	errc := make(chan error, 2)
+	var theirs tmp2p.DefaultNodeInfo
+	go func() {
+		_, err := protoio.NewDelimitedWriter(sc).WriteMsg(ours.ToProto())
+		errc <- err
+	}()
+	go func() {
+		_, err := protoio.NewDelimitedReader(sc, p2p.MaxNodeInfoSize()).ReadMsg(&theirs)
+		errc <- err
+	}()
+	for i := 0; i < 2; i++ {
+		if err := <-errc; err != nil {
+			sc.Close()
+			return nil, p2p.DefaultNodeInfo{}, fmt.Errorf("node info exchange: %w", err)
+		}
+	}
+
+	info, err := p2p.DefaultNodeInfoFromToProto(&theirs)
+	if err != nil {
+		sc.Close()
+		return nil, p2p.DefaultNodeInfo{}, err
+	}
+	return sc, info, nil
+}
+
+// requestAddrs sends a PEX request over an established connection and waits
+// for the peer's address list.
+func requestAddrs(ctx context.Context, sc *conn.SecretConnection) ([]*p2p.NetAddress, error) {
+	type result struct {
+		addrs []*p2p.NetAddress
+		err   error
+	}
+	done := make(chan result, 1)
+	deliver := func(r result) {
+		select {
+		case done <- r:
+		default:
+		}
+	}
+
+	onReceive := func(chID byte, msgBytes []byte) {
+		var msg tmp2p.Message
+		if err := proto.Unmarshal(msgBytes, &msg); err != nil {
+			deliver(result{err: err})
+			return
+		}
+		addrs, ok := msg.Sum.(*tmp2p.Message_PexAddrs)
+		if !ok {
+			// The peer may ask for our addresses too; we have none.
+			return
+		}
+		netAddrs, errs := p2p.NetAddressesFromProto(addrs.PexAddrs.Addrs)
+		if len(netAddrs) == 0 && errs != nil {
+			deliver(result{err: errs})
+			return
+		}
+		deliver(result{addrs: netAddrs})
+	}
+	onError := func(r any) {
+		deliver(result{err: fmt.Errorf("connection error: %v", r)})
+	}
+
+	// The deadline set for the handshake also bounds the PEX exchange.
+	mconn := conn.NewMConnection(sc, []*conn.ChannelDescriptor{{
+		ID:                  pexChannel,
+		Priority:            1,
+		SendQueueCapacity:   10,
+		RecvMessageCapacity: 256 * 1024,
+		MessageType:         &tmp2p.Message{},
+	}}, onReceive, onError)
+	mconn.SetLogger(log.NewNopLogger())
+	if err := mconn.Start(); err != nil {
+		return nil, err
+	}
+	defer func() { _ = mconn.Stop() }()
+
+	req, err := proto.Marshal(&tmp2p.Message{Sum: &tmp2p.Message_PexRequest{PexRequest: &tmp2p.PexRequest{}}})
+	if err != nil {
+		return nil, err
+	}
+	if !mconn.Send(pexChannel, req) {
+		return nil, errors.New("failed to send PEX request")
+	}
+
+	select {
+	case r := <-done:
+		return r.addrs, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Rank returns the reachable peers on chainID, fastest handshake first.
+func Rank(peers []*Peer, chainID string) []*Peer {
+	var ranked []*Peer
+	for _, p := range peers {
+		if p.Reachable && p.Network == chainID {
+			ranked = append(ranked, p)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Latency < ranked[j].Latency })
+	return ranked
+}
+
+// writePeers writes up to limit ranked peers to path. It refuses to replace
+// the file with an empty list when no peer was reachable.
+func writePeers(path string, ranked []*Peer, limit int) error {
+	if len(ranked) == 0 {
+		return fmt.Errorf("no reachable peers, not writing %s", path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	for i, p := range ranked {
+		if i == limit {
+			break
+		}
+		fmt.Fprintln(f, p.String())
+	}
+	return f.Close()
+}
+
+func writeTable(w io.Writer, peers []*Peer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PEER\tDEPTH\tREACHABLE\tLATENCY\tMONIKER\tVERSION\tLEARNED\tERROR")
+	for _, p := range peers {
+		latency := "-"
+		if p.Reachable {
+			latency = p.Latency.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%t\t%s\t%s\t%s\t%d\t%s\n",
+			p.String(), p.Depth, p.Reachable, latency, p.Moniker, p.Version, p.Learned, p.Error)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,92 @@
+package peercrawl
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cometbft/cometbft/p2p"
+)
+
+func TestRank(t *testing.T) {
+	peers := []*Peer{
+		{ID: "slow", Reachable: true, Network: "chiado_10010-1", Latency: 300 * time.Millisecond},
+		{ID: "down", Reachable: false, Network: "", Latency: 0},
+		{ID: "fast", Reachable: true, Network: "chiado_10010-1", Latency: 20 * time.Millisecond},
+		{ID: "other-chain", Reachable: true, Network: "barra_9191-1", Latency: time.Millisecond},
+		{ID: "medium", Reachable: true, Network: "chiado_10010-1", Latency: 100 * time.Millisecond},
+	}
+	var got []string
+	for _, p := range Rank(peers, "chiado_10010-1") {
+		got = append(got, p.ID)
+	}
+	if want := "fast,medium,slow"; strings.Join(got, ",") != want {
+		t.Errorf("Rank() = %v, want %s", got, want)
+	}
+}
+
+func TestWritePeers(t *testing.T) {
+	ranked := []*Peer{
+		{ID: "a", Address: "203.0.113.1:26656"},
+		{ID: "b", Address: "203.0.113.2:26656"},
+		{ID: "c", Address: "203.0.113.3:26656"},
+	}
+	tests := []struct {
+		name   string
+		ranked []*Peer
+		limit  int
+		want   string // file content, or the error
+	}{
+		{"under limit", ranked, 5, "a@203.0.113.1:26656\nb@203.0.113.2:26656\nc@203.0.113.3:26656\n"},
+		{"limit", ranked, 2, "a@203.0.113.1:26656\nb@203.0.113.2:26656\n"},
+		{"no peers", nil, 5, "no reachable peers"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "peer-nodes.txt")
+			if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := writePeers(path, tt.ranked, tt.limit)
+			data, _ := os.ReadFile(path)
+			if err != nil {
+				if !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("writePeers() error = %v, want %q", err, tt.want)
+				}
+				if string(data) != "old\n" {
+					t.Errorf("file was modified on error: %q", data)
+				}
+				return
+			}
+			if string(data) != tt.want {
+				t.Errorf("file = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlable(t *testing.T) {
+	const id = "4b41a522de2124e719227622fa3cb65dd6745d20"
+	var addrs []*p2p.NetAddress
+	for _, host := range []string{"203.0.113.7", "10.1.8.90", "127.0.0.1", "192.168.1.2", "169.254.1.1", "2001:db8::1", "fe80::1"} {
+		a, err := p2p.NewNetAddressString(id + "@" + net.JoinHostPort(host, "26656"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, a)
+	}
+
+	var got []string
+	for _, a := range (&Crawler{}).crawlable(addrs) {
+		got = append(got, a.IP.String())
+	}
+	if want := "203.0.113.7,2001:db8::1"; strings.Join(got, ",") != want {
+		t.Errorf("crawlable() = %v, want %s", got, want)
+	}
+	if got := (&Crawler{AllowPrivate: true}).crawlable(addrs); len(got) != len(addrs) {
+		t.Errorf("crawlable() with AllowPrivate kept %d of %d addresses", len(got), len(addrs))
+	}
+}
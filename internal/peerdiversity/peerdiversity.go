@@ -0,0 +1,481 @@
+// Package peerdiversity implements the peer-diversity command, which reports
+// how the published peers of a network are spread over countries, regions
+// and hosting providers.
+//
+// Every peer address is resolved to an IP and located with one of two
+// keyless sources: Team Cymru's IP-to-ASN DNS service (-geo cymru, the
+// default; ASN, provider and registry country) or the ip-api.com batch API
+// (-geo ip-api; adds region and city). The report lists each peer and the
+// share of peers per country, region and provider, and warns when one of
+// them holds more than -max-share of the peers, so the published set can be
+// curated for resilience and not just liveness. A node listed twice, by node
+// ID or IP, is only counted once.
+//
+// Usage:
+//
+//	peer-diversity alfama
+//	peer-diversity -geo ip-api -max-share 0.5 -check buenavista
+//	peer-crawl -out peers.txt alfama && peer-diversity -file peers.txt
+package peerdiversity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// Location is where an IP address is hosted.
+type Location struct {
+	Country  string `json:"country,omitempty"`
+	Region   string `json:"region,omitempty"`
+	City     string `json:"city,omitempty"`
+	ASN      string `json:"asn,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// Locator looks up the location of IP addresses. IPs it cannot locate are
+// missing from the result.
+type Locator interface {
+	Locate(ctx context.Context, ips []string) (map[string]*Location, error)
+}
+
+// Peer is a located peer.
+type Peer struct {
+	Peer     string    `json:"peer"`
+	IP       string    `json:"ip,omitempty"`
+	Location *Location `json:"location,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Share is the number of peers sharing a country, region or provider.
+type Share struct {
+	Key     string  `json:"key"`
+	Peers   int     `json:"peers"`
+	Percent float64 `json:"percent"`
+}
+
+// Report is the output of the command.
+type Report struct {
+	Peers     []*Peer  `json:"peers"`
+	Located   int      `json:"located"`
+	Countries []Share  `json:"countries"`
+	Regions   []Share  `json:"regions"`
+	Providers []Share  `json:"providers"`
+	Warnings  []string `json:"warnings"`
+}
+
+// Command is the peer-diversity command.
+var Command = &cli.Command{
+	Name:  "peer-diversity",
+	Args:  "<network> | -file <peers.txt>",
+	Short: "Report the geographic and hosting-provider concentration of a network's peers.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format   = cli.FormatFlag(fs, "text", "json")
+			file     = fs.String("file", "", "read peers from this file (e.g. the output of peer-crawl -out) instead of a network")
+			seeds    = fs.Bool("seeds", true, "include the network's seeds")
+			geo      = fs.String("geo", "cymru", "location source: cymru (DNS, ASN and country) or ip-api (HTTP, adds region and city)")
+			maxShare = fs.Float64("max-share", 0.33, "warn when a country, region or provider holds more than this fraction of the peers")
+			check    = fs.Bool("check", false, "exit non-zero when there are concentration warnings")
+			timeout  = fs.Duration("timeout", 30*time.Second, "timeout for resolving and locating all peers")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			var peers []string
+			switch {
+			case *file != "" && len(args) == 0:
+				var err error
+				if peers, err = networks.ReadLines(*file); err != nil {
+					return err
+				}
+			case *file == "" && len(args) == 1:
+				dir, err := network.Resolve(env.Root, args[0])
+				if err != nil {
+					return err
+				}
+				n, err := network.Load(dir)
+				if err != nil {
+					return err
+				}
+				peers = append(peers, n.Peers...)
+				if *seeds {
+					peers = append(peers, n.Seeds...)
+				}
+			default:
+				return cli.ErrUsage
+			}
+			if len(peers) == 0 {
+				return errors.New("no peers to locate")
+			}
+
+			var loc Locator
+			switch *geo {
+			case "cymru":
+				loc = &Cymru{}
+			case "ip-api":
+				loc = &IPAPI{Client: http.DefaultClient}
+			default:
+				return fmt.Errorf("unknown -geo source %q", *geo)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, *timeout)
+			defer cancel()
+			report, err := Locate(ctx, loc, peers, *maxShare)
+			if err != nil {
+				return err
+			}
+
+			if format.JSON() {
+				err = cli.WriteJSON(env.Stdout, report)
+			} else {
+				err = writeText(env.Stdout, report)
+			}
+			if err != nil {
+				return err
+			}
+			if *check && len(report.Warnings) > 0 {
+				return fmt.Errorf("%d concentration warning(s)", len(report.Warnings))
+			}
+			return nil
+		}
+	},
+}
+
+// Locate resolves and locates peers ("nodeID@host:port") and computes the
+// concentration shares over the located ones. A peer with the node ID or IP
+// of an earlier one, such as a node published both as a peer and a seed, is
+// reported as a duplicate and left out of the shares.
+func Locate(ctx context.Context, loc Locator, peers []string, maxShare float64) (*Report, error) {
+	r := &Report{Peers: []*Peer{}, Warnings: []string{}}
+	var ips []string
+	byID, byIP := map[string]string{}, map[string]string{}
+	for _, s := range peers {
+		p := &Peer{Peer: s}
+		r.Peers = append(r.Peers, p)
+
+		id, addr, err := networks.ParsePeer(s)
+		if err != nil {
+			p.Error = err.Error()
+			continue
+		}
+		if first, ok := byID[id]; ok {
+			p.Error = "duplicate node ID of " + first
+			continue
+		}
+		byID[id] = s
+		host, _, _ := net.SplitHostPort(addr)
+		if ip := net.ParseIP(host); ip != nil {
+			p.IP = ip.String()
+		} else {
+			addrs, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+			if err != nil {
+				p.Error = err.Error()
+				continue
+			}
+			p.IP = addrs[0].String()
+		}
+		if first, ok := byIP[p.IP]; ok {
+			p.Error = "duplicate IP of " + first
+			continue
+		}
+		byIP[p.IP] = s
+		ips = append(ips, p.IP)
+	}
+
+	locations, err := loc.Locate(ctx, ips)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) > 0 && len(locations) == 0 {
+		return nil, fmt.Errorf("none of the %d peer IPs could be located", len(ips))
+	}
+
+	countries, regions, providers := map[string]int{}, map[string]int{}, map[string]int{}
+	for _, p := range r.Peers {
+		if p.IP == "" || p.Error != "" {
+			continue
+		}
+		l, ok := locations[p.IP]
+		if !ok {
+			p.Error = "not located"
+			continue
+		}
+		p.Location = l
+		r.Located++
+		if l.Country != "" {
+			countries[l.Country]++
+		}
+		if l.Region != "" {
+			regions[l.Country+"/"+l.Region]++
+		}
+		if key := l.providerKey(); key != "" {
+			providers[key]++
+		}
+	}
+
+	r.Countries = shares(countries, r.Located)
+	r.Regions = shares(regions, r.Located)
+	r.Providers = shares(providers, r.Located)
+
+	if maxShare > 0 && r.Located > 1 {
+		for _, group := range []struct {
+			what   string
+			shares []Share
+		}{{"country", r.Countries}, {"region", r.Regions}, {"provider", r.Providers}} {
+			for _, s := range group.shares {
+				if s.Percent > 100*maxShare {
+					r.Warnings = append(r.Warnings, fmt.Sprintf("%.0f%% of peers (%d of %d) in %s %s", s.Percent, s.Peers, r.Located, group.what, s.Key))
+				}
+			}
+		}
+	}
+	return r, nil
+}
+
+func (l *Location) providerKey() string {
+	switch {
+	case l.ASN != "" && l.Provider != "":
+		return l.ASN + " " + l.Provider
+	case l.ASN != "":
+		return l.ASN
+	}
+	return l.Provider
+}
+
+// shares returns the share of each key over total, largest first.
+func shares(counts map[string]int, total int) []Share {
+	list := make([]Share, 0, len(counts))
+	for k, n := range counts {
+		list = append(list, Share{Key: k, Peers: n, Percent: 100 * float64(n) / float64(total)})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Peers != list[j].Peers {
+			return list[i].Peers > list[j].Peers
+		}
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+// Cymru locates IPs with the Team Cymru IP-to-ASN DNS service.
+type Cymru struct {
+	// Resolver defaults to net.DefaultResolver.
+	Resolver *net.Resolver
+}
+
+// Locate implements Locator. IPs without an origin record are left out;
+// any other lookup error fails the whole batch, so a broken resolver is not
+// mistaken for unannounced addresses.
+func (c *Cymru) Locate(ctx context.Context, ips []string) (map[string]*Location, error) {
+	res := c.Resolver
+	if res == nil {
+		res = net.DefaultResolver
+	}
+
+	out := map[string]*Location{}
+	names := map[string]string{}
+	for _, s := range ips {
+		if _, ok := out[s]; ok {
+			continue
+		}
+		name, ok := cymruOriginName(s)
+		if !ok {
+			continue
+		}
+		txt, err := res.LookupTXT(ctx, name)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				continue
+			}
+			return nil, fmt.Errorf("locate %s: %w", s, err)
+		}
+		if len(txt) == 0 {
+			continue
+		}
+		// "13335 | 1.1.1.0/24 | AU | apnic | 2011-08-11"; multi-origin
+		// prefixes list several ASNs in the first field.
+		f := cymruFields(txt[0])
+		if len(f) < 3 || len(strings.Fields(f[0])) == 0 {
+			continue
+		}
+		asn := strings.Fields(f[0])[0]
+		l := &Location{ASN: "AS" + asn, Country: f[2]}
+		if name, ok := names[asn]; ok {
+			l.Provider = name
+		} else if txt, err := res.LookupTXT(ctx, "AS"+asn+".asn.cymru.com"); err == nil && len(txt) > 0 {
+			// "13335 | US | arin | 2010-07-14 | CLOUDFLARENET - Cloudflare, Inc., US"
+			if f := cymruFields(txt[0]); len(f) >= 5 {
+				l.Provider = f[4]
+			}
+			names[asn] = l.Provider
+		}
+		out[s] = l
+	}
+	return out, nil
+}
+
+func cymruFields(s string) []string {
+	f := strings.Split(s, "|")
+	for i := range f {
+		f[i] = strings.TrimSpace(f[i])
+	}
+	return f
+}
+
+// cymruOriginName returns the origin lookup name of an IP: the reversed
+// octets of IPv4 addresses under origin.asn.cymru.com, the reversed
+// nibbles of IPv6 ones under origin6.asn.cymru.com.
+func cymruOriginName(s string) (string, bool) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return "", false
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.origin.asn.cymru.com", v4[3], v4[2], v4[1], v4[0]), true
+	}
+	var b strings.Builder
+	for i := len(ip) - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "%x.%x.", ip[i]&0xf, ip[i]>>4)
+	}
+	return b.String() + "origin6.asn.cymru.com", true
+}
+
+// IPAPI locates IPs with the ip-api.com batch API. The free endpoint is
+// rate limited and only served over plain HTTP.
+type IPAPI struct {
+	Client *http.Client
+	// URL defaults to http://ip-api.com/batch.
+	URL string
+}
+
+// ipAPIBatchSize is the maximum number of queries per batch request.
+const ipAPIBatchSize = 100
+
+// Locate implements Locator.
+func (a *IPAPI) Locate(ctx context.Context, ips []string) (map[string]*Location, error) {
+	url := a.URL
+	if url == "" {
+		url = "http://ip-api.com/batch"
+	}
+
+	out := map[string]*Location{}
+	for len(ips) > 0 {
+		batch := ips
+		if len(batch) > ipAPIBatchSize {
+			batch = batch[:ipAPIBatchSize]
+		}
+		ips = ips[len(batch):]
+
+		type query struct {
+			Query  string `json:"query"`
+			Fields string `json:"fields"`
+		}
+		queries := make([]query, len(batch))
+		for i, ip := range batch {
+			queries[i] = query{Query: ip, Fields: "status,message,countryCode,regionName,city,isp,as,query"}
+		}
+		body, err := json.Marshal(queries)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := a.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("POST %s: %s", url, resp.Status)
+		}
+
+		var results []struct {
+			Status      string `json:"status"`
+			Message     string `json:"message"`
+			CountryCode string `json:"countryCode"`
+			RegionName  string `json:"regionName"`
+			City        string `json:"city"`
+			ISP         string `json:"isp"`
+			AS          string `json:"as"`
+			Query       string `json:"query"`
+		}
+		if err := json.Unmarshal(data, &results); err != nil {
+			return nil, fmt.Errorf("decode ip-api response: %w", err)
+		}
+		for _, r := range results {
+			if r.Status != "success" {
+				continue
+			}
+			l := &Location{Country: r.CountryCode, Region: r.RegionName, City: r.City, Provider: r.ISP}
+			// "AS14061 DigitalOcean, LLC"
+			if asn, _, ok := strings.Cut(r.AS, " "); ok && strings.HasPrefix(asn, "AS") {
+				l.ASN = asn
+			}
+			out[r.Query] = l
+		}
+	}
+	return out, nil
+}
+
+func writeText(w io.Writer, r *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PEER\tIP\tCOUNTRY\tREGION\tCITY\tPROVIDER\tERROR")
+	for _, p := range r.Peers {
+		l := p.Location
+		if l == nil {
+			l = &Location{}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Peer, p.IP, l.Country, l.Region, l.City, l.providerKey(), p.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, group := range []struct {
+		title  string
+		shares []Share
+	}{{"Countries", r.Countries}, {"Regions", r.Regions}, {"Providers", r.Providers}} {
+		if len(group.shares) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d located peers)\n", group.title, r.Located)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, s := range group.shares {
+			fmt.Fprintf(tw, "  %s\t%d\t%s%%\n", s.Key, s.Peers, strconv.FormatFloat(s.Percent, 'f', 1, 64))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Warnings) > 0 {
+		fmt.Fprintln(w, "\nWarnings")
+		for _, s := range r.Warnings {
+			fmt.Fprintf(w, "  %s\n", s)
+		}
+	}
+	return nil
+}
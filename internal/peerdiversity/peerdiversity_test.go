@@ -0,0 +1,186 @@
+package peerdiversity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeLocator locates the IPs in its map.
+type fakeLocator map[string]*Location
+
+func (f fakeLocator) Locate(ctx context.Context, ips []string) (map[string]*Location, error) {
+	out := map[string]*Location{}
+	for _, ip := range ips {
+		if l, ok := f[ip]; ok {
+			out[ip] = l
+		}
+	}
+	return out, nil
+}
+
+func peer(id byte, addr string) string {
+	return strings.Repeat(string(id), 40) + "@" + addr
+}
+
+func TestLocate(t *testing.T) {
+	de := &Location{Country: "DE", ASN: "AS24940", Provider: "HETZNER-AS"}
+	fi := &Location{Country: "FI", ASN: "AS24940", Provider: "HETZNER-AS"}
+	us := &Location{Country: "US", ASN: "AS16509", Provider: "AMAZON-02"}
+	loc := fakeLocator{"192.0.2.1": de, "192.0.2.2": de, "192.0.2.3": fi, "192.0.2.4": us}
+
+	peers := []string{
+		peer('a', "192.0.2.1:26656"),
+		peer('b', "192.0.2.2:26656"),
+		peer('c', "192.0.2.3:26656"),
+		peer('d', "192.0.2.4:26656"),
+		peer('a', "192.0.2.9:26656"), // same node on another address
+		peer('e', "192.0.2.1:26657"), // same IP as the first peer
+		peer('f', "192.0.2.5:26656"), // not located
+		"not-a-peer",
+	}
+	r, err := Locate(context.Background(), loc, peers, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Located != 4 {
+		t.Errorf("got %d located peers, want 4", r.Located)
+	}
+	wantErrors := []string{"", "", "", "", "duplicate node ID of " + peers[0], "duplicate IP of " + peers[0], "not located", "expected nodeID@host:port"}
+	for i, p := range r.Peers {
+		if p.Error != wantErrors[i] {
+			t.Errorf("%s: got error %q, want %q", p.Peer, p.Error, wantErrors[i])
+		}
+	}
+
+	wantProviders := []Share{{"AS24940 HETZNER-AS", 3, 75}, {"AS16509 AMAZON-02", 1, 25}}
+	if !reflect.DeepEqual(r.Providers, wantProviders) {
+		t.Errorf("got providers %+v, want %+v", r.Providers, wantProviders)
+	}
+	wantCountries := []Share{{"DE", 2, 50}, {"FI", 1, 25}, {"US", 1, 25}}
+	if !reflect.DeepEqual(r.Countries, wantCountries) {
+		t.Errorf("got countries %+v, want %+v", r.Countries, wantCountries)
+	}
+	wantWarnings := []string{"75% of peers (3 of 4) in provider AS24940 HETZNER-AS"}
+	if !reflect.DeepEqual(r.Warnings, wantWarnings) {
+		t.Errorf("got warnings %q, want %q", r.Warnings, wantWarnings)
+	}
+}
+
+func TestLocateNothingLocated(t *testing.T) {
+	_, err := Locate(context.Background(), fakeLocator{}, []string{peer('a', "192.0.2.1:26656")}, 0.5)
+	if err == nil || !strings.Contains(err.Error(), "none of the 1 peer IPs could be located") {
+		t.Errorf("Locate() error = %v, want none located", err)
+	}
+}
+
+func TestShares(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts map[string]int
+		total  int
+		want   []Share
+	}{
+		{"empty", map[string]int{}, 0, []Share{}},
+		{"largest first", map[string]int{"DE": 1, "US": 3}, 4, []Share{{"US", 3, 75}, {"DE", 1, 25}}},
+		{"ties by key", map[string]int{"US": 1, "DE": 1, "FI": 2}, 4, []Share{{"FI", 2, 50}, {"DE", 1, 25}, {"US", 1, 25}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shares(tt.counts, tt.total); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shares() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCymruOriginName(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+		ok   bool
+	}{
+		{"1.2.3.4", "4.3.2.1.origin.asn.cymru.com", true},
+		{"::ffff:1.2.3.4", "4.3.2.1.origin.asn.cymru.com", true},
+		{"2001:db8::1", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.origin6.asn.cymru.com", true},
+		{"2a01:4f8:c17:b8f::2", "2.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.f.8.b.0.7.1.c.0.8.f.4.0.1.0.a.2.origin6.asn.cymru.com", true},
+		{"seed.example.org", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := cymruOriginName(tt.ip)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("cymruOriginName(%q) = %q, %t, want %q, %t", tt.ip, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIPAPILocate(t *testing.T) {
+	var batches []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST only", http.StatusMethodNotAllowed)
+			return
+		}
+		var queries []struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&queries); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		batches = append(batches, len(queries))
+		var results []map[string]string
+		for _, q := range queries {
+			if q.Query == "192.0.2.255" {
+				results = append(results, map[string]string{"status": "fail", "message": "reserved range", "query": q.Query})
+				continue
+			}
+			results = append(results, map[string]string{
+				"status": "success", "countryCode": "DE", "regionName": "Bavaria", "city": "Nuremberg",
+				"isp": "Hetzner Online GmbH", "as": "AS24940 Hetzner Online GmbH", "query": q.Query,
+			})
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	}))
+	defer srv.Close()
+
+	var ips []string
+	for i := 0; i < 150; i++ {
+		ips = append(ips, fmt.Sprintf("198.51.100.%d", i))
+	}
+	ips = append(ips, "192.0.2.255")
+
+	a := &IPAPI{Client: srv.Client(), URL: srv.URL}
+	got, err := a.Locate(context.Background(), ips)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(batches, []int{100, 51}) {
+		t.Errorf("got batches of %v, want [100 51]", batches)
+	}
+	if len(got) != 150 {
+		t.Errorf("located %d IPs, want 150", len(got))
+	}
+	want := &Location{Country: "DE", Region: "Bavaria", City: "Nuremberg", ASN: "AS24940", Provider: "Hetzner Online GmbH"}
+	if l := got["198.51.100.7"]; !reflect.DeepEqual(l, want) {
+		t.Errorf("got location %+v, want %+v", l, want)
+	}
+	if _, ok := got["192.0.2.255"]; ok {
+		t.Error("a failed query was located")
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer down.Close()
+	a = &IPAPI{Client: down.Client(), URL: down.URL}
+	if _, err := a.Locate(context.Background(), ips[:1]); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("Locate() error = %v, want the HTTP status", err)
+	}
+}
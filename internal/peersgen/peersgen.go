@@ -0,0 +1,225 @@
+// Package peersgen implements the peers-gen command, which derives a
+// network's persistent peers from the node addresses validators put in the
+// memo of their gentx.
+//
+// Every gentx memo is expected to be "nodeID@host:port". Memos that do not
+// parse, and by default those pointing at private, loopback or link-local
+// addresses, are skipped with a reason. The remaining peers are deduplicated
+// by node ID and address and printed as a persistent_peers config line; -out
+// also writes them one per line in the peer-nodes.txt format.
+//
+// Usage:
+//
+//	peers-gen alfama
+//	peers-gen -out testnets/alfama/peer-nodes.txt -format json alfama
+//	peers-gen -from-genesis alfama
+package peersgen
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// Peer is a node address taken from a gentx memo.
+type Peer struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+	Source  string `json:"source"`
+}
+
+func (p Peer) String() string { return p.ID + "@" + p.Address }
+
+// Skipped is a memo that did not yield a peer.
+type Skipped struct {
+	Source string `json:"source"`
+	Memo   string `json:"memo"`
+	Reason string `json:"reason"`
+}
+
+// Result is the output of the command.
+type Result struct {
+	PersistentPeers string    `json:"persistent_peers"`
+	Peers           []Peer    `json:"peers"`
+	Skipped         []Skipped `json:"skipped"`
+}
+
+// Command is the peers-gen command.
+var Command = &cli.Command{
+	Name:  "peers-gen",
+	Args:  "<network>",
+	Short: "Derive persistent_peers from the node addresses in the network's gentx memos.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format       = cli.FormatFlag(fs, "text", "json")
+			out          = fs.String("out", "", "also write the peers, one per line, to this file")
+			allowPrivate = fs.Bool("allow-private", false, "keep peers on private, loopback and link-local addresses")
+			fromGenesis  = fs.Bool("from-genesis", false, "read the gentxs collected in the network's genesis instead of its gentx directory")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if len(args) != 1 {
+				return cli.ErrUsage
+			}
+			dir, err := network.Resolve(env.Root, args[0])
+			if err != nil {
+				return err
+			}
+
+			var memos []memo
+			if *fromGenesis {
+				n, err := network.Load(dir)
+				if err != nil {
+					return err
+				}
+				if n.Genesis == "" {
+					return fmt.Errorf("network %s has no genesis file", n.Name)
+				}
+				memos, err = genesisMemos(n.Genesis)
+				if err != nil {
+					return err
+				}
+			} else {
+				memos, err = gentxMemos(filepath.Join(dir, "gentx"))
+				if err != nil {
+					return err
+				}
+			}
+
+			res := extract(memos, *allowPrivate)
+
+			if *out != "" {
+				if len(res.Peers) == 0 {
+					return fmt.Errorf("no usable peers in %d memos (%d skipped, see -allow-private), not writing %s", len(memos), len(res.Skipped), *out)
+				}
+				var b strings.Builder
+				for _, p := range res.Peers {
+					b.WriteString(p.String() + "\n")
+				}
+				if err := os.WriteFile(*out, []byte(b.String()), 0o644); err != nil {
+					return err
+				}
+			}
+
+			if format.JSON() {
+				return cli.WriteJSON(env.Stdout, res)
+			}
+			for _, s := range res.Skipped {
+				fmt.Fprintf(env.Stderr, "skipping %s (memo %q): %s\n", s.Source, s.Memo, s.Reason)
+			}
+			fmt.Fprintf(env.Stdout, "persistent_peers = %q\n", res.PersistentPeers)
+			return nil
+		}
+	},
+}
+
+type memo struct {
+	source string
+	text   string
+}
+
+type gentx struct {
+	Body struct {
+		Memo string `json:"memo"`
+	} `json:"body"`
+}
+
+func gentxMemos(dir string) ([]memo, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no gentx files in %s", dir)
+	}
+	sort.Strings(paths)
+
+	memos := make([]memo, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var tx gentx
+		if err := json.Unmarshal(data, &tx); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", p, err)
+		}
+		memos = append(memos, memo{source: filepath.Base(p), text: tx.Body.Memo})
+	}
+	return memos, nil
+}
+
+func genesisMemos(path string) ([]memo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var g struct {
+		AppState struct {
+			Genutil struct {
+				GenTxs []gentx `json:"gen_txs"`
+			} `json:"genutil"`
+		} `json:"app_state"`
+	}
+	if err := json.NewDecoder(f).Decode(&g); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	memos := make([]memo, 0, len(g.AppState.Genutil.GenTxs))
+	for i, tx := range g.AppState.Genutil.GenTxs {
+		memos = append(memos, memo{source: fmt.Sprintf("gen_txs[%d]", i), text: tx.Body.Memo})
+	}
+	return memos, nil
+}
+
+// extract parses memos into deduplicated peers, in input order.
+func extract(memos []memo, allowPrivate bool) *Result {
+	res := &Result{Peers: []Peer{}, Skipped: []Skipped{}}
+	seenID := map[string]string{}
+	seenAddr := map[string]string{}
+
+	for _, m := range memos {
+		var p Peer
+		id, addr, err := networks.ParsePeer(strings.TrimSpace(m.text))
+		if err == nil {
+			p = Peer{ID: id, Address: addr}
+		}
+		if err == nil && !allowPrivate {
+			err = networks.CheckPublic(p.Address)
+		}
+		if err == nil {
+			if prev, ok := seenID[p.ID]; ok {
+				err = fmt.Errorf("duplicate node id, already used by %s", prev)
+			} else if prev, ok := seenAddr[p.Address]; ok {
+				err = fmt.Errorf("duplicate address, already used by %s", prev)
+			}
+		}
+		if err != nil {
+			res.Skipped = append(res.Skipped, Skipped{Source: m.source, Memo: m.text, Reason: err.Error()})
+			continue
+		}
+
+		p.Source = m.source
+		seenID[p.ID] = m.source
+		seenAddr[p.Address] = m.source
+		res.Peers = append(res.Peers, p)
+	}
+
+	ids := make([]string, len(res.Peers))
+	for i, p := range res.Peers {
+		ids[i] = p.String()
+	}
+	res.PersistentPeers = strings.Join(ids, ",")
+	return res
+}
@@ -0,0 +1,46 @@
+package peersgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	const (
+		id1 = "4b41a522de2124e719227622fa3cb65dd6745d20"
+		id2 = "b0cf4b6ba2f8b1a8ab0ac9e8e6b2fc0e4a6b9d11"
+		id3 = "0c2ae5f8c7b4e2a9d1f0e3b6a8c9d7e5f4a3b2c1"
+	)
+	memos := []memo{
+		{"a.json", id1 + "@203.0.113.7:26656"},
+		{"b.json", " " + strings.ToUpper(id2) + "@node.example.org:26656\n"},
+		{"c.json", id3 + "@10.1.8.90:26656"},
+		{"d.json", id1 + "@203.0.113.8:26656"},
+		{"e.json", id3 + "@203.0.113.7:26656"},
+		{"f.json", "203.0.113.9:26656"},
+	}
+
+	res := extract(memos, false)
+	if want := id1 + "@203.0.113.7:26656," + id2 + "@node.example.org:26656"; res.PersistentPeers != want {
+		t.Errorf("PersistentPeers = %q, want %q", res.PersistentPeers, want)
+	}
+	wantSkipped := map[string]string{
+		"c.json": "private address 10.1.8.90",
+		"d.json": "duplicate node id, already used by a.json",
+		"e.json": "duplicate address, already used by a.json",
+		"f.json": "expected nodeID@host:port",
+	}
+	if len(res.Skipped) != len(wantSkipped) {
+		t.Fatalf("Skipped = %+v, want %d entries", res.Skipped, len(wantSkipped))
+	}
+	for _, s := range res.Skipped {
+		if !strings.Contains(s.Reason, wantSkipped[s.Source]) {
+			t.Errorf("%s skipped for %q, want %q", s.Source, s.Reason, wantSkipped[s.Source])
+		}
+	}
+
+	res = extract(memos, true)
+	if len(res.Peers) != 3 || res.Peers[2].Source != "c.json" {
+		t.Errorf("with allowPrivate, Peers = %+v, want c.json kept", res.Peers)
+	}
+}
@@ -0,0 +1,771 @@
+// Package registry implements the registry-gen command, which produces
+// cosmos/chain-registry compatible chain.json and assetlist.json files from
+// the network data in this repo, and validates existing ones.
+//
+// Generation reads the network directory (chain-id.txt, genesis.json,
+// peer-nodes.txt, seed-nodes.txt, rpc-nodes.txt, api-nodes.txt,
+// grpc-nodes.txt, evm-nodes.txt) and, when present, the existing chain.json
+// and assetlist.json. The generated fields are merged over the existing
+// files, so fields that cannot be derived from the repo (logos, explorers,
+// codebase versions, gas prices) and fields this tool does not model are
+// preserved. The SHA256 of genesis.json, which the registry schema has no
+// field for, is written next to it as genesis.sha256.
+//
+// The display unit of the staking denom comes from -display, the genesis
+// bank denom_metadata or the existing assetlist.json; it is never guessed
+// from the denom.
+//
+// -check validates the files against the chain-registry chain.schema.json
+// and assetlist.schema.json, embedded in the tool or read from a registry
+// checkout with -schema-dir, and checks that the two files agree.
+//
+// Usage:
+//
+//	registry-gen -display ward:6 alfama
+//	registry-gen -out /tmp/alfama -display ward:6 testnets/alfama
+//	registry-gen -check buenavista
+//	registry-gen -check -schema-dir ../chain-registry buenavista
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/jsonschema"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+const defaultBaseURL = "https://raw.githubusercontent.com/warden-protocol/networks/main/"
+
+// The chain-registry schemas files are validated against, unless -schema-dir
+// points at a registry checkout.
+var (
+	//go:embed chain.schema.json
+	chainSchema []byte
+	//go:embed assetlist.schema.json
+	assetListSchema []byte
+)
+
+type chainFile struct {
+	Schema       string          `json:"$schema"`
+	ChainName    string          `json:"chain_name"`
+	ChainType    string          `json:"chain_type,omitempty"`
+	Status       string          `json:"status"`
+	NetworkType  string          `json:"network_type"`
+	PrettyName   string          `json:"pretty_name"`
+	ChainID      string          `json:"chain_id"`
+	Bech32Prefix string          `json:"bech32_prefix"`
+	DaemonName   string          `json:"daemon_name"`
+	NodeHome     string          `json:"node_home"`
+	KeyAlgos     []string        `json:"key_algos"`
+	Slip44       int             `json:"slip44"`
+	Fees         fees            `json:"fees"`
+	Staking      staking         `json:"staking"`
+	Codebase     codebase        `json:"codebase"`
+	Peers        peers           `json:"peers"`
+	APIs         apis            `json:"apis"`
+	LogoURIs     json.RawMessage `json:"logo_URIs,omitempty"`
+	Explorers    json.RawMessage `json:"explorers,omitempty"`
+	Keywords     []string        `json:"keywords,omitempty"`
+	Images       json.RawMessage `json:"images,omitempty"`
+}
+
+type fees struct {
+	FeeTokens []feeToken `json:"fee_tokens"`
+}
+
+type feeToken struct {
+	Denom            string  `json:"denom"`
+	FixedMinGasPrice float64 `json:"fixed_min_gas_price"`
+	LowGasPrice      float64 `json:"low_gas_price"`
+	AverageGasPrice  float64 `json:"average_gas_price"`
+	HighGasPrice     float64 `json:"high_gas_price"`
+}
+
+type staking struct {
+	StakingTokens []struct {
+		Denom string `json:"denom"`
+	} `json:"staking_tokens"`
+}
+
+type codebase struct {
+	GitRepo            string          `json:"git_repo"`
+	RecommendedVersion string          `json:"recommended_version,omitempty"`
+	CompatibleVersions []string        `json:"compatible_versions,omitempty"`
+	CosmosSDKVersion   string          `json:"cosmos_sdk_version,omitempty"`
+	Consensus          json.RawMessage `json:"consensus,omitempty"`
+	CosmwasmEnabled    bool            `json:"cosmwasm_enabled"`
+	Genesis            struct {
+		GenesisURL string `json:"genesis_url"`
+	} `json:"genesis"`
+	Versions json.RawMessage `json:"versions,omitempty"`
+}
+
+type peer struct {
+	ID       string `json:"id"`
+	Address  string `json:"address"`
+	Provider string `json:"provider,omitempty"`
+}
+
+type peers struct {
+	Seeds           []peer `json:"seeds"`
+	PersistentPeers []peer `json:"persistent_peers"`
+}
+
+type endpoint struct {
+	Address  string `json:"address"`
+	Provider string `json:"provider,omitempty"`
+}
+
+type apis struct {
+	RPC  []endpoint `json:"rpc,omitempty"`
+	REST []endpoint `json:"rest,omitempty"`
+	GRPC []endpoint `json:"grpc,omitempty"`
+	EVM  []endpoint `json:"evm-http-jsonrpc,omitempty"`
+}
+
+type assetList struct {
+	Schema    string  `json:"$schema"`
+	ChainName string  `json:"chain_name"`
+	Assets    []asset `json:"assets"`
+}
+
+type asset struct {
+	Description string          `json:"description,omitempty"`
+	DenomUnits  []denomUnit     `json:"denom_units"`
+	Base        string          `json:"base"`
+	Name        string          `json:"name"`
+	Display     string          `json:"display"`
+	Symbol      string          `json:"symbol"`
+	LogoURIs    json.RawMessage `json:"logo_URIs,omitempty"`
+	Images      json.RawMessage `json:"images,omitempty"`
+	TypeAsset   string          `json:"type_asset,omitempty"`
+}
+
+type denomUnit struct {
+	Denom    string   `json:"denom"`
+	Exponent int      `json:"exponent"`
+	Aliases  []string `json:"aliases,omitempty"`
+}
+
+type options struct {
+	root       string
+	dir        string
+	out        string
+	baseURL    string
+	genesisURL string
+	chainName  string
+	provider   string
+	version    string
+	symbol     string
+	display    string
+}
+
+// Command is the registry-gen command.
+var Command = &cli.Command{
+	Name:  "registry-gen",
+	Args:  "<network>",
+	Short: "Generate or check chain-registry chain.json and assetlist.json for a network.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			opts      options
+			check     bool
+			schemaDir string
+		)
+		fs.StringVar(&opts.out, "out", "", "output directory (default: the network directory)")
+		fs.StringVar(&opts.baseURL, "base-url", defaultBaseURL, "URL the repository root is published under")
+		fs.StringVar(&opts.genesisURL, "genesis-url", "", "genesis URL, overrides the one derived from -base-url (required for networks outside the repository root)")
+		fs.StringVar(&opts.chainName, "chain-name", "", "chain-registry chain_name (default: from chain.json or derived from the network name)")
+		fs.StringVar(&opts.provider, "provider", "Warden Protocol", "provider recorded for peers and endpoints")
+		fs.StringVar(&opts.version, "version", "", "recommended wardend version")
+		fs.StringVar(&opts.symbol, "symbol", "", "asset symbol of the staking denom (default: from the denom metadata, or the display denom in upper case)")
+		fs.StringVar(&opts.display, "display", "", "display unit of the staking denom as denom:exponent, e.g. ward:6 (default: from the genesis denom_metadata or assetlist.json)")
+		fs.BoolVar(&check, "check", false, "validate chain.json and assetlist.json in the network directory instead of generating them")
+		fs.StringVar(&schemaDir, "schema-dir", "", "chain-registry checkout whose schemas -check uses instead of the embedded ones")
+		format := cli.FormatFlag(fs, "text", "json")
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if len(args) != 1 {
+				return cli.ErrUsage
+			}
+			dir, err := network.Resolve(env.Root, args[0])
+			if err != nil {
+				return err
+			}
+			opts.root, opts.dir = env.Root, dir
+			if opts.out == "" {
+				opts.out = opts.dir
+			}
+
+			if !check {
+				return generate(opts)
+			}
+
+			problems, err := checkDir(opts.dir, schemaDir)
+			if err != nil {
+				return err
+			}
+			if format.JSON() {
+				if problems == nil {
+					problems = []string{}
+				}
+				if err := cli.WriteJSON(env.Stdout, map[string][]string{"problems": problems}); err != nil {
+					return err
+				}
+			} else {
+				for _, p := range problems {
+					fmt.Fprintln(env.Stdout, p)
+				}
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("%d problem(s) found", len(problems))
+			}
+			return nil
+		}
+	},
+}
+
+func generate(opts options) error {
+	chain, err := buildChain(opts)
+	if err != nil {
+		return err
+	}
+	assets, err := buildAssetList(opts, chain)
+	if err != nil {
+		return err
+	}
+
+	sum, err := fileSHA256(filepath.Join(opts.dir, "genesis.json"))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(opts.out, 0o755); err != nil {
+		return err
+	}
+	if err := writeMerged(filepath.Join(opts.dir, "chain.json"), filepath.Join(opts.out, "chain.json"), chain); err != nil {
+		return err
+	}
+	if err := writeMerged(filepath.Join(opts.dir, "assetlist.json"), filepath.Join(opts.out, "assetlist.json"), assets); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(opts.out, "genesis.sha256"), []byte(sum+"  genesis.json\n"), 0o644)
+}
+
+func buildChain(opts options) (*chainFile, error) {
+	networkType := "mainnet"
+	if strings.Contains(filepath.ToSlash(opts.dir), "testnets") {
+		networkType = "testnet"
+	}
+	name := filepath.Base(filepath.Clean(opts.dir))
+
+	chain := &chainFile{
+		Schema:       "../../chain.schema.json",
+		ChainType:    "cosmos",
+		Status:       "live",
+		NetworkType:  networkType,
+		PrettyName:   "Warden Protocol " + strings.ToUpper(name[:1]) + name[1:],
+		Bech32Prefix: "warden",
+		DaemonName:   "wardend",
+		NodeHome:     "$HOME/.warden",
+		KeyAlgos:     []string{"secp256k1"},
+		Slip44:       118,
+		Codebase: codebase{
+			GitRepo: "https://github.com/warden-protocol/wardenprotocol",
+		},
+	}
+	if networkType == "testnet" {
+		chain.Keywords = []string{"testnet"}
+	}
+
+	existing, err := os.ReadFile(filepath.Join(opts.dir, "chain.json"))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(existing, chain); err != nil {
+			return nil, fmt.Errorf("decode existing chain.json: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	if opts.chainName != "" {
+		chain.ChainName = opts.chainName
+	}
+	if chain.ChainName == "" {
+		chain.ChainName = chainName(networkType, name)
+	}
+
+	if ids, err := networks.ReadLines(filepath.Join(opts.dir, "chain-id.txt")); err == nil && len(ids) > 0 {
+		chain.ChainID = ids[0]
+	}
+
+	denom, err := bondDenom(filepath.Join(opts.dir, "genesis.json"))
+	if err != nil {
+		return nil, err
+	}
+	if chain.ChainID == "" {
+		if chain.ChainID, err = genesisChainID(filepath.Join(opts.dir, "genesis.json")); err != nil {
+			return nil, err
+		}
+	}
+	if len(chain.Staking.StakingTokens) == 0 {
+		chain.Staking.StakingTokens = append(chain.Staking.StakingTokens, struct {
+			Denom string `json:"denom"`
+		}{Denom: denom})
+	}
+	if len(chain.Fees.FeeTokens) == 0 {
+		chain.Fees.FeeTokens = []feeToken{{
+			Denom:            denom,
+			FixedMinGasPrice: 0.005,
+			LowGasPrice:      0.01,
+			AverageGasPrice:  0.025,
+			HighGasPrice:     0.03,
+		}}
+	}
+
+	if opts.version != "" {
+		chain.Codebase.RecommendedVersion = opts.version
+		chain.Codebase.CompatibleVersions = []string{opts.version}
+	}
+	chain.Codebase.Genesis.GenesisURL = opts.genesisURL
+	if chain.Codebase.Genesis.GenesisURL == "" {
+		if chain.Codebase.Genesis.GenesisURL, err = genesisURL(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if ps, err := readPeers(filepath.Join(opts.dir, "peer-nodes.txt"), opts.provider); err == nil {
+		chain.Peers.PersistentPeers = ps
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if ps, err := readPeers(filepath.Join(opts.dir, "seed-nodes.txt"), opts.provider); err == nil {
+		chain.Peers.Seeds = ps
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if chain.Peers.Seeds == nil {
+		chain.Peers.Seeds = []peer{}
+	}
+	if chain.Peers.PersistentPeers == nil {
+		chain.Peers.PersistentPeers = []peer{}
+	}
+
+	for file, dst := range map[string]*[]endpoint{
+		"rpc-nodes.txt":  &chain.APIs.RPC,
+		"api-nodes.txt":  &chain.APIs.REST,
+		"grpc-nodes.txt": &chain.APIs.GRPC,
+		"evm-nodes.txt":  &chain.APIs.EVM,
+	} {
+		lines, err := networks.ReadLines(filepath.Join(opts.dir, file))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		eps := make([]endpoint, 0, len(lines))
+		for _, l := range lines {
+			eps = append(eps, endpoint{Address: l, Provider: opts.provider})
+		}
+		*dst = eps
+	}
+
+	return chain, nil
+}
+
+var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
+
+// chainName derives the chain_name of a network without one. Chain names
+// are unique across the registry, so every network but the mainnet gets
+// its own, from its directory name.
+func chainName(networkType, network string) string {
+	if networkType == "mainnet" {
+		return "wardenprotocol"
+	}
+	return "wardenprotocol" + nonAlnum.ReplaceAllString(strings.ToLower(network), "")
+}
+
+func buildAssetList(opts options, chain *chainFile) (*assetList, error) {
+	a, err := stakingAsset(opts, chain.Staking.StakingTokens[0].Denom)
+	if err != nil {
+		return nil, err
+	}
+	if opts.symbol != "" {
+		a.Symbol = opts.symbol
+	}
+	if a.Symbol == "" {
+		a.Symbol = strings.ToUpper(a.Display)
+	}
+	if a.Name == "" {
+		a.Name = strings.ToUpper(a.Display[:1]) + a.Display[1:]
+	}
+	if a.Description == "" {
+		a.Description = "The native staking token of " + chain.PrettyName + "."
+	}
+	a.LogoURIs, a.Images, a.TypeAsset = chain.LogoURIs, chain.Images, "sdk.coin"
+
+	return &assetList{
+		Schema:    "../../assetlist.schema.json",
+		ChainName: chain.ChainName,
+		Assets:    []asset{*a},
+	}, nil
+}
+
+// stakingAsset returns the denom units of the staking denom base and the
+// one it is displayed in, from -display, else the genesis bank
+// denom_metadata, else the existing assetlist.json.
+func stakingAsset(opts options, base string) (*asset, error) {
+	if opts.display != "" {
+		denom, exp, _ := strings.Cut(opts.display, ":")
+		exponent, err := strconv.Atoi(exp)
+		if denom == "" || err != nil || exponent < 0 || (denom == base) != (exponent == 0) {
+			return nil, fmt.Errorf("-display %q: expected denom:exponent of a unit other than %s, e.g. ward:6", opts.display, base)
+		}
+		units := []denomUnit{{Denom: base}}
+		if denom != base {
+			units = append(units, denomUnit{Denom: denom, Exponent: exponent})
+		}
+		return &asset{DenomUnits: units, Base: base, Display: denom}, nil
+	}
+
+	var g struct {
+		AppState struct {
+			Bank struct {
+				DenomMetadata []asset `json:"denom_metadata"`
+			} `json:"bank"`
+		} `json:"app_state"`
+	}
+	if err := decodeFile(filepath.Join(opts.dir, "genesis.json"), &g); err != nil {
+		return nil, err
+	}
+	candidates := g.AppState.Bank.DenomMetadata
+	var existing assetList
+	if err := decodeFile(filepath.Join(opts.dir, "assetlist.json"), &existing); err == nil {
+		candidates = append(candidates, existing.Assets...)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, a := range candidates {
+		if a.Base == base && a.Display != "" {
+			return &asset{Description: a.Description, DenomUnits: a.DenomUnits, Base: base, Name: a.Name, Display: a.Display, Symbol: a.Symbol}, nil
+		}
+	}
+	return nil, fmt.Errorf("no display unit known for %s: add its bank denom_metadata to the genesis or pass -display denom:exponent", base)
+}
+
+// checkDir validates chain.json and assetlist.json (when present) against
+// the chain-registry schemas, embedded or read from the registry checkout
+// schemaDir, and checks what the schemas cannot: peer addresses and the
+// agreement of the two files.
+func checkDir(dir, schemaDir string) ([]string, error) {
+	chainSch, assetSch, err := loadSchemas(schemaDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	report := func(file, format string, args ...any) {
+		problems = append(problems, file+": "+fmt.Sprintf(format, args...))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "chain.json"))
+	if err != nil {
+		return nil, err
+	}
+	errs, err := chainSch.Validate(data)
+	if err != nil {
+		report("chain.json", "%v", err)
+		return problems, nil
+	}
+	for _, e := range errs {
+		report("chain.json", "%v", e)
+	}
+	// A value of the wrong type, reported by the schema, leaves nothing to
+	// cross-check.
+	var chain chainFile
+	chainOK := json.Unmarshal(data, &chain) == nil
+	for kind, list := range map[string][]peer{"seeds": chain.Peers.Seeds, "persistent_peers": chain.Peers.PersistentPeers} {
+		for i, p := range list {
+			if _, _, err := networks.ParsePeer(p.ID + "@" + p.Address); err != nil {
+				report("chain.json", "/peers/%s/%d: %v", kind, i, err)
+			}
+		}
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "assetlist.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return problems, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if errs, err = assetSch.Validate(data); err != nil {
+		report("assetlist.json", "%v", err)
+		return problems, nil
+	}
+	for _, e := range errs {
+		report("assetlist.json", "%v", e)
+	}
+	var assets assetList
+	if err := json.Unmarshal(data, &assets); err != nil || !chainOK {
+		return problems, nil
+	}
+	if assets.ChainName != chain.ChainName {
+		report("assetlist.json", "chain_name %q does not match chain.json %q", assets.ChainName, chain.ChainName)
+	}
+	for _, a := range assets.Assets {
+		units := map[string]int{}
+		for _, u := range a.DenomUnits {
+			units[u.Denom] = u.Exponent
+		}
+		if exp, ok := units[a.Base]; !ok || exp != 0 {
+			report("assetlist.json", "asset %s: base denom must be a denom unit with exponent 0", a.Base)
+		}
+		if _, ok := units[a.Display]; !ok {
+			report("assetlist.json", "asset %s: display denom %q is not a denom unit", a.Base, a.Display)
+		}
+	}
+	for _, t := range chain.Fees.FeeTokens {
+		if !hasAsset(assets, t.Denom) {
+			report("assetlist.json", "fee token %s has no asset entry", t.Denom)
+		}
+	}
+
+	return problems, nil
+}
+
+// loadSchemas returns the chain.json and assetlist.json schemas of the
+// registry checkout dir, or the embedded ones when dir is empty.
+func loadSchemas(dir string) (chain, assets *jsonschema.Schema, err error) {
+	chainData, assetData := chainSchema, assetListSchema
+	if dir != "" {
+		if chainData, err = os.ReadFile(filepath.Join(dir, "chain.schema.json")); err != nil {
+			return nil, nil, err
+		}
+		if assetData, err = os.ReadFile(filepath.Join(dir, "assetlist.schema.json")); err != nil {
+			return nil, nil, err
+		}
+	}
+	if chain, err = jsonschema.Compile(chainData); err != nil {
+		return nil, nil, fmt.Errorf("chain.schema.json: %w", err)
+	}
+	if assets, err = jsonschema.Compile(assetData); err != nil {
+		return nil, nil, fmt.Errorf("assetlist.schema.json: %w", err)
+	}
+	return chain, assets, nil
+}
+
+func hasAsset(list assetList, denom string) bool {
+	for _, a := range list.Assets {
+		if a.Base == denom {
+			return true
+		}
+	}
+	return false
+}
+
+func bondDenom(genesisPath string) (string, error) {
+	var g struct {
+		AppState struct {
+			Staking struct {
+				Params struct {
+					BondDenom string `json:"bond_denom"`
+				} `json:"params"`
+			} `json:"staking"`
+		} `json:"app_state"`
+	}
+	if err := decodeFile(genesisPath, &g); err != nil {
+		return "", err
+	}
+	if g.AppState.Staking.Params.BondDenom == "" {
+		return "", fmt.Errorf("%s: staking bond_denom is not set", genesisPath)
+	}
+	return g.AppState.Staking.Params.BondDenom, nil
+}
+
+func genesisChainID(genesisPath string) (string, error) {
+	var g struct {
+		ChainID string `json:"chain_id"`
+	}
+	if err := decodeFile(genesisPath, &g); err != nil {
+		return "", err
+	}
+	return g.ChainID, nil
+}
+
+func decodeFile(path string, v any) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeJSON(path string, v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// genesisURL returns the URL genesis.json is published under: its path
+// relative to the repository root, appended to the base URL.
+func genesisURL(opts options) (string, error) {
+	root, err := filepath.Abs(opts.root)
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.Abs(filepath.Join(opts.dir, "genesis.json"))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository root %s: pass -genesis-url", opts.dir, root)
+	}
+	return strings.TrimSuffix(opts.baseURL, "/") + "/" + filepath.ToSlash(rel), nil
+}
+
+// writeMerged writes v to path, merged over the JSON object in existing when
+// that file exists.
+func writeMerged(existing, path string, v any) error {
+	generated, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	base, err := os.ReadFile(existing)
+	switch {
+	case err == nil:
+		if generated, err = mergeJSON(base, generated); err != nil {
+			return fmt.Errorf("merge into %s: %w", existing, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+	return writeJSON(path, json.RawMessage(generated))
+}
+
+// mergeJSON returns base with the values of overlay set over it. Objects are
+// merged recursively, keeping the key order of base and appending new keys;
+// any other value of overlay replaces the one in base.
+func mergeJSON(base, overlay json.RawMessage) (json.RawMessage, error) {
+	baseKeys, baseValues, ok := objectFields(base)
+	if !ok {
+		return overlay, nil
+	}
+	keys, values, ok := objectFields(overlay)
+	if !ok {
+		return overlay, nil
+	}
+	for _, k := range keys {
+		old, exists := baseValues[k]
+		if !exists {
+			baseKeys = append(baseKeys, k)
+			baseValues[k] = values[k]
+			continue
+		}
+		merged, err := mergeJSON(old, values[k])
+		if err != nil {
+			return nil, err
+		}
+		baseValues[k] = merged
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range baseKeys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(baseValues[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// objectFields returns the keys, in order, and values of a JSON object, and
+// false when raw is not an object.
+func objectFields(raw json.RawMessage) ([]string, map[string]json.RawMessage, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, nil, false
+	}
+	var keys []string
+	values := map[string]json.RawMessage{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, nil, false
+		}
+		key := t.(string)
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, nil, false
+		}
+		if _, dup := values[key]; !dup {
+			keys = append(keys, key)
+		}
+		values[key] = v
+	}
+	return keys, values, true
+}
+
+func readPeers(path, provider string) ([]peer, error) {
+	lines, err := networks.ReadLines(path)
+	if err != nil {
+		return nil, err
+	}
+	ps := make([]peer, 0, len(lines))
+	for _, l := range lines {
+		id, addr, err := networks.ParsePeer(l)
+		if err != nil {
+			return nil, fmt.Errorf("%s: peer %q: %w", path, l, err)
+		}
+		ps = append(ps, peer{ID: id, Address: addr, Provider: provider})
+	}
+	return ps, nil
+}
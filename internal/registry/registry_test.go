@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckDir(t *testing.T) {
+	chain, err := os.ReadFile("../../testnets/buenavista/chain.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const assets = `{"chain_name":"wardenprotocoltestnet","assets":[{"denom_units":[{"denom":"uward","exponent":0},{"denom":"ward","exponent":6}],"base":"uward","name":"Ward","display":"ward","symbol":"WARD","type_asset":"sdk.coin"}]}`
+	edit := func(s, old, new string) string {
+		if !strings.Contains(s, old) {
+			t.Fatalf("%q not found", old)
+		}
+		return strings.Replace(s, old, new, 1)
+	}
+
+	tests := []struct {
+		name     string
+		chain    string
+		assets   string   // not written when empty
+		problems []string // the problems, in order
+	}{
+		{name: "fixture", chain: string(chain)},
+		{name: "with assets", chain: string(chain), assets: assets},
+		{
+			name:     "unknown field",
+			chain:    edit(string(chain), `"status"`, `"state": "live", "status"`),
+			problems: []string{"chain.json: /state: unexpected property"},
+		},
+		{
+			name:     "bad status",
+			chain:    edit(string(chain), `"status": "live"`, `"status": "running"`),
+			problems: []string{`chain.json: /status: "running" is not one of "live", "upcoming", "killed"`},
+		},
+		{
+			name:     "bad peer",
+			chain:    edit(string(chain), `"54.171.21.98:26656"`, `"54.171.21.98"`),
+			problems: []string{"chain.json: /peers/persistent_peers/1: address 54.171.21.98: missing port in address"},
+		},
+		{
+			name:     "string exponent",
+			chain:    string(chain),
+			assets:   edit(assets, `"exponent":6`, `"exponent":"6"`),
+			problems: []string{"assetlist.json: /assets/0/denom_units/1/exponent: is string, want integer"},
+		},
+		{
+			name:     "other chain name",
+			chain:    string(chain),
+			assets:   edit(assets, `wardenprotocoltestnet`, `wardenprotocol`),
+			problems: []string{`assetlist.json: chain_name "wardenprotocol" does not match chain.json "wardenprotocoltestnet"`},
+		},
+		{
+			name:     "display not a unit",
+			chain:    string(chain),
+			assets:   edit(assets, `"display":"ward"`, `"display":"mward"`),
+			problems: []string{`assetlist.json: asset uward: display denom "mward" is not a denom unit`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "chain.json"), []byte(tt.chain), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.assets != "" {
+				if err := os.WriteFile(filepath.Join(dir, "assetlist.json"), []byte(tt.assets), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			problems, err := checkDir(dir, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(problems, "\n") != strings.Join(tt.problems, "\n") {
+				t.Errorf("checkDir() = %q, want %q", problems, tt.problems)
+			}
+		})
+	}
+}
+
+func TestChainName(t *testing.T) {
+	tests := []struct {
+		networkType, network, want string
+	}{
+		{"mainnet", "mainnet", "wardenprotocol"},
+		{"testnet", "alfama", "wardenprotocolalfama"},
+		{"testnet", "buenavista", "wardenprotocolbuenavista"},
+		{"devnet", "Chiado-2", "wardenprotocolchiado2"},
+	}
+	for _, tt := range tests {
+		if got := chainName(tt.networkType, tt.network); got != tt.want {
+			t.Errorf("chainName(%q, %q) = %q, want %q", tt.networkType, tt.network, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+// Package seedmonitor implements the seed-monitor command, which tracks the
+// uptime of the published seed nodes over time.
+//
+// Every run dials the seeds of the selected networks (and, with -peers, the
+// persistent peers), performs the CometBFT P2P handshake and appends the
+// outcome to a JSON history file. Samples older than -retain are dropped. The
+// command then prints an uptime report over the last 7 and 30 days, which
+// CI can publish, and with -min-uptime fails when a node's 7-day uptime is
+// too low to keep advertising it. With -interval, the report is printed
+// once the command is interrupted.
+//
+// Usage:
+//
+//	seed-monitor -history seed-history.json
+//	seed-monitor -interval 10m -history seed-history.json alfama
+//	seed-monitor -report-only -format markdown -min-uptime 90
+package seedmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/internal/peercrawl"
+)
+
+// Node kinds.
+const (
+	KindSeed = "seed"
+	KindPeer = "peer"
+)
+
+const day = 24 * time.Hour
+
+// Sample is one dial of one node.
+type Sample struct {
+	Time    time.Time     `json:"time"`
+	Network string        `json:"network"`
+	Kind    string        `json:"kind"`
+	Node    string        `json:"node"`
+	OK      bool          `json:"ok"`
+	Latency time.Duration `json:"latency_ns,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// History is the on-disk history file.
+type History struct {
+	Samples []Sample `json:"samples"`
+}
+
+// Uptime is the report line of one node.
+type Uptime struct {
+	Network    string        `json:"network"`
+	Kind       string        `json:"kind"`
+	Node       string        `json:"node"`
+	Samples7d  int           `json:"samples_7d"`
+	Uptime7d   float64       `json:"uptime_7d"`
+	Samples30d int           `json:"samples_30d"`
+	Uptime30d  float64       `json:"uptime_30d"`
+	Latency7d  time.Duration `json:"latency_7d_ns"`
+	LastOK     *time.Time    `json:"last_ok,omitempty"`
+	LastError  string        `json:"last_error,omitempty"`
+}
+
+// Command is the seed-monitor command.
+var Command = &cli.Command{
+	Name:  "seed-monitor",
+	Args:  "[network...]",
+	Short: "Dial the published seeds, record the outcome in a history file and report their uptime.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format     = cli.FormatFlag(fs, "text", "json", "markdown")
+			history    = fs.String("history", "seed-history.json", "JSON history file, created when missing")
+			peers      = fs.Bool("peers", false, "also monitor the persistent peers")
+			timeout    = fs.Duration("timeout", 10*time.Second, "timeout for dialing and handshaking with each node")
+			interval   = fs.Duration("interval", 0, "keep dialing at this interval instead of running once")
+			retain     = fs.Duration("retain", 30*day, "drop samples older than this")
+			reportOnly = fs.Bool("report-only", false, "only print the report from the history file")
+			minUptime  = fs.Float64("min-uptime", 0, "fail when a node's 7-day uptime percentage is below this")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			h, err := readHistory(*history)
+			if err != nil {
+				return err
+			}
+
+			if !*reportOnly {
+				networks, err := network.LoadAll(env.Root, args)
+				if err != nil {
+					return err
+				}
+				targets := collectTargets(networks, *peers)
+				if len(targets) == 0 {
+					return errors.New("no seeds to monitor")
+				}
+
+			monitor:
+				for {
+					h.Samples = append(h.Samples, probe(ctx, targets, *timeout)...)
+					h.prune(time.Now().Add(-*retain))
+					if err := writeHistory(*history, h); err != nil {
+						return err
+					}
+					if *interval <= 0 || ctx.Err() != nil {
+						break
+					}
+					select {
+					case <-time.After(*interval):
+					case <-ctx.Done():
+						break monitor
+					}
+				}
+				fmt.Fprintf(env.Stderr, "%s: %s\n", *history, h.summary())
+			}
+
+			report := Report(h, time.Now(), args)
+			switch format.String() {
+			case "json":
+				err = cli.WriteJSON(env.Stdout, report)
+			case "markdown":
+				err = writeMarkdown(env.Stdout, report)
+			default:
+				err = writeTable(env.Stdout, report)
+			}
+			if err != nil {
+				return err
+			}
+
+			if *minUptime > 0 {
+				var low []string
+				for _, u := range report {
+					if u.Samples7d > 0 && u.Uptime7d < *minUptime {
+						low = append(low, fmt.Sprintf("%s (%.1f%%)", u.Node, u.Uptime7d))
+					}
+				}
+				if len(low) > 0 {
+					return fmt.Errorf("7-day uptime below %.1f%%: %s", *minUptime, strings.Join(low, ", "))
+				}
+			}
+			return nil
+		}
+	},
+}
+
+type target struct {
+	network *network.Network
+	kind    string
+	node    string
+}
+
+func collectTargets(networks []*network.Network, peers bool) []target {
+	var targets []target
+	for _, n := range networks {
+		for _, s := range n.Seeds {
+			targets = append(targets, target{network: n, kind: KindSeed, node: s})
+		}
+		if peers {
+			for _, p := range n.Peers {
+				targets = append(targets, target{network: n, kind: KindPeer, node: p})
+			}
+		}
+	}
+	return targets
+}
+
+// probe dials every target concurrently, with one handshake key per
+// network. Dials that fail once ctx is done say nothing about the node and
+// are not returned.
+func probe(ctx context.Context, targets []target, timeout time.Duration) []Sample {
+	crawlers := map[string]*peercrawl.Crawler{}
+	for _, t := range targets {
+		if crawlers[t.network.Name] == nil {
+			crawlers[t.network.Name] = &peercrawl.Crawler{ChainID: t.network.ChainID, Timeout: timeout}
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	samples := make([]Sample, len(targets))
+	done := make([]bool, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := crawlers[t.network.Name].Probe(ctx, t.node)
+			s := Sample{Time: now, Network: t.network.Name, Kind: t.kind, Node: t.node}
+			if p.Reachable && p.Error == "" {
+				s.OK = true
+				s.Latency = p.Latency
+			} else {
+				s.Error = p.Error
+			}
+			samples[i], done[i] = s, s.OK || ctx.Err() == nil
+		}()
+	}
+	wg.Wait()
+
+	kept := samples[:0]
+	for i, s := range samples {
+		if done[i] {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+func readHistory(path string) (*History, error) {
+	h := &History{Samples: []Sample{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// writeHistory replaces the history file atomically, so an interrupted run
+// never leaves a truncated file behind.
+func writeHistory(path string, h *History) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := cli.WriteJSON(f, h); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// summary describes the samples of h.
+func (h *History) summary() string {
+	if len(h.Samples) == 0 {
+		return "no samples"
+	}
+	nodes := map[string]bool{}
+	first, last := h.Samples[0].Time, h.Samples[0].Time
+	for _, s := range h.Samples {
+		nodes[s.Network+"\x00"+s.Kind+"\x00"+s.Node] = true
+		if s.Time.Before(first) {
+			first = s.Time
+		}
+		if s.Time.After(last) {
+			last = s.Time
+		}
+	}
+	return fmt.Sprintf("%d sample(s) of %d node(s) from %s to %s",
+		len(h.Samples), len(nodes), first.Format(time.RFC3339), last.Format(time.RFC3339))
+}
+
+func (h *History) prune(before time.Time) {
+	kept := h.Samples[:0]
+	for _, s := range h.Samples {
+		if !s.Time.Before(before) {
+			kept = append(kept, s)
+		}
+	}
+	h.Samples = kept
+}
+
+// Report computes the uptime of every node in h over the 7 and 30 days
+// before now, restricted to networks when it is not empty. Nodes are
+// sorted by network, kind and 7-day uptime, worst first.
+func Report(h *History, now time.Time, networks []string) []*Uptime {
+	keep := map[string]bool{}
+	for _, n := range networks {
+		keep[filepath.Base(filepath.Clean(n))] = true
+	}
+
+	type counts struct {
+		ok7, n7, ok30, n30 int
+		latency            time.Duration
+		last               time.Time
+	}
+	byNode := map[string]*Uptime{}
+	c := map[string]*counts{}
+	for _, s := range h.Samples {
+		if len(keep) > 0 && !keep[s.Network] {
+			continue
+		}
+		age := now.Sub(s.Time)
+		if age > 30*day {
+			continue
+		}
+		key := s.Network + "\x00" + s.Kind + "\x00" + s.Node
+		u := byNode[key]
+		if u == nil {
+			u = &Uptime{Network: s.Network, Kind: s.Kind, Node: s.Node}
+			byNode[key] = u
+			c[key] = &counts{}
+		}
+		k := c[key]
+
+		k.n30++
+		if s.OK {
+			k.ok30++
+			if u.LastOK == nil || s.Time.After(*u.LastOK) {
+				t := s.Time
+				u.LastOK = &t
+			}
+		}
+		// The last error is only reported while the node is still failing.
+		if !s.Time.Before(k.last) {
+			k.last = s.Time
+			u.LastError = s.Error
+		}
+		if age <= 7*day {
+			k.n7++
+			if s.OK {
+				k.ok7++
+				k.latency += s.Latency
+			}
+		}
+	}
+
+	report := make([]*Uptime, 0, len(byNode))
+	for key, u := range byNode {
+		k := c[key]
+		u.Samples7d, u.Samples30d = k.n7, k.n30
+		if k.n7 > 0 {
+			u.Uptime7d = 100 * float64(k.ok7) / float64(k.n7)
+		}
+		if k.ok7 > 0 {
+			u.Latency7d = k.latency / time.Duration(k.ok7)
+		}
+		u.Uptime30d = 100 * float64(k.ok30) / float64(k.n30)
+		report = append(report, u)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Network != b.Network {
+			return a.Network < b.Network
+		}
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind // seeds first
+		}
+		if a.Uptime7d != b.Uptime7d {
+			return a.Uptime7d < b.Uptime7d
+		}
+		return a.Node < b.Node
+	})
+	return report
+}
+
+func (u *Uptime) columns() []string {
+	uptime7d, latency, lastOK := "-", "-", "never"
+	if u.Samples7d > 0 {
+		uptime7d = fmt.Sprintf("%.1f%% (%d)", u.Uptime7d, u.Samples7d)
+	}
+	if u.Latency7d > 0 {
+		latency = u.Latency7d.Round(time.Millisecond).String()
+	}
+	if u.LastOK != nil {
+		lastOK = u.LastOK.Format(time.RFC3339)
+	}
+	return []string{
+		u.Network, u.Kind, u.Node, uptime7d,
+		fmt.Sprintf("%.1f%% (%d)", u.Uptime30d, u.Samples30d),
+		latency, lastOK, u.LastError,
+	}
+}
+
+var header = []string{"NETWORK", "KIND", "NODE", "UPTIME 7D", "UPTIME 30D", "LATENCY 7D", "LAST OK", "LAST ERROR"}
+
+func writeTable(w io.Writer, report []*Uptime) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, u := range report {
+		fmt.Fprintln(tw, strings.Join(u.columns(), "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeMarkdown(w io.Writer, report []*Uptime) error {
+	rows := make([][]string, len(report))
+	for i, u := range report {
+		rows[i] = u.columns()
+	}
+	return cli.WriteMarkdown(w, header, rows)
+}
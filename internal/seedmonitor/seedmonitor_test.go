@@ -0,0 +1,209 @@
+package seedmonitor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+)
+
+const (
+	seedA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa@seed-a.example.org:26656"
+	seedB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb@seed-b.example.org:26656"
+	peerC = "cccccccccccccccccccccccccccccccccccccccc@peer-c.example.org:26656"
+)
+
+var now = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func sample(age time.Duration, netw, kind, node string, ok bool) Sample {
+	s := Sample{Time: now.Add(-age), Network: netw, Kind: kind, Node: node, OK: ok}
+	if ok {
+		s.Latency = 100 * time.Millisecond
+	} else {
+		s.Error = "connection refused"
+	}
+	return s
+}
+
+func TestReport(t *testing.T) {
+	h := &History{Samples: []Sample{
+		// seedA: up in the last week, down before.
+		sample(1*day, "chiado", KindSeed, seedA, true),
+		sample(2*day, "chiado", KindSeed, seedA, true),
+		sample(10*day, "chiado", KindSeed, seedA, false),
+		sample(20*day, "chiado", KindSeed, seedA, false),
+		sample(40*day, "chiado", KindSeed, seedA, false), // out of the window
+		// seedB: down last, after being up.
+		sample(1*day, "chiado", KindSeed, seedB, false),
+		sample(3*day, "chiado", KindSeed, seedB, true),
+		// peerC: only old samples.
+		sample(15*day, "chiado", KindPeer, peerC, true),
+		// another network.
+		sample(1*day, "buenavista", KindSeed, seedA, true),
+	}}
+
+	report := Report(h, now, []string{"chiado"})
+	var nodes []string
+	for _, u := range report {
+		nodes = append(nodes, u.Kind+" "+u.Node)
+	}
+	want := []string{KindSeed + " " + seedB, KindSeed + " " + seedA, KindPeer + " " + peerC}
+	if strings.Join(nodes, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("Report() nodes =\n%s\nwant\n%s", strings.Join(nodes, "\n"), strings.Join(want, "\n"))
+	}
+
+	a, b, c := report[1], report[0], report[2]
+	if a.Samples7d != 2 || a.Uptime7d != 100 || a.Samples30d != 4 || a.Uptime30d != 50 {
+		t.Errorf("seed A: got %+v", a)
+	}
+	if a.Latency7d != 100*time.Millisecond {
+		t.Errorf("seed A: got latency %s, want 100ms", a.Latency7d)
+	}
+	if a.LastError != "" {
+		t.Errorf("seed A: got last error %q, want none once it answers again", a.LastError)
+	}
+	if a.LastOK == nil || !a.LastOK.Equal(now.Add(-day)) {
+		t.Errorf("seed A: got last OK %v", a.LastOK)
+	}
+	if b.Uptime7d != 50 || b.LastError != "connection refused" {
+		t.Errorf("seed B: got uptime %.1f, last error %q", b.Uptime7d, b.LastError)
+	}
+	if c.Samples7d != 0 || c.Uptime30d != 100 {
+		t.Errorf("peer C: got %+v", c)
+	}
+
+	if all := Report(h, now, nil); len(all) != 4 {
+		t.Errorf("Report() without networks returned %d nodes, want 4", len(all))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	h := &History{Samples: []Sample{
+		sample(1*day, "chiado", KindSeed, seedA, true),
+		sample(31*day, "chiado", KindSeed, seedA, true),
+		sample(2*day, "chiado", KindSeed, seedB, false),
+	}}
+	h.prune(now.Add(-30 * day))
+	if len(h.Samples) != 2 || h.Samples[0].Node != seedA || h.Samples[1].Node != seedB {
+		t.Errorf("prune kept %+v", h.Samples)
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	h, err := readHistory(path)
+	if err != nil || len(h.Samples) != 0 {
+		t.Fatalf("readHistory() of a missing file = %+v, %v, want an empty history", h, err)
+	}
+
+	h.Samples = append(h.Samples, sample(day, "chiado", KindSeed, seedA, true))
+	if err := writeHistory(path, h); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Samples) != 1 || got.Samples[0] != h.Samples[0] {
+		t.Errorf("readHistory() = %+v, want %+v", got.Samples, h.Samples)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files next to the history, want no temporary file left", len(entries))
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readHistory(path); err == nil {
+		t.Error("readHistory() accepted a truncated file")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	if got := (&History{}).summary(); got != "no samples" {
+		t.Errorf("summary() = %q", got)
+	}
+	h := &History{Samples: []Sample{
+		sample(2*day, "chiado", KindSeed, seedA, true),
+		sample(day, "chiado", KindSeed, seedA, false),
+		sample(day, "chiado", KindSeed, seedB, true),
+	}}
+	want := "3 sample(s) of 2 node(s) from 2026-10-14T12:00:00Z to 2026-10-15T12:00:00Z"
+	if got := h.summary(); got != want {
+		t.Errorf("summary() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	u := &Uptime{Network: "chiado", Kind: KindSeed, Node: seedA, Samples30d: 2, Uptime30d: 50, LastError: "a|b"}
+	var buf bytes.Buffer
+	if err := writeMarkdown(&buf, []*Uptime{u}); err != nil {
+		t.Fatal(err)
+	}
+	want := "| Network | Kind | Node | Uptime 7d | Uptime 30d | Latency 7d | Last ok | Last error |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- | --- |\n" +
+		"| chiado | seed | " + seedA + " | - | 50.0% (2) | - | never | a\\|b |\n"
+	if buf.String() != want {
+		t.Errorf("writeMarkdown() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestProbeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n := &network.Network{Name: "chiado", ChainID: "chiado_10010-1"}
+	targets := []target{{network: n, kind: KindSeed, node: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa@127.0.0.1:1"}}
+	if samples := probe(ctx, targets, time.Second); len(samples) != 0 {
+		t.Errorf("probe() after cancellation = %+v, want no samples", samples)
+	}
+}
+
+// TestInterruptedInterval checks that an interrupted -interval run still
+// writes its history and prints the report.
+func TestInterruptedInterval(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "testnets", "chiado")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"chain-id.txt":   "chiado_10010-1\n",
+		"seed-nodes.txt": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa@127.0.0.1:1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history := filepath.Join(root, "history.json")
+	h := &History{Samples: []Sample{sample(time.Hour, "chiado", KindSeed, seedA, true)}}
+	h.Samples[0].Time = time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	if err := writeHistory(history, h); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var stdout, stderr bytes.Buffer
+	env := &cli.Env{Stdout: &stdout, Stderr: &stderr, Root: root}
+	if code := cli.Exec(ctx, "seed-monitor", Command, env, []string{"-interval", "1h", "-history", history}); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), seedA) {
+		t.Errorf("report does not list %s:\n%s", seedA, stdout.String())
+	}
+	if want := history + ": 1 sample(s) of 1 node(s)"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+	}
+}
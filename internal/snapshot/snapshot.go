@@ -0,0 +1,500 @@
+// Package snapshot implements the snapshot-tool command, which publishes and
+// checks signed manifests for node snapshot archives.
+//
+// A manifest records the chain-id, height, archive format, download URL,
+// total size, the SHA-256 of the whole archive and of every fixed-size chunk,
+// and is signed with an ed25519 key. Operators verify an archive by
+// streaming it (from its URL or a local copy) through the chunk hashes, so a
+// corrupted or tampered download is reported with the first bad chunk
+// instead of failing later in the node. The publisher's key must be pinned,
+// either with -pubkey or in the snapshot-keys.txt file (one hex key per
+// line) of the network given with -network; the key embedded in a manifest
+// is never trusted on its own.
+//
+// Usage:
+//
+//	snapshot-tool -genkey snapshot.key
+//	snapshot-tool -key snapshot.key -network alfama -height 1200000 \
+//	    -url https://snapshots.example.com/alfama-1200000.tar.lz4 \
+//	    -out alfama-1200000.json alfama-1200000.tar.lz4
+//	snapshot-tool -verify alfama-1200000.json -pubkey <hex> [-out archive] [archive]
+//	snapshot-tool -verify alfama-1200000.json -network alfama [archive]
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/pkg/networks"
+)
+
+// KeysFile is the name of the file pinning the snapshot publishers' public
+// keys in a network directory.
+const KeysFile = "snapshot-keys.txt"
+
+// DefaultChunkSize is the chunk size used when -chunk-size is not set.
+const DefaultChunkSize = 256 << 20
+
+// Manifest describes a snapshot archive.
+type Manifest struct {
+	ChainID   string    `json:"chain_id"`
+	Height    int64     `json:"height"`
+	Format    string    `json:"format"`
+	URL       string    `json:"url"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	ChunkSize int64     `json:"chunk_size"`
+	Chunks    []string  `json:"chunks"`
+	CreatedAt time.Time `json:"created_at"`
+	PublicKey string    `json:"public_key"`
+	Signature string    `json:"signature,omitempty"`
+}
+
+// signedBytes returns the bytes covered by the signature: the manifest's
+// JSON encoding without the signature field.
+func (m *Manifest) signedBytes() ([]byte, error) {
+	c := *m
+	c.Signature = ""
+	return json.Marshal(&c)
+}
+
+// Sign fills in the public key and signature of m.
+func (m *Manifest) Sign(key ed25519.PrivateKey) error {
+	m.PublicKey = hex.EncodeToString(key.Public().(ed25519.PublicKey))
+	msg, err := m.signedBytes()
+	if err != nil {
+		return err
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, msg))
+	return nil
+}
+
+// VerifySignature checks the signature of m against pub, or against the
+// key embedded in the manifest when pub is nil.
+func (m *Manifest) VerifySignature(pub ed25519.PublicKey) error {
+	embedded, err := hex.DecodeString(m.PublicKey)
+	if err != nil || len(embedded) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public_key %q", m.PublicKey)
+	}
+	if pub != nil && !bytes.Equal(pub, embedded) {
+		return errors.New("manifest is signed by a different key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	msg, err := m.signedBytes()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(embedded, msg, sig) {
+		return errors.New("signature does not match the manifest")
+	}
+	return nil
+}
+
+// Command is the snapshot-tool command.
+var Command = &cli.Command{
+	Name:  "snapshot-tool",
+	Args:  "<archive> | -verify <manifest.json> [archive] | -genkey <file>",
+	Short: "Generate a signed manifest for a node snapshot archive, or verify an archive against one.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			format    = cli.FormatFlag(fs, "text", "json")
+			genkey    = fs.String("genkey", "", "write a new ed25519 signing key to this file and print its public key")
+			keyFile   = fs.String("key", "", "file holding the hex-encoded ed25519 seed used to sign the manifest")
+			name      = fs.String("network", "", "network the snapshot belongs to; its chain-id is recorded in the manifest (with -verify: checked, and its "+KeysFile+" pins the publisher keys)")
+			height    = fs.Int64("height", 0, "block height of the snapshot")
+			url       = fs.String("url", "", "URL the archive is published at")
+			archFmt   = fs.String("archive-format", "", "archive format (e.g. tar.lz4); guessed from the file name when empty")
+			chunkSize = fs.Int64("chunk-size", DefaultChunkSize, "size in bytes of the hashed chunks")
+			out       = fs.String("out", "", "write the manifest (or, with -verify, the downloaded archive) to this file")
+			verify    = fs.String("verify", "", "verify an archive against this manifest")
+			pubkey    = fs.String("pubkey", "", "hex-encoded ed25519 public key the manifest must be signed with (-verify); required unless -network pins keys")
+			timeout   = fs.Duration("timeout", 0, "timeout for downloading the archive (-verify); 0 means none")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			switch {
+			case *genkey != "":
+				if len(args) != 0 {
+					return cli.ErrUsage
+				}
+				return runGenkey(env, *genkey)
+			case *verify != "":
+				if len(args) > 1 {
+					return cli.ErrUsage
+				}
+				if *timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, *timeout)
+					defer cancel()
+				}
+				var n *network.Network
+				if *name != "" {
+					dir, err := network.Resolve(env.Root, *name)
+					if err != nil {
+						return err
+					}
+					if n, err = network.Load(dir); err != nil {
+						return err
+					}
+				}
+				keys, err := pinnedKeys(n, *pubkey)
+				if err != nil {
+					return err
+				}
+				return runVerify(ctx, env, *verify, args, n, keys, *out, format)
+			}
+
+			if len(args) != 1 || *keyFile == "" || *height <= 0 || *url == "" || *chunkSize <= 0 {
+				return cli.ErrUsage
+			}
+			key, err := readKey(*keyFile)
+			if err != nil {
+				return err
+			}
+
+			m := &Manifest{
+				Height:    *height,
+				Format:    *archFmt,
+				URL:       *url,
+				ChunkSize: *chunkSize,
+				CreatedAt: time.Now().UTC().Truncate(time.Second),
+			}
+			if m.Format == "" {
+				m.Format = guessFormat(args[0])
+			}
+			if *name != "" {
+				dir, err := network.Resolve(env.Root, *name)
+				if err != nil {
+					return err
+				}
+				n, err := network.Load(dir)
+				if err != nil {
+					return err
+				}
+				m.ChainID = n.ChainID
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			h := newChunkHasher(m.ChunkSize)
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+			m.Size, m.SHA256, m.Chunks = h.sum()
+
+			if err := m.Sign(key); err != nil {
+				return err
+			}
+
+			w := env.Stdout
+			if *out != "" {
+				f, err := os.Create(*out)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			return cli.WriteJSON(w, m)
+		}
+	},
+}
+
+func runGenkey(env *cli.Env, path string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, hex.EncodeToString(priv.Seed())); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintln(env.Stdout, hex.EncodeToString(pub))
+	return nil
+}
+
+func readKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s: not a hex-encoded %d-byte ed25519 seed", path, ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// Verification is the outcome of -verify.
+type Verification struct {
+	Manifest  string   `json:"manifest"`
+	Source    string   `json:"source"`
+	Signature string   `json:"signature"`
+	Size      int64    `json:"size"`
+	Problems  []string `json:"problems"`
+}
+
+// pinnedKeys returns the public keys a manifest may be signed with: the one
+// given with -pubkey, or those listed in the KeysFile of n.
+func pinnedKeys(n *network.Network, pubkey string) ([]ed25519.PublicKey, error) {
+	if pubkey != "" {
+		b, err := hex.DecodeString(pubkey)
+		if err != nil || len(b) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("-pubkey is not a hex-encoded ed25519 public key")
+		}
+		return []ed25519.PublicKey{b}, nil
+	}
+	if n == nil {
+		return nil, fmt.Errorf("no publisher key pinned: pass -pubkey, or -network for a network with a %s", KeysFile)
+	}
+	path := filepath.Join(n.Dir, KeysFile)
+	lines, err := networks.ReadLines(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("network %s has no %s: pass -pubkey", n.Name, KeysFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []ed25519.PublicKey
+	for _, line := range lines {
+		b, err := hex.DecodeString(line)
+		if err != nil || len(b) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%s: %q is not a hex-encoded ed25519 public key", path, line)
+		}
+		keys = append(keys, b)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s lists no keys", path)
+	}
+	return keys, nil
+}
+
+func runVerify(ctx context.Context, env *cli.Env, path string, args []string, n *network.Network, keys []ed25519.PublicKey, out string, format *cli.Format) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	// VerifySignature reports a manifest signed by another key than the
+	// one it is given, so pass it the pinned key matching the embedded one.
+	pub := keys[0]
+	for _, k := range keys {
+		if hex.EncodeToString(k) == strings.ToLower(m.PublicKey) {
+			pub = k
+		}
+	}
+
+	v := &Verification{Manifest: path, Source: m.URL, Signature: "ok", Problems: []string{}}
+	if err := m.VerifySignature(pub); err != nil {
+		v.Signature = err.Error()
+		v.Problems = append(v.Problems, "signature: "+err.Error())
+	}
+	if n != nil && m.ChainID != n.ChainID {
+		v.Problems = append(v.Problems, fmt.Sprintf("manifest is for chain-id %q, network %s is %q", m.ChainID, n.Name, n.ChainID))
+	}
+	if m.ChunkSize <= 0 {
+		v.Problems = append(v.Problems, fmt.Sprintf("invalid chunk_size %d", m.ChunkSize))
+	}
+
+	// Only download archives whose manifest is trusted.
+	if len(v.Problems) == 0 {
+		if len(args) == 1 {
+			v.Source = args[0]
+		}
+		v.Problems = append(v.Problems, verifyArchive(ctx, &m, v, out)...)
+	}
+
+	if format.JSON() {
+		if err := cli.WriteJSON(env.Stdout, v); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range v.Problems {
+			fmt.Fprintln(env.Stdout, p)
+		}
+	}
+	if len(v.Problems) > 0 {
+		return fmt.Errorf("%s: %d problem(s) found", path, len(v.Problems))
+	}
+	if !format.JSON() {
+		fmt.Fprintf(env.Stdout, "%s: %s at height %d, %d bytes in %d chunks, ok\n", v.Source, m.ChainID, m.Height, v.Size, len(m.Chunks))
+	}
+	return nil
+}
+
+// verifyArchive streams the archive from v.Source, a URL or local path,
+// through the manifest's hashes. When out is set, the archive is copied to
+// a temporary file next to it, which is renamed to out only if the archive
+// matches the manifest.
+func verifyArchive(ctx context.Context, m *Manifest, v *Verification, out string) []string {
+	r, err := open(ctx, v.Source)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer r.Close()
+
+	h := newChunkHasher(m.ChunkSize)
+	var w io.Writer = h
+	var tmp *os.File
+	if out != "" {
+		tmp, err = os.CreateTemp(filepath.Dir(out), filepath.Base(out)+".*")
+		if err != nil {
+			return []string{err.Error()}
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if err := tmp.Chmod(0o644); err != nil {
+			return []string{err.Error()}
+		}
+		w = io.MultiWriter(h, tmp)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return []string{fmt.Sprintf("read %s: %v", v.Source, err)}
+	}
+
+	problems := checkArchive(m, v, h)
+	if tmp != nil && len(problems) == 0 {
+		if err := tmp.Close(); err != nil {
+			return []string{err.Error()}
+		}
+		if err := os.Rename(tmp.Name(), out); err != nil {
+			return []string{err.Error()}
+		}
+	}
+	return problems
+}
+
+// checkArchive compares the hashes of an archive with the manifest's.
+func checkArchive(m *Manifest, v *Verification, h *chunkHasher) []string {
+	var problems []string
+	size, sum, chunks := h.sum()
+	v.Size = size
+	if size != m.Size {
+		problems = append(problems, fmt.Sprintf("size is %d bytes, manifest says %d", size, m.Size))
+	}
+	for i, c := range chunks {
+		if i >= len(m.Chunks) {
+			break
+		}
+		if c != m.Chunks[i] {
+			problems = append(problems, fmt.Sprintf("chunk %d (offset %d) has sha256 %s, manifest says %s", i, int64(i)*m.ChunkSize, c, m.Chunks[i]))
+			break
+		}
+	}
+	if len(chunks) != len(m.Chunks) {
+		problems = append(problems, fmt.Sprintf("archive has %d chunks, manifest lists %d", len(chunks), len(m.Chunks)))
+	}
+	if sum != m.SHA256 {
+		problems = append(problems, fmt.Sprintf("sha256 is %s, manifest says %s", sum, m.SHA256))
+	}
+	return problems
+}
+
+func open(ctx context.Context, src string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		return os.Open(src)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", src, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// chunkHasher hashes a stream as a whole and in chunks of a fixed size.
+type chunkHasher struct {
+	size   int64
+	n      int64
+	all    hash.Hash
+	chunk  hash.Hash
+	fill   int64
+	chunks []string
+}
+
+func newChunkHasher(size int64) *chunkHasher {
+	return &chunkHasher{size: size, all: sha256.New(), chunk: sha256.New(), chunks: []string{}}
+}
+
+func (h *chunkHasher) Write(p []byte) (int, error) {
+	n := len(p)
+	h.all.Write(p)
+	h.n += int64(n)
+	for len(p) > 0 {
+		take := h.size - h.fill
+		if int64(len(p)) < take {
+			take = int64(len(p))
+		}
+		h.chunk.Write(p[:take])
+		h.fill += take
+		p = p[take:]
+		if h.fill == h.size {
+			h.chunks = append(h.chunks, hex.EncodeToString(h.chunk.Sum(nil)))
+			h.chunk.Reset()
+			h.fill = 0
+		}
+	}
+	return n, nil
+}
+
+// sum returns the total size, the hash of the whole stream and the chunk
+// hashes, including the final partial chunk.
+func (h *chunkHasher) sum() (int64, string, []string) {
+	chunks := h.chunks
+	if h.fill > 0 {
+		chunks = append(chunks, hex.EncodeToString(h.chunk.Sum(nil)))
+	}
+	return h.n, hex.EncodeToString(h.all.Sum(nil)), chunks
+}
+
+func guessFormat(path string) string {
+	base := filepath.Base(path)
+	for _, ext := range []string{".tar.lz4", ".tar.zst", ".tar.gz", ".tgz", ".tar"} {
+		if strings.HasSuffix(base, ext) {
+			return strings.TrimPrefix(ext, ".")
+		}
+	}
+	return strings.TrimPrefix(filepath.Ext(base), ".")
+}
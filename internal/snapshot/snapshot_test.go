@@ -0,0 +1,111 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func sha(b []byte) string {
+	s := sha256.Sum256(b)
+	return hex.EncodeToString(s[:])
+}
+
+func TestChunkHasher(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10) // 100 bytes
+
+	tests := []struct {
+		name   string
+		size   int64
+		writes []int // lengths of the successive writes
+		chunks int
+	}{
+		{"one write", 30, []int{100}, 4},
+		{"exact multiple", 25, []int{100}, 4},
+		{"byte by byte", 30, ones(100), 4},
+		{"writes straddling chunks", 30, []int{7, 50, 1, 42}, 4},
+		{"chunk larger than stream", 1000, []int{100}, 1},
+		{"empty", 30, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newChunkHasher(tt.size)
+			var written int64
+			for _, n := range tt.writes {
+				if _, err := h.Write(data[written : written+int64(n)]); err != nil {
+					t.Fatal(err)
+				}
+				written += int64(n)
+			}
+			in := data[:written]
+
+			size, sum, chunks := h.sum()
+			if size != written {
+				t.Errorf("size = %d, want %d", size, written)
+			}
+			if sum != sha(in) {
+				t.Errorf("sha256 = %s, want %s", sum, sha(in))
+			}
+			if len(chunks) != tt.chunks {
+				t.Fatalf("got %d chunks, want %d", len(chunks), tt.chunks)
+			}
+			for i, c := range chunks {
+				end := min(int64(i+1)*tt.size, written)
+				if want := sha(in[int64(i)*tt.size : end]); c != want {
+					t.Errorf("chunk %d = %s, want %s", i, c, want)
+				}
+			}
+		})
+	}
+}
+
+func ones(count int) []int {
+	n := make([]int, count)
+	for i := range n {
+		n[i] = 1
+	}
+	return n
+}
+
+func TestCheckArchive(t *testing.T) {
+	data := []byte(strings.Repeat("snapshot", 8)) // 64 bytes
+	h := newChunkHasher(16)
+	h.Write(data)
+	_, sum, chunks := h.sum()
+	good := Manifest{Size: 64, SHA256: sum, ChunkSize: 16, Chunks: chunks}
+
+	tests := []struct {
+		name    string
+		edit    func(m *Manifest)
+		problem string // substring of the first problem; "" when none is expected
+	}{
+		{"match", func(m *Manifest) {}, ""},
+		{"size", func(m *Manifest) { m.Size = 63 }, "size is 64 bytes"},
+		{"chunk", func(m *Manifest) { m.Chunks = []string{chunks[0], sha(nil), chunks[2], chunks[3]} }, "chunk 1 (offset 16)"},
+		{"missing chunk", func(m *Manifest) { m.Chunks = chunks[:3] }, "archive has 4 chunks, manifest lists 3"},
+		{"sha256", func(m *Manifest) { m.SHA256 = sha(nil) }, "sha256 is"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := good
+			m.Chunks = append([]string{}, chunks...)
+			tt.edit(&m)
+			var v Verification
+			problems := checkArchive(&m, &v, h)
+			if v.Size != 64 {
+				t.Errorf("Verification.Size = %d, want 64", v.Size)
+			}
+			if tt.problem == "" {
+				if len(problems) > 0 {
+					t.Fatalf("checkArchive problems = %q", problems)
+				}
+				return
+			}
+			if len(problems) == 0 || !strings.Contains(problems[0], tt.problem) {
+				t.Fatalf("checkArchive problems = %q, want one containing %q", problems, tt.problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Package statesync implements the state-sync-gen command, which emits a
+// ready-to-paste [statesync] config.toml block for bootstrapping a node from
+// one of the networks described in this repo.
+//
+// It queries every RPC endpoint for its latest height, picks a trust height a
+// configurable number of blocks below the lowest reported height, and makes
+// sure all endpoints agree on the block hash at that height before printing
+// the config. Endpoints that cannot serve the trust height, such as pruned
+// nodes, are skipped with a warning. CometBFT needs two rpc_servers to
+// cross-check the light client; with a single usable endpoint the command
+// fails unless -allow-single-rpc is given.
+//
+// Usage:
+//
+//	state-sync-gen -network alfama
+//	state-sync-gen -rpc https://rpc1.example.org,https://rpc2.example.org -format json
+package statesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/network"
+)
+
+type config struct {
+	Enable      bool     `json:"enable"`
+	RPCServers  []string `json:"rpc_servers"`
+	TrustHeight int64    `json:"trust_height"`
+	TrustHash   string   `json:"trust_hash"`
+	TrustPeriod string   `json:"trust_period"`
+}
+
+type statusResponse struct {
+	Result struct {
+		NodeInfo struct {
+			Network string `json:"network"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			LatestBlockHeight string `json:"latest_block_height"`
+			CatchingUp        bool   `json:"catching_up"`
+		} `json:"sync_info"`
+	} `json:"result"`
+}
+
+type blockResponse struct {
+	Result struct {
+		BlockID struct {
+			Hash string `json:"hash"`
+		} `json:"block_id"`
+	} `json:"result"`
+}
+
+// Command is the state-sync-gen command.
+var Command = &cli.Command{
+	Name:  "state-sync-gen",
+	Short: "Emit a [statesync] config.toml block with a trust height and hash agreed on by the network's RPC endpoints.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		var (
+			name        = fs.String("network", "", "network name or directory (e.g. alfama) to read chain-id and RPC endpoints from")
+			rpcList     = fs.String("rpc", "", "comma-separated RPC endpoints, overrides the network's list")
+			chainID     = fs.String("chain-id", "", "expected chain-id, overrides the network's chain-id")
+			offset      = fs.Int64("offset", 2000, "number of blocks below the latest height to use as trust height")
+			trustPeriod = fs.String("trust-period", "168h0m0s", "trust_period to emit")
+			format      = cli.FormatFlag(fs, "toml", "json")
+			timeout     = fs.Duration("timeout", 10*time.Second, "timeout for each RPC request")
+			allowSingle = fs.Bool("allow-single-rpc", false, "list a single usable RPC endpoint twice instead of failing")
+		)
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if len(args) != 0 {
+				return cli.ErrUsage
+			}
+
+			var endpoints []string
+			expected := *chainID
+			if *name != "" {
+				dir, err := network.Resolve(env.Root, *name)
+				if err != nil {
+					return err
+				}
+				n, err := network.Load(dir)
+				if err != nil {
+					return err
+				}
+				endpoints = n.RPC
+				if expected == "" {
+					expected = n.ChainID
+				}
+			}
+			if *rpcList != "" {
+				endpoints = network.SplitList(*rpcList)
+			}
+			if len(endpoints) == 0 {
+				return errors.New("no RPC endpoints: pass -network or -rpc")
+			}
+
+			g := &generator{
+				client:      &http.Client{Timeout: *timeout},
+				log:         env.Stderr,
+				chainID:     expected,
+				offset:      *offset,
+				allowSingle: *allowSingle,
+			}
+			cfg, err := g.generate(ctx, endpoints)
+			if err != nil {
+				return err
+			}
+			cfg.TrustPeriod = *trustPeriod
+
+			return write(env.Stdout, cfg, format)
+		}
+	},
+}
+
+type generator struct {
+	client      *http.Client
+	log         io.Writer
+	chainID     string
+	offset      int64
+	allowSingle bool
+}
+
+func (g *generator) generate(ctx context.Context, endpoints []string) (*config, error) {
+	var (
+		live   []string
+		lowest int64
+	)
+	for _, ep := range endpoints {
+		height, err := g.latestHeight(ctx, ep)
+		if err != nil {
+			fmt.Fprintf(g.log, "skipping %s: %v\n", ep, err)
+			continue
+		}
+		live = append(live, ep)
+		if lowest == 0 || height < lowest {
+			lowest = height
+		}
+	}
+	if len(live) == 0 {
+		return nil, errors.New("none of the RPC endpoints are usable")
+	}
+
+	trustHeight := lowest - g.offset
+	if trustHeight < 1 {
+		trustHeight = 1
+	}
+
+	var (
+		trustHash string
+		serving   []string
+	)
+	for _, ep := range live {
+		hash, err := g.blockHash(ctx, ep, trustHeight)
+		if err != nil {
+			fmt.Fprintf(g.log, "skipping %s: %v\n", ep, err)
+			continue
+		}
+		serving = append(serving, ep)
+		if trustHash == "" {
+			trustHash = hash
+		} else if hash != trustHash {
+			return nil, fmt.Errorf("endpoints disagree on block %d: %s reports %s, expected %s", trustHeight, ep, hash, trustHash)
+		}
+	}
+	if len(serving) == 0 {
+		return nil, fmt.Errorf("none of the RPC endpoints can serve block %d", trustHeight)
+	}
+
+	// CometBFT requires at least two rpc_servers so the light client can
+	// cross-check them. Listing a single endpoint twice defeats that, so it
+	// is only done when asked for.
+	servers := serving
+	if len(servers) == 1 {
+		if !g.allowSingle {
+			return nil, fmt.Errorf("only %s can serve block %d, state sync needs two RPC servers (pass -allow-single-rpc to list it twice)", servers[0], trustHeight)
+		}
+		fmt.Fprintf(g.log, "warning: only one usable RPC endpoint, listing %s twice\n", servers[0])
+		servers = []string{servers[0], servers[0]}
+	}
+
+	return &config{
+		Enable:      true,
+		RPCServers:  servers,
+		TrustHeight: trustHeight,
+		TrustHash:   trustHash,
+	}, nil
+}
+
+func (g *generator) latestHeight(ctx context.Context, endpoint string) (int64, error) {
+	var status statusResponse
+	if err := g.get(ctx, endpoint+"/status", &status); err != nil {
+		return 0, err
+	}
+	if g.chainID != "" && status.Result.NodeInfo.Network != g.chainID {
+		return 0, fmt.Errorf("wrong chain-id %q, expected %q", status.Result.NodeInfo.Network, g.chainID)
+	}
+	if status.Result.SyncInfo.CatchingUp {
+		return 0, errors.New("node is catching up")
+	}
+	height, err := strconv.ParseInt(status.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid latest_block_height: %w", err)
+	}
+	return height, nil
+}
+
+func (g *generator) blockHash(ctx context.Context, endpoint string, height int64) (string, error) {
+	var block blockResponse
+	if err := g.get(ctx, fmt.Sprintf("%s/block?height=%d", endpoint, height), &block); err != nil {
+		return "", err
+	}
+	if block.Result.BlockID.Hash == "" {
+		return "", fmt.Errorf("no block hash for height %d", height)
+	}
+	return block.Result.BlockID.Hash, nil
+}
+
+func (g *generator) get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func write(w io.Writer, cfg *config, format *cli.Format) error {
+	if format.JSON() {
+		return cli.WriteJSON(w, cfg)
+	}
+	fmt.Fprintln(w, "[statesync]")
+	fmt.Fprintf(w, "enable = %t\n", cfg.Enable)
+	fmt.Fprintf(w, "rpc_servers = %q\n", strings.Join(cfg.RPCServers, ","))
+	fmt.Fprintf(w, "trust_height = %d\n", cfg.TrustHeight)
+	fmt.Fprintf(w, "trust_hash = %q\n", cfg.TrustHash)
+	fmt.Fprintf(w, "trust_period = %q\n", cfg.TrustPeriod)
+	return nil
+}
@@ -0,0 +1,410 @@
+// Package networks defines the manifest describing a network of this repo
+// and loads it from a network directory. It is the single place that knows
+// how network metadata is laid out on disk; every tool in the repo reads
+// networks through it.
+//
+// A network directory may describe the network in three ways, read in this
+// order with later sources overriding earlier ones:
+//
+//   - a chain-registry style chain.json;
+//   - a manifest.json (see [File]), for the fields the other sources have no
+//     place for: the pinned genesis hash, the wardend version, the fee
+//     policy and denominations, and the names of the list files;
+//   - plain-text files (chain-id.txt, rpc-nodes.txt, api-nodes.txt,
+//     grpc-nodes.txt, evm-nodes.txt, peer-nodes.txt, seed-nodes.txt), as
+//     they are what maintainers edit by hand.
+//
+// The SHA-256 of the genesis is also read from genesis.sha256, as written by
+// registry-gen, when the manifest does not pin one.
+package networks
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ManifestFile is the name of the manifest file in a network directory.
+const ManifestFile = "manifest.json"
+
+// Manifest is the metadata of one network.
+type Manifest struct {
+	Name    string `json:"name"`
+	Dir     string `json:"dir"`
+	ChainID string `json:"chain_id"`
+	// NetworkType is the network_type of chain.json: mainnet, testnet or
+	// devnet, or "" when unknown.
+	NetworkType string   `json:"network_type,omitempty"`
+	Genesis     string   `json:"genesis"`
+	RPC         []string `json:"rpc"`
+	REST        []string `json:"rest"`
+	GRPC        []string `json:"grpc"`
+	EVM         []string `json:"evm"`
+	Peers       []string `json:"peers"`
+	Seeds       []string `json:"seeds"`
+
+	// GenesisURL and Version (the recommended wardend version) come from
+	// chain.json or manifest.json.
+	GenesisURL string `json:"genesis_url,omitempty"`
+	Version    string `json:"version,omitempty"`
+
+	// GenesisSHA256 pins the hex SHA-256 of the genesis file.
+	GenesisSHA256 string     `json:"genesis_sha256,omitempty"`
+	StakingDenom  string     `json:"staking_denom,omitempty"`
+	Fees          []FeeToken `json:"fees,omitempty"`
+}
+
+// FeeToken is a denom accepted for fees with its minimum gas price.
+type FeeToken struct {
+	Denom       string  `json:"denom"`
+	MinGasPrice float64 `json:"min_gas_price"`
+}
+
+// File is the schema of manifest.json. Every field is optional.
+type File struct {
+	ChainID        string `json:"chain_id,omitempty"`
+	WardendVersion string `json:"wardend_version,omitempty"`
+	Genesis        struct {
+		// Path is relative to the network directory.
+		Path   string `json:"path,omitempty"`
+		URL    string `json:"url,omitempty"`
+		SHA256 string `json:"sha256,omitempty"`
+	} `json:"genesis"`
+	StakingDenom string     `json:"staking_denom,omitempty"`
+	Fees         []FeeToken `json:"fees,omitempty"`
+	// Files overrides the names of the list files, by list: "rpc", "rest",
+	// "grpc", "evm", "peers" and "seeds".
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// listFiles are the default names of the list files.
+var listFiles = map[string]string{
+	"rpc":   "rpc-nodes.txt",
+	"rest":  "api-nodes.txt",
+	"grpc":  "grpc-nodes.txt",
+	"evm":   "evm-nodes.txt",
+	"peers": "peer-nodes.txt",
+	"seeds": "seed-nodes.txt",
+}
+
+// Load reads the manifest of the network directory dir.
+func Load(dir string) (*Manifest, error) {
+	m := &Manifest{
+		Name: filepath.Base(filepath.Clean(dir)),
+		Dir:  dir,
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "chain.json")); err == nil {
+		if err := m.loadChainJSON(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, "chain.json"), err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	files := listFiles
+	if data, err := os.ReadFile(filepath.Join(dir, ManifestFile)); err == nil {
+		var f File
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, ManifestFile), err)
+		}
+		m.apply(&f)
+		files = map[string]string{}
+		for list, name := range listFiles {
+			files[list] = name
+		}
+		for list, name := range f.Files {
+			if _, ok := listFiles[list]; !ok {
+				return nil, fmt.Errorf("%s: unknown list %q in files", filepath.Join(dir, ManifestFile), list)
+			}
+			files[list] = name
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	for list, dst := range map[string]*[]string{
+		"rpc":   &m.RPC,
+		"rest":  &m.REST,
+		"grpc":  &m.GRPC,
+		"evm":   &m.EVM,
+		"peers": &m.Peers,
+		"seeds": &m.Seeds,
+	} {
+		lines, err := ReadLines(filepath.Join(dir, files[list]))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		*dst = lines
+	}
+
+	ids, err := ReadLines(filepath.Join(dir, "chain-id.txt"))
+	switch {
+	case err == nil && len(ids) > 0:
+		m.ChainID = ids[0]
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	if m.Genesis == "" {
+		for _, name := range []string{"genesis.json", "init_genesis.json"} {
+			if p := filepath.Join(dir, name); fileExists(p) {
+				m.Genesis = p
+				break
+			}
+		}
+	}
+	if m.GenesisSHA256 == "" && m.Genesis != "" {
+		sum, err := readSHA256File(filepath.Join(filepath.Dir(m.Genesis), "genesis.sha256"), filepath.Base(m.Genesis))
+		if err != nil {
+			return nil, err
+		}
+		m.GenesisSHA256 = sum
+	}
+
+	for _, list := range []*[]string{&m.RPC, &m.REST, &m.GRPC, &m.EVM} {
+		for i, u := range *list {
+			(*list)[i] = strings.TrimSuffix(u, "/")
+		}
+	}
+	for _, list := range []*[]string{&m.RPC, &m.REST, &m.GRPC, &m.EVM, &m.Peers, &m.Seeds} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+
+	return m, nil
+}
+
+// Mainnet reports whether m is a mainnet: its chain.json network_type says
+// so or, without one, it is the mainnet directory at the repository root or
+// lives under mainnets/.
+func (m *Manifest) Mainnet() bool {
+	if m.NetworkType != "" {
+		return m.NetworkType == "mainnet"
+	}
+	abs, err := filepath.Abs(m.Dir)
+	if err != nil {
+		return false
+	}
+	parent := filepath.Base(filepath.Dir(abs))
+	return parent == "mainnets" || (filepath.Base(abs) == "mainnet" && parent != "testnets")
+}
+
+// apply copies the fields set in f to m.
+func (m *Manifest) apply(f *File) {
+	if f.ChainID != "" {
+		m.ChainID = f.ChainID
+	}
+	if f.WardendVersion != "" {
+		m.Version = f.WardendVersion
+	}
+	if f.Genesis.Path != "" {
+		m.Genesis = filepath.Join(m.Dir, filepath.FromSlash(f.Genesis.Path))
+	}
+	if f.Genesis.URL != "" {
+		m.GenesisURL = f.Genesis.URL
+	}
+	if f.Genesis.SHA256 != "" {
+		m.GenesisSHA256 = strings.ToLower(f.Genesis.SHA256)
+	}
+	if f.StakingDenom != "" {
+		m.StakingDenom = f.StakingDenom
+	}
+	if len(f.Fees) > 0 {
+		m.Fees = f.Fees
+	}
+}
+
+func (m *Manifest) loadChainJSON(data []byte) error {
+	type peer struct {
+		ID      string `json:"id"`
+		Address string `json:"address"`
+	}
+	type endpoint struct {
+		Address string `json:"address"`
+	}
+	var chain struct {
+		ChainID     string `json:"chain_id"`
+		NetworkType string `json:"network_type"`
+		Fees        struct {
+			FeeTokens []struct {
+				Denom            string  `json:"denom"`
+				FixedMinGasPrice float64 `json:"fixed_min_gas_price"`
+			} `json:"fee_tokens"`
+		} `json:"fees"`
+		Staking struct {
+			StakingTokens []struct {
+				Denom string `json:"denom"`
+			} `json:"staking_tokens"`
+		} `json:"staking"`
+		Codebase struct {
+			RecommendedVersion string `json:"recommended_version"`
+			Genesis            struct {
+				GenesisURL string `json:"genesis_url"`
+			} `json:"genesis"`
+		} `json:"codebase"`
+		Peers struct {
+			Seeds           []peer `json:"seeds"`
+			PersistentPeers []peer `json:"persistent_peers"`
+		} `json:"peers"`
+		APIs struct {
+			RPC  []endpoint `json:"rpc"`
+			REST []endpoint `json:"rest"`
+			GRPC []endpoint `json:"grpc"`
+			EVM  []endpoint `json:"evm-http-jsonrpc"`
+		} `json:"apis"`
+	}
+	if err := json.Unmarshal(data, &chain); err != nil {
+		return err
+	}
+
+	m.ChainID, m.NetworkType = chain.ChainID, chain.NetworkType
+	m.Version = chain.Codebase.RecommendedVersion
+	m.GenesisURL = chain.Codebase.Genesis.GenesisURL
+	for _, t := range chain.Fees.FeeTokens {
+		m.Fees = append(m.Fees, FeeToken{Denom: t.Denom, MinGasPrice: t.FixedMinGasPrice})
+	}
+	if len(chain.Staking.StakingTokens) > 0 {
+		m.StakingDenom = chain.Staking.StakingTokens[0].Denom
+	}
+	for _, p := range chain.Peers.Seeds {
+		m.Seeds = append(m.Seeds, p.ID+"@"+p.Address)
+	}
+	for _, p := range chain.Peers.PersistentPeers {
+		m.Peers = append(m.Peers, p.ID+"@"+p.Address)
+	}
+	for _, e := range chain.APIs.RPC {
+		m.RPC = append(m.RPC, e.Address)
+	}
+	for _, e := range chain.APIs.REST {
+		m.REST = append(m.REST, e.Address)
+	}
+	for _, e := range chain.APIs.GRPC {
+		m.GRPC = append(m.GRPC, e.Address)
+	}
+	for _, e := range chain.APIs.EVM {
+		m.EVM = append(m.EVM, e.Address)
+	}
+	return nil
+}
+
+// readSHA256File returns the hash of name in a sha256sum-style file, or ""
+// when the file does not exist or does not list name.
+func readSHA256File(path, name string) (string, error) {
+	lines, err := ReadLines(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, l := range lines {
+		sum, file, ok := strings.Cut(l, " ")
+		if ok && strings.TrimLeft(strings.TrimSpace(file), "*") == name {
+			return strings.ToLower(sum), nil
+		}
+	}
+	return "", nil
+}
+
+// ReadLines returns the non-empty lines of a text file, skipping comments
+// starting with "#".
+func ReadLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, s.Err()
+}
+
+// ParsePeer parses a "nodeID@host:port" peer address, checking that the
+// node ID is 20 hex-encoded bytes and the port is in range. The node ID is
+// returned lowercased.
+func ParsePeer(s string) (id, addr string, err error) {
+	id, addr, ok := strings.Cut(s, "@")
+	if !ok {
+		return "", "", errors.New("expected nodeID@host:port")
+	}
+	id = strings.ToLower(id)
+	if b, err := hex.DecodeString(id); err != nil || len(b) != 20 {
+		return "", "", fmt.Errorf("node id %q is not 40 hex characters", id)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", err
+	}
+	if host == "" {
+		return "", "", errors.New("empty host")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", "", fmt.Errorf("invalid port %q", port)
+	}
+	return id, addr, nil
+}
+
+// CheckPublic returns an error when the host of a "host:port" address is a
+// loopback, private, link-local or unspecified IP, which other nodes on the
+// internet cannot dial. Hostnames other than localhost are accepted.
+func CheckPublic(addr string) error {
+	host, _, _ := net.SplitHostPort(addr)
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		// A hostname; whether it resolves is for the liveness checks.
+		if host == "localhost" {
+			return fmt.Errorf("loopback host %s", host)
+		}
+		return nil
+	}
+	switch {
+	case ip.IsLoopback():
+		return fmt.Errorf("loopback address %s", ip)
+	case ip.IsPrivate():
+		return fmt.Errorf("private address %s", ip)
+	case ip.IsLinkLocalUnicast(), ip.IsUnspecified():
+		return fmt.Errorf("non-routable address %s", ip)
+	}
+	return nil
+}
+
+// EVMChainID returns the EVM chain ID embedded in an evmos-style chain-id
+// ("name_EVMID-version"), and false for chain-ids without one.
+func EVMChainID(chainID string) (uint64, bool) {
+	_, rest, ok := strings.Cut(chainID, "_")
+	if !ok {
+		return 0, false
+	}
+	id, _, ok := strings.Cut(rest, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(id, 10, 64)
+	return n, err == nil
+}
+
+func fileExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
@@ -0,0 +1,183 @@
+package networks
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, by name, to the directory dir under root and
+// returns its path.
+func writeFiles(t *testing.T, root, dir string, files map[string]string) string {
+	t.Helper()
+	dir = filepath.Join(root, dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	const (
+		peerA = "4b41a522de2124e719227622fa3cb65dd6745d20@10.1.8.90:26656"
+		peerB = "5c52b633ef3235f82a338733fb4dc76ee7856e31@10.1.8.91:26656"
+		sum   = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	)
+	dir := writeFiles(t, t.TempDir(), "chiado", map[string]string{
+		"chain.json": `{
+			"chain_id": "chiado_10010-1",
+			"network_type": "testnet",
+			"codebase": {"recommended_version": "v0.5.0", "genesis": {"genesis_url": "https://example.org/genesis.json"}},
+			"staking": {"staking_tokens": [{"denom": "award"}]},
+			"apis": {"rpc": [{"address": "https://rpc.example.org/"}]}
+		}`,
+		"manifest.json": `{
+			"wardend_version": "v0.5.2",
+			"genesis": {"path": "config/genesis.json", "sha256": "` + sum + `"},
+			"fees": [{"denom": "award", "min_gas_price": 25}],
+			"files": {"peers": "persistent-peers.txt"}
+		}`,
+		"persistent-peers.txt": "# maintained by hand\n" + peerA + "\n",
+		"peer-nodes.txt":       peerB + "\n",
+		"chain-id.txt":         "chiado_10010-2\n",
+	})
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field     string
+		got, want any
+	}{
+		// chain-id.txt overrides chain.json.
+		{"ChainID", m.ChainID, "chiado_10010-2"},
+		{"NetworkType", m.NetworkType, "testnet"},
+		// manifest.json overrides chain.json.
+		{"Version", m.Version, "v0.5.2"},
+		{"GenesisURL", m.GenesisURL, "https://example.org/genesis.json"},
+		{"Genesis", m.Genesis, filepath.Join(dir, "config", "genesis.json")},
+		{"GenesisSHA256", m.GenesisSHA256, sum},
+		{"StakingDenom", m.StakingDenom, "award"},
+		{"Fees", len(m.Fees) == 1 && m.Fees[0] == FeeToken{Denom: "award", MinGasPrice: 25}, true},
+		{"RPC", slices.Equal(m.RPC, []string{"https://rpc.example.org"}), true},
+		// files renames the peer list; peer-nodes.txt is not read.
+		{"Peers", slices.Equal(m.Peers, []string{peerA}), true},
+		{"Seeds", m.Seeds != nil && len(m.Seeds) == 0, true},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.field, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{"bad chain.json", map[string]string{"chain.json": "{"}, "chain.json"},
+		{"bad manifest", map[string]string{ManifestFile: `{"fees": "award"}`}, ManifestFile},
+		{"unknown list", map[string]string{ManifestFile: `{"files": {"validators": "v.txt"}}`}, `unknown list "validators"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeFiles(t, t.TempDir(), "chiado", tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGenesisSHA256File(t *testing.T) {
+	const sum = "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08"
+	dir := writeFiles(t, t.TempDir(), "chiado", map[string]string{
+		"genesis.json":   `{"chain_id": "chiado_10010-1"}`,
+		"genesis.sha256": sum + "  genesis.json\n",
+	})
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.GenesisSHA256 != strings.ToLower(sum) {
+		t.Errorf("GenesisSHA256 = %q, want %q", m.GenesisSHA256, strings.ToLower(sum))
+	}
+}
+
+func TestMainnet(t *testing.T) {
+	root := t.TempDir()
+	write := func(dir, chainJSON string) string {
+		files := map[string]string{}
+		if chainJSON != "" {
+			files["chain.json"] = chainJSON
+		}
+		return writeFiles(t, root, dir, files)
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		want bool
+	}{
+		{"under mainnets", write("mainnets/warden", ""), true},
+		{"under testnets", write("testnets/chiado", ""), false},
+		{"root mainnet", write("mainnet", ""), true},
+		{"testnet named mainnet", write("testnets/mainnet", ""), false},
+		{"chain.json mainnet", write("elsewhere/warden", `{"network_type":"mainnet"}`), true},
+		{"chain.json testnet under mainnets", write("mainnets/rehearsal", `{"network_type":"testnet"}`), false},
+		{"chain.json without network_type", write("mainnets/bare", `{"chain_id":"warden_8765-1"}`), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Load(tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Mainnet(); got != tt.want {
+				t.Errorf("Mainnet() of %s = %t, want %t", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePeer(t *testing.T) {
+	const id = "4b41a522de2124e719227622fa3cb65dd6745d20"
+
+	tests := []struct {
+		in       string
+		id, addr string
+		ok       bool
+	}{
+		{id + "@10.1.8.90:26656", id, "10.1.8.90:26656", true},
+		{"4B41A522DE2124E719227622FA3CB65DD6745D20@seed.example.org:26656", id, "seed.example.org:26656", true},
+		{id + "@[2001:db8::1]:26656", id, "[2001:db8::1]:26656", true},
+		{id + "@10.1.8.90", "", "", false},
+		{id + "@:26656", "", "", false},
+		{id + "@10.1.8.90:0", "", "", false},
+		{id + "@10.1.8.90:65536", "", "", false},
+		{"4b41a522@10.1.8.90:26656", "", "", false},
+		{"zz41a522de2124e719227622fa3cb65dd6745d20@10.1.8.90:26656", "", "", false},
+		{"10.1.8.90:26656", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		id, addr, err := ParsePeer(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParsePeer(%q) error = %v, want ok %t", tt.in, err, tt.ok)
+			continue
+		}
+		if id != tt.id || addr != tt.addr {
+			t.Errorf("ParsePeer(%q) = %q, %q, want %q, %q", tt.in, id, addr, tt.id, tt.addr)
+		}
+	}
+}
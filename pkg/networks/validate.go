@@ -0,0 +1,149 @@
+package networks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	// denomRe is the cosmos-sdk denom format.
+	denomRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9/:._-]{2,127}$`)
+	// versionRe matches wardend release tags.
+	versionRe = regexp.MustCompile(`^v\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+)
+
+// maxChainIDLength is the longest chain-id CometBFT accepts.
+const maxChainIDLength = 50
+
+// Validate checks the manifest for mistakes: a missing or malformed
+// chain-id, a genesis file that is missing, serves another chain or does not
+// match its pinned hash, an invalid version, fee or denom, and peers or
+// endpoints that do not parse. All problems are returned, joined.
+func (m *Manifest) Validate() error {
+	var errs []error
+	report := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch {
+	case m.ChainID == "":
+		report("no chain-id")
+	case len(m.ChainID) > maxChainIDLength:
+		report("chain-id %q is longer than %d characters", m.ChainID, maxChainIDLength)
+	case strings.ContainsAny(m.ChainID, " \t\n"):
+		report("chain-id %q contains whitespace", m.ChainID)
+	case strings.Contains(m.ChainID, "_"):
+		if _, ok := EVMChainID(m.ChainID); !ok {
+			report("chain-id %q is not of the form name_EVMID-version", m.ChainID)
+		}
+	}
+
+	if m.Genesis == "" && m.GenesisURL == "" {
+		report("no genesis file or URL")
+	}
+	if m.GenesisSHA256 != "" {
+		if b, err := hex.DecodeString(m.GenesisSHA256); err != nil || len(b) != sha256.Size {
+			report("genesis sha256 %q is not a hex SHA-256", m.GenesisSHA256)
+		}
+	}
+	if m.Genesis != "" {
+		if err := m.checkGenesis(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if m.Version != "" && !versionRe.MatchString(m.Version) {
+		report("wardend version %q is not a release tag like v0.3.0", m.Version)
+	}
+	if m.StakingDenom != "" && !denomRe.MatchString(m.StakingDenom) {
+		report("staking denom %q is invalid", m.StakingDenom)
+	}
+	seenDenom := map[string]bool{}
+	for _, f := range m.Fees {
+		switch {
+		case !denomRe.MatchString(f.Denom):
+			report("fee denom %q is invalid", f.Denom)
+		case seenDenom[f.Denom]:
+			report("fee denom %s is listed twice", f.Denom)
+		}
+		seenDenom[f.Denom] = true
+		if f.MinGasPrice < 0 {
+			report("fee denom %s has a negative minimum gas price", f.Denom)
+		}
+	}
+
+	for kind, peers := range map[string][]string{"peer": m.Peers, "seed": m.Seeds} {
+		seen := map[string]bool{}
+		for _, p := range peers {
+			id, _, err := ParsePeer(p)
+			if err != nil {
+				report("%s %q: %v", kind, p, err)
+				continue
+			}
+			if seen[id] {
+				report("%s %s is listed twice", kind, id)
+			}
+			seen[id] = true
+		}
+	}
+
+	for kind, endpoints := range map[string][]string{"rpc": m.RPC, "rest": m.REST, "evm": m.EVM} {
+		for _, e := range endpoints {
+			if u, err := url.Parse(e); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				report("%s endpoint %q is not an http(s) URL", kind, e)
+			}
+		}
+	}
+	for _, e := range m.GRPC {
+		// gRPC endpoints are published both as URLs and as host:port.
+		if u, err := url.Parse(e); err == nil && u.Host != "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(e); err != nil {
+			report("grpc endpoint %q is neither a URL nor host:port", e)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkGenesis checks the chain-id and, when pinned, the hash of the
+// genesis file.
+func (m *Manifest) checkGenesis() error {
+	f, err := os.Open(m.Genesis)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	var doc struct {
+		ChainID string `json:"chain_id"`
+	}
+	if err := json.NewDecoder(io.TeeReader(f, h)).Decode(&doc); err != nil {
+		return fmt.Errorf("decode %s: %w", m.Genesis, err)
+	}
+	if doc.ChainID != m.ChainID {
+		return fmt.Errorf("genesis %s is for chain-id %q, expected %q", m.Genesis, doc.ChainID, m.ChainID)
+	}
+	if m.GenesisSHA256 == "" {
+		return nil
+	}
+	// The decoder may stop before the end of the file.
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != m.GenesisSHA256 {
+		return fmt.Errorf("genesis %s has sha256 %s, pinned %s", m.Genesis, sum, m.GenesisSHA256)
+	}
+	return nil
+}
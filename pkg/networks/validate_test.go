@@ -0,0 +1,78 @@
+package networks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	genesis := filepath.Join(dir, "genesis.json")
+	if err := os.WriteFile(genesis, []byte(`{"chain_id": "warden_1337-1"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := func() *Manifest {
+		return &Manifest{
+			ChainID:      "warden_1337-1",
+			Genesis:      genesis,
+			RPC:          []string{"https://rpc.example.org"},
+			GRPC:         []string{"grpc.example.org:443"},
+			Peers:        []string{"4b41a522de2124e719227622fa3cb65dd6745d20@10.1.8.90:26656"},
+			Version:      "v0.5.2",
+			StakingDenom: "award",
+			Fees:         []FeeToken{{Denom: "award", MinGasPrice: 25}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(m *Manifest)
+		want   []string // substrings of the problems, none when empty
+	}{
+		{"valid", func(m *Manifest) {}, nil},
+		{"no chain-id", func(m *Manifest) { m.ChainID = "" }, []string{"no chain-id"}},
+		{"chain-id too long", func(m *Manifest) { m.ChainID = strings.Repeat("a", 51) }, []string{"longer than 50"}},
+		{"bad EVM chain-id", func(m *Manifest) { m.ChainID = "warden_x-1" }, []string{"name_EVMID-version"}},
+		{"genesis for another chain", func(m *Manifest) { m.ChainID = "alfama" }, []string{`is for chain-id "warden_1337-1"`}},
+		{"no genesis", func(m *Manifest) { m.Genesis = "" }, []string{"no genesis file or URL"}},
+		{"genesis URL only", func(m *Manifest) { m.Genesis, m.GenesisURL = "", "https://example.org/genesis.json" }, nil},
+		{"wrong genesis hash", func(m *Manifest) { m.GenesisSHA256 = strings.Repeat("0", 64) }, []string{"sha256"}},
+		{"malformed genesis hash", func(m *Manifest) { m.GenesisSHA256 = "abc" }, []string{"not a hex SHA-256"}},
+		{"bad version", func(m *Manifest) { m.Version = "latest" }, []string{"not a release tag"}},
+		{"bad denoms", func(m *Manifest) {
+			m.StakingDenom = "a"
+			m.Fees = append(m.Fees, FeeToken{Denom: "award"}, FeeToken{Denom: "uward", MinGasPrice: -1})
+		}, []string{"staking denom", "listed twice", "negative minimum gas price"}},
+		{"bad peers", func(m *Manifest) {
+			m.Peers = append(m.Peers, "4b41a522de2124e719227622fa3cb65dd6745d20@10.1.8.91:26656", "nope")
+		}, []string{"is listed twice", `"nope"`}},
+		{"bad endpoints", func(m *Manifest) {
+			m.RPC = []string{"rpc.example.org"}
+			m.GRPC = []string{"grpc.example.org"}
+		}, []string{"rpc endpoint", "grpc endpoint"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid()
+			tt.modify(m)
+			err := m.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want problems %q", tt.want)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("Validate() = %v, want a problem containing %q", err, w)
+				}
+			}
+		})
+	}
+}
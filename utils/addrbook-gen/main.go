@@ -0,0 +1,13 @@
+// Command addrbook-gen builds a CometBFT addrbook.json from the peer lists of
+// a network in this repo, and validates existing address books. It is also
+// available as "wardennet addrbook-gen".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/addrbook"
+	"github.com/warden-protocol/networks/internal/cli"
+)
+
+func main() {
+	cli.Main("addrbook-gen", addrbook.Command)
+}
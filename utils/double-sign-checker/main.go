@@ -0,0 +1,13 @@
+// Command double-sign-checker scans a chain's recent blocks for double-sign
+// evidence and slashing of a validator, and reports whether it is jailed or
+// tombstoned. It is also available as "wardennet double-sign-checker".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/doublesign"
+)
+
+func main() {
+	cli.Main("double-sign-checker", doublesign.Command)
+}
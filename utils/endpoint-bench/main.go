@@ -0,0 +1,13 @@
+// Command endpoint-bench samples the public endpoints published for each
+// network in this repo and ranks them by latency and block-height freshness.
+// It is also available as "wardennet endpoint-bench".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/endpointbench"
+)
+
+func main() {
+	cli.Main("endpoint-bench", endpointbench.Command)
+}
@@ -0,0 +1,13 @@
+// Command genesis-accounts turns an allocation spreadsheet (CSV or JSON)
+// into genesis accounts, balances and vesting schedules, optionally merged
+// into a genesis file. It is also available as "wardennet genesis-accounts".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/genesisaccounts"
+)
+
+func main() {
+	cli.Main("genesis-accounts", genesisaccounts.Command)
+}
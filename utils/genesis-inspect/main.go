@@ -0,0 +1,12 @@
+// Command genesis-inspect prints a structured summary of a genesis file. It
+// is also available as "wardennet genesis-inspect".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/genesisinspect"
+)
+
+func main() {
+	cli.Main("genesis-inspect", genesisinspect.Command)
+}
@@ -0,0 +1,13 @@
+// Command gentx-lint checks the gentx files of the networks in this repo.
+// With -inspect it prints the validator a single gentx creates. It is also
+// available as "wardennet gentx-lint".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/gentxlint"
+)
+
+func main() {
+	cli.Main("gentx-lint", gentxlint.Command)
+}
@@ -0,0 +1,13 @@
+// Command halt-detector watches the block height of the networks in this
+// repo across their RPC endpoints and alerts when it stops advancing or the
+// endpoints disagree. It is also available as "wardennet halt-detector".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/haltdetector"
+)
+
+func main() {
+	cli.Main("halt-detector", haltdetector.Command)
+}
@@ -0,0 +1,14 @@
+// Command netinfo-server serves the network metadata in this repo (chain-id,
+// genesis URL and hash, peers, seeds, endpoints, recommended version) as
+// versioned JSON under /v1/networks/{network}. It is also available as
+// "wardennet netinfo-server".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/netinfo"
+)
+
+func main() {
+	cli.Main("netinfo-server", netinfo.Command)
+}
@@ -0,0 +1,13 @@
+// Command peer-crawl discovers the live peers of a network in this repo over
+// the CometBFT PEX protocol and ranks them into a peers.txt candidate. It is
+// also available as "wardennet peer-crawl".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/peercrawl"
+)
+
+func main() {
+	cli.Main("peer-crawl", peercrawl.Command)
+}
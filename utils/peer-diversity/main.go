@@ -0,0 +1,13 @@
+// Command peer-diversity reports how the peers published in this repo are
+// spread over countries, regions and hosting providers. It is also
+// available as "wardennet peer-diversity".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/peerdiversity"
+)
+
+func main() {
+	cli.Main("peer-diversity", peerdiversity.Command)
+}
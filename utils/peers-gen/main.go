@@ -0,0 +1,13 @@
+// Command peers-gen derives a network's persistent_peers line, and
+// optionally its peer-nodes.txt, from the node addresses in its gentx memos.
+// It is also available as "wardennet peers-gen".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/peersgen"
+)
+
+func main() {
+	cli.Main("peers-gen", peersgen.Command)
+}
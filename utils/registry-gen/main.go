@@ -0,0 +1,13 @@
+// Command registry-gen produces and checks cosmos/chain-registry compatible
+// chain.json and assetlist.json files for the networks in this repo. It is
+// also available as "wardennet registry-gen".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/registry"
+)
+
+func main() {
+	cli.Main("registry-gen", registry.Command)
+}
@@ -0,0 +1,13 @@
+// Command rpc-healthcheck probes the public endpoints published for each
+// network in this repo and exits non-zero when any of them is down or on the
+// wrong chain. It is also available as "wardennet rpc-healthcheck".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/healthcheck"
+)
+
+func main() {
+	cli.Main("rpc-healthcheck", healthcheck.Command)
+}
@@ -0,0 +1,13 @@
+// Command seed-monitor dials the seed nodes published in this repo, keeps a
+// JSON history of the outcomes and reports their 7- and 30-day uptime. It is
+// also available as "wardennet seed-monitor".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/seedmonitor"
+)
+
+func main() {
+	cli.Main("seed-monitor", seedmonitor.Command)
+}
@@ -0,0 +1,13 @@
+// Command snapshot-tool generates signed manifests for node snapshot
+// archives and verifies downloaded archives against them. It is also
+// available as "wardennet snapshot-tool".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/snapshot"
+)
+
+func main() {
+	cli.Main("snapshot-tool", snapshot.Command)
+}
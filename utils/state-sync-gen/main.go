@@ -0,0 +1,13 @@
+// Command state-sync-gen emits a ready-to-paste [statesync] config.toml block
+// for one of the networks in this repo. It is also available as
+// "wardennet state-sync-gen".
+package main
+
+import (
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/statesync"
+)
+
+func main() {
+	cli.Main("state-sync-gen", statesync.Command)
+}
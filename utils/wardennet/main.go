@@ -0,0 +1,164 @@
+// Command wardennet bundles the tools in this repo under a single binary.
+// Every subcommand is named after the standalone tool under utils/ it
+// replaces.
+//
+// Network names given to -network (or as arguments) are resolved against the
+// repository checkout given by -root or $WARDENNET_ROOT, so the same binary
+// works for every network without passing directory paths around. Every
+// subcommand supports -format json for machine-readable output.
+//
+// Usage:
+//
+//	wardennet [-root dir] <command> [flags] [args]
+//	wardennet networks
+//	wardennet networks -check
+//	wardennet genesis-inspect -network alfama
+//	wardennet gentx-lint alfama
+//	source <(wardennet completion bash)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/warden-protocol/networks/internal/addrbook"
+	"github.com/warden-protocol/networks/internal/cli"
+	"github.com/warden-protocol/networks/internal/doublesign"
+	"github.com/warden-protocol/networks/internal/endpointbench"
+	"github.com/warden-protocol/networks/internal/genesisaccounts"
+	"github.com/warden-protocol/networks/internal/genesisinspect"
+	"github.com/warden-protocol/networks/internal/gentxlint"
+	"github.com/warden-protocol/networks/internal/haltdetector"
+	"github.com/warden-protocol/networks/internal/healthcheck"
+	"github.com/warden-protocol/networks/internal/netinfo"
+	"github.com/warden-protocol/networks/internal/network"
+	"github.com/warden-protocol/networks/internal/peercrawl"
+	"github.com/warden-protocol/networks/internal/peerdiversity"
+	"github.com/warden-protocol/networks/internal/peersgen"
+	"github.com/warden-protocol/networks/internal/registry"
+	"github.com/warden-protocol/networks/internal/seedmonitor"
+	"github.com/warden-protocol/networks/internal/snapshot"
+	"github.com/warden-protocol/networks/internal/statesync"
+)
+
+func commands() []*cli.Command {
+	return []*cli.Command{
+		networksCommand,
+		genesisinspect.Command,
+		gentxlint.Command,
+		statesync.Command,
+		registry.Command,
+		healthcheck.Command,
+		endpointbench.Command,
+		peersgen.Command,
+		addrbook.Command,
+		peercrawl.Command,
+		snapshot.Command,
+		netinfo.Command,
+		seedmonitor.Command,
+		peerdiversity.Command,
+		genesisaccounts.Command,
+		haltdetector.Command,
+		doublesign.Command,
+		cli.CompletionCommand("wardennet", commands),
+	}
+}
+
+var networksCommand = &cli.Command{
+	Name:  "networks",
+	Short: "List the networks in the repository with their chain-id and endpoint counts.",
+	Setup: func(fs *flag.FlagSet) cli.RunFunc {
+		format := cli.FormatFlag(fs, "text", "json")
+		check := fs.Bool("check", false, "validate every network's manifest and exit non-zero on problems")
+
+		return func(ctx context.Context, env *cli.Env, args []string) error {
+			if len(args) != 0 {
+				return cli.ErrUsage
+			}
+			dirs, err := network.List(env.Root)
+			if err != nil {
+				return err
+			}
+			networks := make([]*network.Network, 0, len(dirs))
+			for _, d := range dirs {
+				n, err := network.Load(d)
+				if err != nil {
+					return err
+				}
+				networks = append(networks, n)
+			}
+
+			if *check {
+				var invalid int
+				for _, n := range networks {
+					if err := n.Validate(); err != nil {
+						invalid++
+						for _, line := range strings.Split(err.Error(), "\n") {
+							fmt.Fprintf(env.Stderr, "%s: %s\n", n.Name, line)
+						}
+					}
+				}
+				if invalid > 0 {
+					return fmt.Errorf("%d of %d network(s) have invalid manifests", invalid, len(networks))
+				}
+			}
+
+			if format.JSON() {
+				return cli.WriteJSON(env.Stdout, networks)
+			}
+			tw := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tCHAIN ID\tRPC\tREST\tGRPC\tPEERS\tSEEDS\tDIR")
+			for _, n := range networks {
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+					n.Name, n.ChainID, len(n.RPC), len(n.REST), len(n.GRPC), len(n.Peers), len(n.Seeds), n.Dir)
+			}
+			return tw.Flush()
+		}
+	},
+}
+
+func main() {
+	env := cli.DefaultEnv()
+
+	fs := flag.NewFlagSet("wardennet", flag.ExitOnError)
+	fs.StringVar(&env.Root, "root", env.Root, "networks repository checkout to resolve network names against ($"+cli.RootEnv+" sets the default)")
+	fs.Usage = func() { usage(fs) }
+	_ = fs.Parse(os.Args[1:])
+
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		usage(fs)
+		os.Exit(2)
+	}
+
+	for _, c := range commands() {
+		if c.Name != fs.Arg(0) {
+			continue
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		code := cli.Exec(ctx, "wardennet "+c.Name, c, env, fs.Args()[1:])
+		stop()
+		os.Exit(code)
+	}
+
+	fmt.Fprintf(os.Stderr, "wardennet: unknown command %q\n", fs.Arg(0))
+	usage(fs)
+	os.Exit(2)
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "usage: wardennet [-root dir] <command> [flags] [args]\n\nCommands:\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands() {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.Name, c.Short)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nGlobal flags:\n")
+	fs.PrintDefaults()
+}